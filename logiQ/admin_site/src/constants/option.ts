@@ -2,6 +2,11 @@ export const CATEGORY_OPTIONS = [
     {label: 'Truth Table', value: 'truthTable'},
     {label: 'Equivalence', value: 'equivalence'},
     {label: 'Inference', value: 'inference'},
+    {label: 'Normal Form', value: 'normalForm'},
+    {label: 'Classification', value: 'classification'},
+    {label: 'Proof', value: 'proof'},
+    {label: 'Resolution', value: 'resolution'},
+    {label: 'Tableau', value: 'tableau'},
 ];
 
 export const DIFFICULTY_OPTIONS = [
@@ -16,3 +21,11 @@ export const TYPE_OPTIONS = [
     {label: 'True / False', value: 'trueFalse'},
 ];
 
+
+export const NOTATION_OPTIONS = [
+    {label: 'Unicode (¬ ∧ ∨ →)', value: 'unicode'},
+    {label: 'ASCII (~ & | ->)', value: 'ascii'},
+    {label: 'LaTeX', value: 'latex'},
+    {label: 'Polish Prefix', value: 'prefix'},
+    {label: 'Spoken English', value: 'spoken'},
+];
@@ -1,10 +1,10 @@
 import {useCallback, useEffect, useMemo, useState} from 'react';
-import {Button, Card, Col, Form, InputNumber, Row, Select, Space, Spin, Typography, message} from 'antd';
+import {Button, Card, Checkbox, Col, Form, InputNumber, Row, Select, Space, Spin, Typography, message} from 'antd';
 
 import type {Question} from '../Table/types.ts';
 import GenerationPreview, {type GenerationSummary} from './components/GenerationPreview.tsx';
 import {createJsonDownloadHandle, type JsonDownloadHandle} from '../../utils/download.ts';
-import {CATEGORY_OPTIONS, DIFFICULTY_OPTIONS, TYPE_OPTIONS} from '../../constants/option.ts';
+import {CATEGORY_OPTIONS, DIFFICULTY_OPTIONS, NOTATION_OPTIONS, TYPE_OPTIONS} from '../../constants/option.ts';
 import {generateQuestions, type GenerateParam} from './services.ts';
 import './generation.css';
 
@@ -59,6 +59,16 @@ const GenerationPage = () => {
                 category: values.category,
                 difficulty: values.difficulty,
                 type: values.type,
+                notation: values.notation,
+                minimal_parens: values.minimal_parens,
+            }, (job) => {
+                messageApi.open({
+                    key: messageKey,
+                    type: 'loading',
+                    content: `Generating questions... ${job.generated}/${job.total}` +
+                        (job.duplicates > 0 ? ` (${job.duplicates} duplicates skipped)` : ''),
+                    duration: 0,
+                });
             });
             setGeneratedQuestions(questions);
             setGeneratedAt(new Date());
@@ -166,6 +176,14 @@ const GenerationPage = () => {
                                         <Select allowClear placeholder="Select question type" options={TYPE_OPTIONS}/>
                                     </Form.Item>
 
+                                    <Form.Item label="Notation" name="notation">
+                                        <Select allowClear placeholder="Unicode" options={NOTATION_OPTIONS}/>
+                                    </Form.Item>
+
+                                    <Form.Item name="minimal_parens" valuePropName="checked">
+                                        <Checkbox>Only keep parentheses required by precedence</Checkbox>
+                                    </Form.Item>
+
                                     <Form.Item>
                                         <Button
                                             type="primary"
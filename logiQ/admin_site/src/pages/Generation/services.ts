@@ -6,29 +6,70 @@ export interface GenerateParam {
     category?: string;
     difficulty?: string;
     type?: string;
+    notation?: string;
+    minimal_parens?: boolean;
 }
 
-export const generateQuestions = async ({number, category, difficulty, type}: GenerateParam): Promise<Question[]> => {
+export type GenerationJobStatus = 'queued' | 'running' | 'completed' | 'failed' | 'cancelled';
+
+export interface GenerationJob {
+    id: string;
+    status: GenerationJobStatus;
+    total: number;
+    generated: number;
+    inserted: number;
+    failed_attempts: number;
+    duplicates: number;
+    error?: string;
+    questions?: Question[];
+}
+
+const JOB_POLL_INTERVAL = 1000;
+
+const sleep = (ms: number) => new Promise((resolve) => setTimeout(resolve, ms));
+
+export const getGenerationJob = async (jobId: string): Promise<GenerationJob> => {
+    const res = await axiosInstance.get(`/question/jobs/${jobId}`);
+    return res.data as GenerationJob;
+};
+
+// 取消排队中或执行中的生成任务，已入库的题目保留
+export const cancelGenerationJob = async (jobId: string): Promise<GenerationJob> => {
+    const res = await axiosInstance.post(`/question/jobs/${jobId}/cancel`);
+    return res.data as GenerationJob;
+};
+
+// 提交生成任务后轮询任务状态，直到任务结束
+export const generateQuestions = async (
+    {number, category, difficulty, type, notation, minimal_parens}: GenerateParam,
+    onProgress?: (job: GenerationJob) => void,
+): Promise<Question[]> => {
     const res = await axiosInstance.post('/question/generate', {
         number,
         category,
         difficulty,
         type,
+        notation,
+        minimal_parens,
     });
 
-    const data = res?.data;
-
-    if (Array.isArray(data)) {
-        return data as Question[];
+    const jobId: string = res?.data?.job_id;
+    if (!jobId) {
+        return [];
     }
 
-    if (Array.isArray(data?.questions)) {
-        return data.questions as Question[];
+    for (;;) {
+        const job = await getGenerationJob(jobId);
+        onProgress?.(job);
+        if (job.status === 'completed') {
+            return job.questions ?? [];
+        }
+        if (job.status === 'failed') {
+            throw new Error(job.error || 'Failed to generate questions');
+        }
+        if (job.status === 'cancelled') {
+            throw new Error('Generation job was cancelled');
+        }
+        await sleep(JOB_POLL_INTERVAL);
     }
-
-    if (Array.isArray(data?.list)) {
-        return data.list as Question[];
-    }
-
-    return [];
 };
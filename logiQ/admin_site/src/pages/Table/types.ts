@@ -7,6 +7,7 @@ export interface Question {
     difficulty: string;
     type: string;
     is_active: boolean;
+    notation?: string;
 
     // Statistics fields
     total_answers: number;
@@ -0,0 +1,211 @@
+// Command auditbank checks every active question in MongoDB: it recomputes
+// the answer key from the stored formulas with the validator and flags wrong
+// answer keys, duplicate options and degenerate questions.
+//
+// Usage:
+//
+//	go run ./cmd/auditbank [-deactivate] [-generate N -seed S]
+//	go run ./cmd/auditbank -fingerprint
+//
+// The connection is configured like the server, through MONGO_URI and
+// MONGO_DB_NAME (or a .env file). The exit status is 1 when any question was
+// flagged, so the command can gate CI. With -generate every answer key must
+// also be recomputed, otherwise an intent the audit does not cover would pass
+// the smoke test unchecked.
+//
+// -fingerprint backfills the fingerprint of questions stored without one.
+// Questions with structured formulas are fingerprinted from them; questions
+// with only a blueprint are regenerated and fingerprinted if the result
+// matches the stored question. Any other question, such as one entered by
+// hand, is fingerprinted by its text and options. Questions whose
+// fingerprint is already taken by an active question are reported, not
+// updated.
+package main
+
+import (
+	"backend/database"
+	"backend/generation/audit"
+	"backend/generation/config"
+	"backend/generation/fingerprint"
+	gengerationService "backend/generation/service"
+	"backend/generation/validator"
+	"backend/models"
+	"backend/services"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func main() {
+	deactivate := flag.Bool("deactivate", false, "deactivate questions with a wrong answer key or that are degenerate")
+	generate := flag.Int("generate", 0, "insert this many freshly generated questions before auditing, e.g. to smoke-test an empty database")
+	seed := flag.Uint64("seed", 1, "master seed for -generate")
+	backfill := flag.Bool("fingerprint", false, "set the fingerprint of questions stored without one, then exit")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, fallback to environment variables")
+	}
+	database.ConnectMongoDB()
+	questionService := services.NewQuestionService()
+
+	if *backfill {
+		if err := backfillFingerprints(questionService); err != nil {
+			log.Fatal("Failed to backfill fingerprints: ", err)
+		}
+		return
+	}
+
+	if *generate > 0 {
+		if err := insertGenerated(questionService, *generate, *seed); err != nil {
+			log.Fatal("Failed to generate questions: ", err)
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load generation config: ", err)
+	}
+	v, err := validator.New(cfg.Validator)
+	if err != nil {
+		log.Fatal("Failed to create validator: ", err)
+	}
+	auditor := audit.New(v)
+
+	var (
+		total, verified int
+		flagged         []primitive.ObjectID
+		toDeactivate    []primitive.ObjectID
+	)
+	err = questionService.ForEachActiveQuestion(context.Background(), func(q models.Question) error {
+		total++
+		res := auditor.Check(q)
+		if res.Verified {
+			verified++
+		}
+		if res.OK() {
+			return nil
+		}
+		flagged = append(flagged, q.ID)
+		intent := "manual"
+		if q.Blueprint != nil {
+			intent = q.Blueprint.Intent
+		}
+		unanswerable := false
+		for _, f := range res.Findings {
+			fmt.Printf("%s %s/%s %s: %s\n", q.ID.Hex(), q.Category, intent, f.Kind, f.Detail)
+			if f.Kind == audit.WrongAnswer || f.Kind == audit.Degenerate {
+				unanswerable = true
+			}
+		}
+		if unanswerable {
+			toDeactivate = append(toDeactivate, q.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal("Failed to read questions: ", err)
+	}
+
+	fmt.Printf("audited %d active questions: %d answer keys recomputed, %d flagged\n", total, verified, len(flagged))
+	if *deactivate && len(toDeactivate) > 0 {
+		n, err := questionService.SoftDeleteQuestionsByIDs(toDeactivate)
+		if err != nil {
+			log.Fatal("Failed to deactivate questions: ", err)
+		}
+		fmt.Printf("deactivated %d questions\n", n)
+	}
+	unverified := *generate > 0 && verified < total
+	if unverified {
+		fmt.Printf("%d answer keys could not be recomputed\n", total-verified)
+	}
+	if len(flagged) > 0 || unverified {
+		os.Exit(1)
+	}
+}
+
+// insertGenerated 生成并插入 n 道题目
+func insertGenerated(questionService *services.QuestionService, n int, seed uint64) error {
+	questions, err := gengerationService.NewService().GenerateBatch(context.Background(), gengerationService.BatchRequest{
+		Num:        n,
+		MasterSeed: seed,
+	})
+	if err != nil {
+		return err
+	}
+	inserted, err := questionService.InsertQuestions(questions)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("inserted %d generated questions (%d duplicates skipped)\n", len(inserted), len(questions)-len(inserted))
+	return nil
+}
+
+// backfillFingerprints 补写没有指纹的题目的指纹
+// 有结构化公式的题目直接用公式计算；只有 blueprint 的题目重新生成，结果与存储的题目相同时用生成的指纹；
+// 其余题目（手工录入、重新生成的结果不同或无法重新生成）按文本和选项计算。与其他有效题目指纹相同时只报告
+func backfillFingerprints(questionService *services.QuestionService) error {
+	ctx := context.Background()
+	genService := gengerationService.NewService()
+	var structured, regenerated, text, duplicates int
+	err := questionService.ForEachUnfingerprintedQuestion(ctx, func(q models.Question) error {
+		var fp string
+		switch {
+		case q.Blueprint != nil && q.Formulas != nil:
+			var err error
+			fp, err = fingerprint.Of(fingerprint.Question{
+				Intent:   q.Blueprint.Intent,
+				Type:     q.Type,
+				Formulas: q.Formulas,
+				Options:  q.Options,
+			})
+			if err != nil {
+				fmt.Printf("%s: stored formulas: %v\n", q.ID.Hex(), err)
+				fp = ""
+			}
+		case q.Blueprint != nil:
+			question, err := genService.Regenerate(*q.Blueprint)
+			if err != nil {
+				fmt.Printf("%s: cannot regenerate: %v\n", q.ID.Hex(), err)
+			} else if question.QuestionText != q.QuestionText || !slices.Equal(question.Options, q.Options) {
+				fmt.Printf("%s: regenerated question differs from the stored one\n", q.ID.Hex())
+			} else {
+				fp = question.Fingerprint
+			}
+		}
+		counter := &text
+		switch {
+		case fp == "":
+			fp = fingerprint.OfText(q.QuestionText, q.Options)
+		case q.Formulas != nil:
+			counter = &structured
+		default:
+			counter = &regenerated
+		}
+
+		err := questionService.SetFingerprint(ctx, q.ID, fp)
+		if errors.Is(err, services.ErrQuestionDuplicate) {
+			duplicates++
+			fmt.Printf("%s: duplicate of another active question in the bank (fingerprint %s)\n", q.ID.Hex(), fp)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		*counter++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("fingerprinted %d questions from stored formulas, %d by regeneration and %d by text; %d duplicates\n",
+		structured, regenerated, text, duplicates)
+	return nil
+}
@@ -2,10 +2,12 @@ package database
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -51,9 +53,36 @@ func ConnectMongoDB() {
 	// 选择数据库（如果数据库不存在，MongoDB会在第一次写入数据时自动创建）
 	DB = client.Database(dbName)
 
+	if err := ensureIndexes(ctx); err != nil {
+		log.Fatal("Failed to create MongoDB indexes:", err)
+	}
+
 	log.Println("Successfully connected to MongoDB!")
 }
 
+// ensureIndexes 创建应用依赖的索引，索引已存在时 MongoDB 不做任何事
+func ensureIndexes(ctx context.Context) error {
+	questions := DB.Collection(QuestionsCollection).Indexes()
+	// 旧版本的指纹索引对已停用的题目也生效，停用的题目会挡住同一道题重新入库，先删掉
+	// 26（集合不存在）、27（索引不存在）说明没有旧索引
+	if _, err := questions.DropOne(ctx, "fingerprint_unique"); err != nil {
+		var cmdErr mongo.CommandError
+		if !errors.As(err, &cmdErr) || (cmdErr.Code != 26 && cmdErr.Code != 27) {
+			return err
+		}
+	}
+	// 有效题目的指纹唯一：同一道题（只差变量名、交换律或选项顺序）只能有一道有效题目
+	// 部分索引，已停用的题目和没有指纹的题目不受限制
+	_, err := questions.CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "fingerprint", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("fingerprint_active_unique").SetPartialFilterExpression(bson.D{
+			{Key: "fingerprint", Value: bson.D{{Key: "$exists", Value: true}}},
+			{Key: "is_active", Value: true},
+		}),
+	})
+	return err
+}
+
 // GetCollection 获取指定名称的集合
 // 这是一个辅助函数，让我们更容易获取MongoDB集合
 // 集合类似于关系型数据库中的表
@@ -68,6 +97,7 @@ const (
 	QuestionsCollection     = "questions"             // 题目集合 - 存储测验题目
 	QuestionStatsCollection = "question_stats"        // 题目统计集合 - 存储题目使用和正确率统计
 	QuizzesCollection       = "quizzes"               // 测验集合 - 存储测验记录和结果
+	QuizSessionsCollection  = "quiz_sessions"         // 测验会话集合 - 存储进行中/未完成的测验
 	PendingUsersCollection  = "pending_registrations" // 待注册用户集合 - 存储未完成注册的用户信息
 	UserStatsCollection     = "user_stats"            // 用户统计集合 - 存储用户统计数据
 )
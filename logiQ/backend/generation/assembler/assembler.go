@@ -2,6 +2,7 @@ package assembler
 
 import (
 	"backend/generation/builder/choice"
+	"backend/generation/builder/explain"
 	"backend/generation/builder/prompt"
 	"backend/generation/sampler"
 	"backend/models"
@@ -13,8 +14,8 @@ func NewAssembler() Assembler {
 	return Assembler{}
 }
 
-// Assemble 把 prompt 和 choice 组装成最终的 question
-func (a Assembler) Assemble(plan sampler.Plan, prompt prompt.Prompt, choice choice.Choice) models.Question {
+// Assemble 把 prompt、choice 和解析组装成最终的 question
+func (a Assembler) Assemble(plan sampler.Plan, prompt prompt.Prompt, choice choice.Choice, explanation explain.Explanation) models.Question {
 
 	return models.Question{
 		Difficulty:         plan.Difficulty,
@@ -24,5 +25,7 @@ func (a Assembler) Assemble(plan sampler.Plan, prompt prompt.Prompt, choice choi
 		Options:            choice.Options,
 		CorrectAnswerIndex: choice.CorrectIndexes,
 		IsActive:           true,
+		Explanation:        explanation.Text,
+		OptionExplanations: explanation.Options,
 	}
 }
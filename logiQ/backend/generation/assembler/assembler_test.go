@@ -2,6 +2,7 @@ package assembler
 
 import (
 	"backend/generation/builder/choice"
+	"backend/generation/builder/explain"
 	"backend/generation/builder/prompt"
 	"backend/generation/sampler"
 	"backend/models"
@@ -27,7 +28,11 @@ func TestAssembler_Assemble(t *testing.T) {
 		}
 	)
 	assembler := NewAssembler()
-	question := assembler.Assemble(plan, prompt, chioice)
+	explanation := explain.Explanation{
+		Text:    "p=T, q=T ⇒ T",
+		Options: []string{"true", "false", "false"},
+	}
+	question := assembler.Assemble(plan, prompt, chioice, explanation)
 	// // 把question转换成json打印出来
 	jsonBytes, err := json.Marshal(question)
 	if err != nil {
@@ -0,0 +1,665 @@
+// Package audit re-checks stored questions against the validator. The answer
+// key is recomputed from the structured formulas saved with the question
+// (models.Question.Formulas) and the intent in its blueprint, so a question
+// can be verified regardless of the notation its text was rendered in.
+// Besides wrong answer keys the audit flags duplicate options and degenerate
+// questions, e.g. a single choice question with two correct options.
+package audit
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+	"backend/generation/normalform"
+	"backend/generation/parser"
+	"backend/generation/proof"
+	"backend/generation/resolution"
+	"backend/generation/tableau"
+	"backend/generation/validator"
+	"backend/models"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kind classifies a finding.
+type Kind string
+
+const (
+	// WrongAnswer means CorrectAnswerIndex disagrees with the recomputed key.
+	WrongAnswer Kind = "wrong_answer"
+	// DuplicateOptions means two options have the same text or formula.
+	DuplicateOptions Kind = "duplicate_options"
+	// Degenerate means the question cannot be answered as posed.
+	Degenerate Kind = "degenerate"
+	// InvalidFormulas means the stored formula payload cannot be decoded.
+	InvalidFormulas Kind = "invalid_formulas"
+)
+
+// Finding is one problem found in a question.
+type Finding struct {
+	Kind   Kind
+	Detail string
+}
+
+// Result is the outcome of auditing one question. Verified is false when the
+// answer key could not be recomputed: the question has no blueprint or
+// formulas (e.g. it was entered by hand), or its intent is not covered.
+type Result struct {
+	Verified bool
+	Findings []Finding
+}
+
+// OK reports whether the question has no findings.
+func (r Result) OK() bool { return len(r.Findings) == 0 }
+
+type Auditor struct {
+	validator validator.Validator
+}
+
+func New(v validator.Validator) Auditor {
+	return Auditor{validator: v}
+}
+
+// Check audits a single question.
+func (a Auditor) Check(q models.Question) Result {
+	var res Result
+	res.Findings = append(res.Findings, degenerate(q)...)
+
+	formulas, err := decode(q.Formulas)
+	if err != nil {
+		res.Findings = append(res.Findings, Finding{Kind: InvalidFormulas, Detail: err.Error()})
+		return res
+	}
+	res.Findings = append(res.Findings, duplicates(q, formulas)...)
+
+	if q.Blueprint == nil || q.Formulas == nil {
+		return res
+	}
+	want, ok := a.recompute(q, formulas)
+	if !ok {
+		return res
+	}
+	res.Verified = true
+	got := append([]int(nil), q.CorrectAnswerIndex...)
+	sort.Ints(got)
+	if !equalInts(got, want) {
+		res.Findings = append(res.Findings, Finding{
+			Kind:   WrongAnswer,
+			Detail: fmt.Sprintf("%s: stored correct options %v, recomputed %v", q.Blueprint.Intent, got, want),
+		})
+	}
+	return res
+}
+
+// formulas 解码后的 models.QuestionFormulas
+type formulas struct {
+	target    *core.Node
+	premises  []*core.Node
+	candidate *core.Node
+	options   []*core.Node
+	vars      []string
+	proof     []proof.Line
+	line      int
+	clauses   []normalform.Clause
+}
+
+func decode(f *models.QuestionFormulas) (formulas, error) {
+	if f == nil {
+		return formulas{}, nil
+	}
+	var (
+		out formulas
+		err error
+	)
+	if out.target, err = core.DecodeFormula(f.Target); err != nil {
+		return formulas{}, fmt.Errorf("target: %w", err)
+	}
+	if out.candidate, err = core.DecodeFormula(f.Candidate); err != nil {
+		return formulas{}, fmt.Errorf("candidate: %w", err)
+	}
+	out.premises = make([]*core.Node, len(f.Premises))
+	for i, p := range f.Premises {
+		if out.premises[i], err = core.DecodeFormula(p); err != nil {
+			return formulas{}, fmt.Errorf("premise %d: %w", i+1, err)
+		}
+	}
+	out.options = make([]*core.Node, len(f.Options))
+	for i, o := range f.Options {
+		if out.options[i], err = core.DecodeFormula(o); err != nil {
+			return formulas{}, fmt.Errorf("option %d: %w", i+1, err)
+		}
+	}
+	out.vars = f.Vars
+	out.proof = make([]proof.Line, len(f.Proof))
+	for i, l := range f.Proof {
+		if out.proof[i].Formula, err = core.DecodeFormula(l.Formula); err != nil {
+			return formulas{}, fmt.Errorf("proof line %d: %w", i+1, err)
+		}
+		out.proof[i].Number = i + 1
+		out.proof[i].Rule = proof.Rule(l.Rule)
+		out.proof[i].Cites = l.Cites
+	}
+	out.line = f.Line
+	for i, c := range f.Clauses {
+		node, err := core.DecodeFormula(c)
+		if err != nil {
+			return formulas{}, fmt.Errorf("clause %d: %w", i+1, err)
+		}
+		clause, ok := toClause(node)
+		if !ok {
+			return formulas{}, fmt.Errorf("clause %d is not a disjunction of literals", i+1)
+		}
+		out.clauses = append(out.clauses, clause)
+	}
+	return out, nil
+}
+
+// recompute 按意图重新计算正确选项的下标；ok 为 false 表示该意图无法只凭存储的公式校验
+func (a Auditor) recompute(q models.Question, f formulas) ([]int, bool) {
+	v := a.validator
+	intent := q.Blueprint.Intent
+
+	// 判断题：算出陈述是否成立，True 在下标 0
+	var claim, ok bool
+	switch intent {
+	case "TT_EVAL_AT_ASSIGNMENT":
+		var assign map[string]bool
+		if assign, ok = findAssignment(q.QuestionText); ok && f.target != nil {
+			claim = v.Eval(f.target, assign)
+		}
+		ok = ok && f.target != nil
+	case "EQ_PAIR_TF", "NF_PAIR_TF":
+		// 范式判断题的候选式来自对应范式的池子，形状由构造保证，只需判断是否等价
+		if ok = f.target != nil && f.candidate != nil; ok {
+			claim = v.Equivalent(f.target, f.candidate, f.vars)
+		}
+	case "INF_VALIDITY_TF":
+		if ok = f.target != nil && len(f.premises) > 0; ok {
+			claim = v.Derivable(f.premises, f.target, f.vars)
+		}
+	case "TAB_CLOSES_TF":
+		// 表格全部关闭当且仅当前提与结论的否定不可满足，前提本身不可满足时也关闭
+		if ok = f.target != nil && len(f.premises) > 0; ok {
+			_, open := v.DerivationCounterexample(f.premises, f.target, f.vars)
+			claim = !open
+		}
+	case "PRF_STEP_TF":
+		// 最后一行是待判断的步骤
+		if ok = f.line > 0 && f.line == len(f.proof); ok {
+			claim = validStep(f.proof, f.line-1, f.premises)
+		}
+	case "RES_RESOLVENT_TF":
+		var c normalform.Clause
+		if c, ok = toClause(f.candidate); ok && len(f.clauses) == 2 {
+			claim = resolvents(f.clauses)[clauseKey(c)]
+		}
+		ok = ok && len(f.clauses) == 2
+	case "CLS_CLASS_TF":
+		var class string
+		if class, ok = findClass(q.QuestionText); ok && f.target != nil {
+			claim = a.class(f.target, f.vars) == class
+		}
+		ok = ok && f.target != nil
+	default:
+		return a.recomputeOptions(q, f)
+	}
+	if !ok {
+		return nil, false
+	}
+	if claim {
+		return []int{0}, true
+	}
+	return []int{1}, true
+}
+
+// recomputeOptions 逐个判断选项是否正确
+func (a Auditor) recomputeOptions(q models.Question, f formulas) ([]int, bool) {
+	v := a.validator
+	var correct func(i int) (bool, bool)
+
+	formulaOption := func(check func(*core.Node) bool) func(int) (bool, bool) {
+		return func(i int) (bool, bool) {
+			if i >= len(f.options) || f.options[i] == nil {
+				return false, false
+			}
+			return check(f.options[i]), true
+		}
+	}
+	assignmentOption := func(check func(map[string]bool) bool) func(int) (bool, bool) {
+		return func(i int) (bool, bool) {
+			assign, ok := parseAssignment(q.Options[i])
+			if !ok {
+				return false, false
+			}
+			return check(assign), true
+		}
+	}
+
+	switch q.Blueprint.Intent {
+	case "TT_TRUE_ASSIGNMENTS", "TT_FALSE_ASSIGNMENTS":
+		if f.target == nil {
+			return nil, false
+		}
+		want := q.Blueprint.Intent == "TT_TRUE_ASSIGNMENTS"
+		correct = assignmentOption(func(assign map[string]bool) bool { return v.Eval(f.target, assign) == want })
+	case "INF_COUNTEREXAMPLE":
+		if f.target == nil || len(f.premises) == 0 {
+			return nil, false
+		}
+		correct = assignmentOption(func(assign map[string]bool) bool {
+			for _, p := range f.premises {
+				if !v.Eval(p, assign) {
+					return false
+				}
+			}
+			return !v.Eval(f.target, assign)
+		})
+	case "EQ_EQUIVALENT", "EQ_NONEQUIVALENT":
+		if f.target == nil {
+			return nil, false
+		}
+		want := q.Blueprint.Intent == "EQ_EQUIVALENT"
+		correct = formulaOption(func(n *core.Node) bool { return v.Equivalent(f.target, n, f.vars) == want })
+	case "INF_DERIVABLE", "INF_UNDERIVABLE":
+		if len(f.premises) == 0 {
+			return nil, false
+		}
+		want := q.Blueprint.Intent == "INF_DERIVABLE"
+		correct = formulaOption(func(n *core.Node) bool { return v.Derivable(f.premises, n, f.vars) == want })
+	case "NF_CNF", "NF_DNF":
+		if f.target == nil {
+			return nil, false
+		}
+		outer, inner := core.And, core.Or
+		if q.Blueprint.Intent == "NF_DNF" {
+			outer, inner = core.Or, core.And
+		}
+		correct = formulaOption(func(n *core.Node) bool {
+			return isNormalForm(n, outer, inner) && v.Equivalent(f.target, n, f.vars)
+		})
+	case "CLS_TAUTOLOGY", "CLS_CONTRADICTION", "CLS_SATISFIABLE":
+		correct = formulaOption(func(n *core.Node) bool {
+			class := a.class(n, f.vars)
+			switch q.Blueprint.Intent {
+			case "CLS_TAUTOLOGY":
+				return class == core.ClassTautology
+			case "CLS_CONTRADICTION":
+				return class == core.ClassContradiction
+			default:
+				return class != core.ClassContradiction
+			}
+		})
+	case "PRF_RULE", "PRF_FORMULA":
+		// 把选项填进被问的行，再检查该行
+		if f.line < 1 || f.line > len(f.proof) {
+			return nil, false
+		}
+		fill := func(edit func(*proof.Line)) bool {
+			lines := append([]proof.Line(nil), f.proof...)
+			edit(&lines[f.line-1])
+			return validStep(lines, f.line-1, f.premises)
+		}
+		if q.Blueprint.Intent == "PRF_FORMULA" {
+			correct = formulaOption(func(n *core.Node) bool {
+				return fill(func(l *proof.Line) { l.Formula = n })
+			})
+			break
+		}
+		correct = func(i int) (bool, bool) {
+			rule, err := proof.ParseRule(q.Options[i])
+			if err != nil {
+				return false, false
+			}
+			return fill(func(l *proof.Line) { l.Rule = rule }), true
+		}
+	case "RES_RESOLVENT":
+		if len(f.clauses) != 2 {
+			return nil, false
+		}
+		want := resolvents(f.clauses)
+		correct = func(i int) (bool, bool) {
+			node, ok := parseClauseSet(q.Options[i])
+			if strings.TrimSpace(q.Options[i]) == resolution.Empty {
+				node, ok = &core.Node{Kind: core.False}, true
+			}
+			if !ok {
+				return false, false
+			}
+			c, ok := toClause(node)
+			return want[clauseKey(c)], ok
+		}
+	case "TAB_OPEN_BRANCH":
+		if f.target == nil || len(f.premises) == 0 {
+			return nil, false
+		}
+		root := append(append([]*core.Node(nil), f.premises...), &core.Node{Kind: core.Not, Left: f.target})
+		tab, err := tableau.Build(root, 0)
+		if err != nil {
+			return nil, false
+		}
+		open := make(map[string]bool)
+		for _, b := range tab.Open() {
+			open[clauseKey(b.Literals)] = true
+		}
+		correct = func(i int) (bool, bool) {
+			literals, ok := parseBranch(q.Options[i])
+			return open[clauseKey(literals)], ok
+		}
+	case "RES_UNSAT":
+		correct = func(i int) (bool, bool) {
+			set, ok := parseClauseSet(q.Options[i])
+			if !ok {
+				return false, false
+			}
+			_, satisfiable := v.EquivalenceCounterexample(set, &core.Node{Kind: core.False}, setVars(set))
+			return !satisfiable, true
+		}
+	default:
+		return nil, false
+	}
+
+	want := make([]int, 0)
+	for i := range q.Options {
+		holds, ok := correct(i)
+		if !ok {
+			return nil, false
+		}
+		if holds {
+			want = append(want, i)
+		}
+	}
+	return want, true
+}
+
+// class 与分类题的生成器一致：恒真、恒假或可满足但非恒真
+func (a Auditor) class(n *core.Node, vars []string) string {
+	if a.validator.Equivalent(n, &core.Node{Kind: core.True}, vars) {
+		return core.ClassTautology
+	}
+	if a.validator.Equivalent(n, &core.Node{Kind: core.False}, vars) {
+		return core.ClassContradiction
+	}
+	return core.ClassContingent
+}
+
+// degenerate 结构上无法作答的题目
+func degenerate(q models.Question) []Finding {
+	var out []Finding
+	add := func(format string, args ...any) {
+		out = append(out, Finding{Kind: Degenerate, Detail: fmt.Sprintf(format, args...)})
+	}
+	if strings.TrimSpace(q.QuestionText) == "" {
+		add("empty question text")
+	}
+	if len(q.Options) < 2 {
+		add("%d options", len(q.Options))
+	}
+	if len(q.OptionExplanations) > 0 && len(q.OptionExplanations) != len(q.Options) {
+		add("%d option explanations for %d options", len(q.OptionExplanations), len(q.Options))
+	}
+	seen := make(map[int]bool)
+	for _, i := range q.CorrectAnswerIndex {
+		if i < 0 || i >= len(q.Options) {
+			add("correct answer index %d out of range", i)
+		}
+		if seen[i] {
+			add("correct answer index %d listed twice", i)
+		}
+		seen[i] = true
+	}
+	switch q.Type {
+	case models.QuestionTypeSingleChoice, models.QuestionTypeTrueFalse:
+		if len(q.CorrectAnswerIndex) != 1 {
+			add("%s question with %d correct options", q.Type, len(q.CorrectAnswerIndex))
+		}
+	case models.QuestionTypeMultipleChoice:
+		if len(q.CorrectAnswerIndex) == 0 {
+			add("multiple choice question without a correct option")
+		}
+	}
+	if q.Type == models.QuestionTypeTrueFalse && (len(q.Options) != 2 || q.Options[0] != "True" || q.Options[1] != "False") {
+		add("true/false question with options %q", q.Options)
+	}
+	return out
+}
+
+// duplicates 文本相同，或者公式相同（只是空白、括号或记法不同）的选项
+func duplicates(q models.Question, f formulas) []Finding {
+	var out []Finding
+	first := make(map[string]int)
+	for i, option := range q.Options {
+		key := "text:" + strings.Join(strings.Fields(option), " ")
+		if i < len(f.options) && f.options[i] != nil {
+			key = "formula:" + helper.Stringify(f.options[i])
+		}
+		if j, dup := first[key]; dup {
+			out = append(out, Finding{Kind: DuplicateOptions, Detail: fmt.Sprintf("options %d and %d are the same: %q", j, i, option)})
+			continue
+		}
+		first[key] = i
+	}
+	return out
+}
+
+// isNormalForm 判断 n 是否为 outer 连接若干 inner 子句的形式；⊤ / ⊥ 是常量范式
+func isNormalForm(n *core.Node, outer, inner core.NodeKind) bool {
+	if n.Kind == core.True || n.Kind == core.False {
+		return true
+	}
+	var clause func(*core.Node) bool
+	clause = func(n *core.Node) bool {
+		switch n.Kind {
+		case core.Var:
+			return true
+		case core.Not:
+			return n.Left.Kind == core.Var
+		case inner:
+			return clause(n.Left) && clause(n.Right)
+		default:
+			return false
+		}
+	}
+	var form func(*core.Node) bool
+	form = func(n *core.Node) bool {
+		return clause(n) || (n.Kind == outer && form(n.Left) && form(n.Right))
+	}
+	return form(n)
+}
+
+// assignmentPattern 匹配 helper.AssignmentStringify 的输出，如 "p=T, q=F"
+var assignmentPattern = regexp.MustCompile(`\b\w+=[TF](?:, \w+=[TF])*`)
+
+func parseAssignment(s string) (map[string]bool, bool) {
+	s = strings.TrimSpace(s)
+	if assignmentPattern.FindString(s) != s || s == "" {
+		return nil, false
+	}
+	assign := make(map[string]bool)
+	for _, part := range strings.Split(s, ", ") {
+		name, value, _ := strings.Cut(part, "=")
+		assign[name] = value == "T"
+	}
+	return assign, true
+}
+
+// findAssignment 取出题干中的赋值，只有一个时才可用
+func findAssignment(text string) (map[string]bool, bool) {
+	matches := assignmentPattern.FindAllString(text, -1)
+	if len(matches) != 1 {
+		return nil, false
+	}
+	return parseAssignment(matches[0])
+}
+
+// classPhrases 与分类判断题题干中的写法一致
+var classPhrases = map[string]string{
+	"a tautology":     core.ClassTautology,
+	"a contradiction": core.ClassContradiction,
+	"contingent":      core.ClassContingent,
+}
+
+func findClass(text string) (string, bool) {
+	found := ""
+	for phrase, class := range classPhrases {
+		if strings.Contains(text, phrase) {
+			if found != "" {
+				return "", false
+			}
+			found = class
+		}
+	}
+	return found, found != ""
+}
+
+// parseClauseSet 把 "{p, ¬q}, {q}" 这样的子句集读成合取范式
+func parseClauseSet(s string) (*core.Node, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil, false
+	}
+	var set *core.Node
+	for _, text := range strings.Split(s[1:len(s)-1], "}, {") {
+		clause := &core.Node{Kind: core.False}
+		if strings.TrimSpace(text) != "" {
+			lits, err := parser.ParseList(text)
+			if err != nil {
+				return nil, false
+			}
+			clause = lits[0]
+			for _, l := range lits[1:] {
+				clause = &core.Node{Kind: core.Or, Left: clause, Right: l}
+			}
+		}
+		if set == nil {
+			set = clause
+		} else {
+			set = &core.Node{Kind: core.And, Left: set, Right: clause}
+		}
+	}
+	return set, set != nil
+}
+
+// validStep 按规则检查第 i 行；前提行必须是题目的前提之一
+func validStep(lines []proof.Line, i int, premises []*core.Node) bool {
+	l := lines[i]
+	if l.Formula == nil || l.Rule == "" {
+		return false
+	}
+	if l.Rule == proof.Premise {
+		for _, p := range premises {
+			if helper.Stringify(p) == helper.Stringify(l.Formula) {
+				return len(l.Cites) == 0
+			}
+		}
+		return false
+	}
+	return proof.Proof{Lines: lines}.CheckLine(i) == nil
+}
+
+// resolvents 两个子句的全部消解结果
+func resolvents(clauses []normalform.Clause) map[string]bool {
+	out := make(map[string]bool)
+	for _, step := range resolution.Resolvents(clauses[0], clauses[1]) {
+		out[clauseKey(step.Resolvent)] = true
+	}
+	return out
+}
+
+// toClause 把文字的析取读成子句，⊥ 为空子句
+func toClause(n *core.Node) (normalform.Clause, bool) {
+	if n == nil {
+		return nil, false
+	}
+	switch n.Kind {
+	case core.False:
+		return normalform.Clause{}, true
+	case core.Or:
+		left, ok := toClause(n.Left)
+		if !ok || n.Left.Kind == core.False {
+			return nil, false
+		}
+		right, ok := toClause(n.Right)
+		if !ok || n.Right.Kind == core.False {
+			return nil, false
+		}
+		return append(left, right...), true
+	}
+	l, ok := toLiteral(n)
+	return normalform.Clause{l}, ok
+}
+
+func toLiteral(n *core.Node) (normalform.Literal, bool) {
+	switch {
+	case n.Kind == core.Var:
+		return normalform.Literal{Var: n.Name}, true
+	case n.Kind == core.Not && n.Left.Kind == core.Var:
+		return normalform.Literal{Var: n.Left.Name, Negated: true}, true
+	}
+	return normalform.Literal{}, false
+}
+
+// parseBranch 把 "p, ¬q" 读成分支上的文字，没有文字的分支写作 "∅"
+func parseBranch(s string) ([]normalform.Literal, bool) {
+	s = strings.TrimSpace(s)
+	if s == "∅" {
+		return nil, true
+	}
+	nodes, err := parser.ParseList(s)
+	if err != nil {
+		return nil, false
+	}
+	literals := make([]normalform.Literal, len(nodes))
+	for i, n := range nodes {
+		l, ok := toLiteral(n)
+		if !ok {
+			return nil, false
+		}
+		literals[i] = l
+	}
+	return literals, true
+}
+
+// clauseKey 与文字顺序无关的写法，用于比较子句和分支
+func clauseKey(literals []normalform.Literal) string {
+	parts := make([]string, len(literals))
+	for i, l := range literals {
+		parts[i] = l.String()
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+func setVars(n *core.Node) []string {
+	seen := make(map[string]bool)
+	var walk func(*core.Node)
+	walk = func(n *core.Node) {
+		if n == nil {
+			return
+		}
+		if n.Kind == core.Var {
+			seen[n.Name] = true
+		}
+		walk(n.Left)
+		walk(n.Right)
+	}
+	walk(n)
+	vars := make([]string, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
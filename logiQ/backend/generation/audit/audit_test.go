@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"backend/generation/render"
+	"backend/generation/service"
+	"backend/generation/validator"
+	"backend/models"
+	"context"
+	"testing"
+)
+
+func generate(t *testing.T, req service.BatchRequest) []models.Question {
+	t.Helper()
+	questions, err := service.NewService().GenerateBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	return questions
+}
+
+func TestGeneratedQuestionsPass(t *testing.T) {
+	a := New(validator.NewBitsetValidator())
+	questions := generate(t, service.BatchRequest{Num: 160, MasterSeed: 11})
+	// 记法只改变文本，结构化公式不受影响
+	questions = append(questions, generate(t, service.BatchRequest{Num: 40, MasterSeed: 12, Renderer: render.Renderer{Notation: render.ASCII}})...)
+	for _, q := range questions {
+		res := a.Check(q)
+		for _, f := range res.Findings {
+			t.Errorf("seed %d (%s): %s: %s", q.Blueprint.Seed, q.Blueprint.Intent, f.Kind, f.Detail)
+		}
+		if !res.Verified {
+			t.Errorf("seed %d (%s): answer key not recomputed", q.Blueprint.Seed, q.Blueprint.Intent)
+		}
+	}
+}
+
+func TestWrongAnswerIsFlagged(t *testing.T) {
+	a := New(validator.NewBitsetValidator())
+	flagged := 0
+	for _, q := range generate(t, service.BatchRequest{Num: 60, MasterSeed: 5}) {
+		if q.Type == models.QuestionTypeMultipleChoice {
+			continue
+		}
+		// 把正确选项换成下一个选项
+		q.CorrectAnswerIndex = []int{(q.CorrectAnswerIndex[0] + 1) % len(q.Options)}
+		if !hasKind(a.Check(q), WrongAnswer) {
+			t.Errorf("seed %d (%s): tampered answer key not flagged", q.Blueprint.Seed, q.Blueprint.Intent)
+		}
+		flagged++
+	}
+	if flagged == 0 {
+		t.Fatal("no question was tampered with")
+	}
+}
+
+func TestDuplicateAndDegenerate(t *testing.T) {
+	a := New(validator.NewBitsetValidator())
+	p := &models.FormulaNode{Op: "VAR", Name: "p"}
+	q := &models.FormulaNode{Op: "VAR", Name: "q"}
+	question := models.Question{
+		QuestionText:       "Which of the following formulas is a tautology?",
+		Options:            []string{"p ∨ ¬p", "p ∧ q", "(p ∧ q)", "¬p"},
+		CorrectAnswerIndex: []int{0},
+		Type:               models.QuestionTypeSingleChoice,
+		Category:           models.QuestionCategoryClassification,
+		Blueprint:          &models.QuestionBlueprint{Intent: "CLS_TAUTOLOGY"},
+		Formulas: &models.QuestionFormulas{
+			Options: []*models.FormulaNode{
+				{Op: "OR", Left: p, Right: &models.FormulaNode{Op: "NOT", Left: p}},
+				{Op: "AND", Left: p, Right: q},
+				{Op: "AND", Left: p, Right: q},
+				{Op: "NOT", Left: p},
+			},
+			Vars: []string{"p", "q"},
+		},
+	}
+	res := a.Check(question)
+	if !res.Verified || !hasKind(res, DuplicateOptions) || hasKind(res, WrongAnswer) || hasKind(res, Degenerate) {
+		t.Errorf("duplicate formulas: %+v", res)
+	}
+
+	question.CorrectAnswerIndex = []int{0, 4}
+	if res := a.Check(question); !hasKind(res, Degenerate) || !hasKind(res, WrongAnswer) {
+		t.Errorf("out of range index: %+v", res)
+	}
+
+	question.Formulas.Options[3] = &models.FormulaNode{Op: "NOT"}
+	if res := a.Check(question); !hasKind(res, InvalidFormulas) {
+		t.Errorf("broken payload: %+v", res)
+	}
+
+	manual := models.Question{
+		QuestionText:       "Is p → p a tautology?",
+		Options:            []string{"True", "False"},
+		CorrectAnswerIndex: []int{0},
+		Type:               models.QuestionTypeTrueFalse,
+	}
+	if res := a.Check(manual); !res.OK() || res.Verified {
+		t.Errorf("manual question: %+v", res)
+	}
+}
+
+func hasKind(res Result, kind Kind) bool {
+	for _, f := range res.Findings {
+		if f.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -54,6 +54,25 @@ func (Builder) BuildChoice(params Params, rng *rand.Rand) (Choice, error) {
 	}
 }
 
+// OptionCounts returns how many correct options and distractors BuildChoice
+// draws for a choice plan. Single choice takes 1 and 3; multiple choice takes
+// plan.MCCorrectCount (2 when unset, at most 4) and fills up to 4 options.
+// Generators use it to check that their pools can fill the question.
+func OptionCounts(plan sampler.Plan) (correct, distractors int) {
+	if plan.QType != models.QuestionTypeMultipleChoice {
+		return 1, 3
+	}
+	// 确保正确选项数在合理范围内
+	k := plan.MCCorrectCount
+	if k <= 0 {
+		k = 2
+	}
+	if k > 4 {
+		k = 4
+	}
+	return k, 4 - k
+}
+
 func buildSC(plan sampler.Plan, intent config.IntentSpec, pools core.CandidatePools, rng *rand.Rand) (Choice, error) {
 	// 单选：从配置映射到的正确池抽 1、从干扰池抽 3
 	correctPool, distractorPool, err := resolvePools(plan, intent.PoolMapping.SC, pools)
@@ -61,8 +80,9 @@ func buildSC(plan sampler.Plan, intent config.IntentSpec, pools core.CandidatePo
 		return Choice{}, err
 	}
 
-	correct := sampleUnique(correctPool, 1, rng)
-	distractors := sampleUnique(distractorPool, 3, rng)
+	k, rest := OptionCounts(plan)
+	correct := sampleUnique(correctPool, k, rng)
+	distractors := sampleUnique(distractorPool, rest, rng)
 
 	options, indexes := combineAndShuffle(correct, distractors, rng)
 	return Choice{Options: options, CorrectIndexes: indexes}, nil
@@ -74,16 +94,9 @@ func buildMC(plan sampler.Plan, intent config.IntentSpec, pools core.CandidatePo
 	if err != nil {
 		return Choice{}, err
 	}
-	// 确保正确选项数在合理范围内
-	k := plan.MCCorrectCount
-	if k <= 0 {
-		k = 2
-	}
-	if k > 4 {
-		k = 4
-	}
+	k, rest := OptionCounts(plan)
 	correct := sampleUnique(correctPool, k, rng)
-	distractors := sampleUnique(distractorPool, 4-k, rng)
+	distractors := sampleUnique(distractorPool, rest, rng)
 
 	options, indexes := combineAndShuffle(correct, distractors, rng)
 	return Choice{Options: options, CorrectIndexes: indexes}, nil
@@ -126,8 +139,59 @@ func resolvePools(plan sampler.Plan, mapping *config.PoolMapping, pools core.Can
 			return nil, nil, ErrMissingPool
 		}
 		return selectPool(mapping, map[string][]string{
-			"ValidConclusions":   pools.Inference.ValidConclusions,
-			"InvalidConclusions": pools.Inference.InvalidConclusions,
+			"ValidConclusions":     pools.Inference.ValidConclusions,
+			"InvalidConclusions":   pools.Inference.InvalidConclusions,
+			"CounterexampleSet":    pools.Inference.CounterexampleSet,
+			"NonCounterexampleSet": pools.Inference.NonCounterexampleSet,
+		})
+	case models.QuestionCategoryClassification:
+		if pools.Classification == nil {
+			return nil, nil, ErrMissingPool
+		}
+		c := pools.Classification
+		return selectPool(mapping, map[string][]string{
+			"TautologyPool":     c.Tautologies,
+			"NonTautologyPool":  concat(c.Contradictions, c.Contingents),
+			"ContradictionPool": c.Contradictions,
+			"SatisfiablePool":   concat(c.Tautologies, c.Contingents),
+		})
+	case models.QuestionCategoryNormalForm:
+		if pools.NormalForm == nil {
+			return nil, nil, ErrMissingPool
+		}
+		return selectPool(mapping, map[string][]string{
+			"CNFPool":        pools.NormalForm.CNFPool,
+			"CNFDistractors": pools.NormalForm.CNFDistractors,
+			"DNFPool":        pools.NormalForm.DNFPool,
+			"DNFDistractors": pools.NormalForm.DNFDistractors,
+		})
+	case models.QuestionCategoryProof:
+		if pools.Proof == nil {
+			return nil, nil, ErrMissingPool
+		}
+		return selectPool(mapping, map[string][]string{
+			"RuleAnswer":         {pools.Proof.Rule},
+			"RuleDistractors":    pools.Proof.RuleDistractors,
+			"FormulaAnswer":      {pools.Proof.Formula},
+			"FormulaDistractors": pools.Proof.FormulaDistractors,
+		})
+	case models.QuestionCategoryResolution:
+		if pools.Resolution == nil {
+			return nil, nil, ErrMissingPool
+		}
+		return selectPool(mapping, map[string][]string{
+			"ResolventAnswer":      {pools.Resolution.Resolvent},
+			"ResolventDistractors": pools.Resolution.ResolventDistractors,
+			"UnsatSets":            pools.Resolution.UnsatSets,
+			"SatSets":              pools.Resolution.SatSets,
+		})
+	case models.QuestionCategoryTableau:
+		if pools.Tableau == nil {
+			return nil, nil, ErrMissingPool
+		}
+		return selectPool(mapping, map[string][]string{
+			"OpenBranches":      pools.Tableau.OpenBranches,
+			"BranchDistractors": pools.Tableau.BranchDistractors,
 		})
 	default:
 		return nil, nil, ErrUnsupportedIntent
@@ -147,6 +211,10 @@ func selectPool(mapping *config.PoolMapping, pools map[string][]string) ([]strin
 	return correct, distractor, nil
 }
 
+func concat(a, b []string) []string {
+	return append(append(make([]string, 0, len(a)+len(b)), a...), b...)
+}
+
 func sampleUnique(pool []string, n int, rng *rand.Rand) []string {
 	// 随机抽取 n 个互不重复元素；不足时取全部
 	if n <= 0 {
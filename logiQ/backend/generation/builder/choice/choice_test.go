@@ -1,6 +1,7 @@
-package choice
+package choice_test
 
 import (
+	"backend/generation/builder/choice"
 	"backend/generation/config"
 	"backend/generation/core"
 	"backend/generation/generator/tt"
@@ -45,13 +46,13 @@ func TestBuildChoice(t *testing.T) {
 	if !ok {
 		t.Fatalf("intent not found")
 	}
-	var param = Params{
+	var param = choice.Params{
 		Plan:   plan,
 		Intent: intentSpec,
 		Pools:  pools,
 		//TFAnswer: false,
 	}
-	choiceBuilder := NewBuilder()
+	choiceBuilder := choice.NewBuilder()
 	choice, err := choiceBuilder.BuildChoice(param, rng)
 	if err != nil {
 		t.Fatalf("failed to build choice: %v", err)
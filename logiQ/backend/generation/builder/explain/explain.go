@@ -0,0 +1,429 @@
+package explain
+
+import (
+	"backend/generation/builder/choice"
+	"backend/generation/core"
+	"backend/generation/helper"
+	"backend/generation/proof"
+	"backend/generation/sampler"
+	"backend/models"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Explanation holds the review text of a question: an overall explanation and
+// one line per option saying why that option is right or wrong.
+type Explanation struct {
+	Text    string
+	Options []string
+}
+
+var (
+	ErrMissingPool = errors.New("explain: required candidate pool not available")
+)
+
+// Builder turns candidate pools and the drawn options into review explanations.
+type Builder struct{}
+
+func NewBuilder() Builder { return Builder{} }
+
+// Params bundles inputs required to explain a question.
+type Params struct {
+	Plan     sampler.Plan
+	Pools    core.CandidatePools
+	Data     map[string]string // prepare 阶段生成的模板数据，判断题需要其中的 alpha / G / Conclusion
+	TFAnswer bool
+	Choice   choice.Choice
+}
+
+// BuildExplanation explains the answer according to the question category.
+func (Builder) BuildExplanation(params Params) (Explanation, error) {
+	switch params.Plan.Category {
+	case models.QuestionCategoryTruthTable:
+		return explainTruthTable(params)
+	case models.QuestionCategoryEquivalence:
+		return explainEquivalence(params)
+	case models.QuestionCategoryInference:
+		return explainInference(params)
+	case models.QuestionCategoryNormalForm:
+		return explainNormalForm(params)
+	case models.QuestionCategoryClassification:
+		return explainClassification(params)
+	case models.QuestionCategoryProof:
+		return explainProof(params)
+	case models.QuestionCategoryResolution:
+		return explainResolution(params)
+	case models.QuestionCategoryTableau:
+		return explainTableau(params)
+	default:
+		return Explanation{}, fmt.Errorf("explain: unsupported category %s", params.Plan.Category)
+	}
+}
+
+func explainTruthTable(params Params) (Explanation, error) {
+	pools := params.Pools.TruthTable
+	if pools == nil {
+		return Explanation{}, ErrMissingPool
+	}
+	formula := helper.Stringify(pools.Formula)
+	trueRows := toSet(pools.TrueSet)
+
+	// 列出完整真值表，每行给出公式的取值
+	lines := make([]string, 0, len(pools.TrueSet)+len(pools.FalseSet)+2)
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		alpha := params.Data["alpha"]
+		lines = append(lines, fmt.Sprintf("Under %s, %s evaluates to %s.", alpha, formula, truthLetter(params.TFAnswer)))
+	}
+	lines = append(lines, fmt.Sprintf("Truth table of %s:", formula))
+	for _, assign := range helper.EnumerateAssignments(pools.Vars) {
+		row := helper.AssignmentStringify(pools.Vars, assign)
+		_, isTrue := trueRows[row]
+		lines = append(lines, fmt.Sprintf("%s ⇒ %s", row, truthLetter(isTrue)))
+	}
+
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		return Explanation{Text: strings.Join(lines, "\n")}, nil
+	}
+
+	options := make([]string, len(params.Choice.Options))
+	for i, row := range params.Choice.Options {
+		if _, isTrue := trueRows[row]; isTrue {
+			options[i] = fmt.Sprintf("%s is true under %s.", formula, row)
+		} else {
+			options[i] = fmt.Sprintf("%s is false under %s.", formula, row)
+		}
+	}
+	return Explanation{Text: strings.Join(lines, "\n"), Options: options}, nil
+}
+
+func explainEquivalence(params Params) (Explanation, error) {
+	pools := params.Pools.Equivalence
+	if pools == nil {
+		return Explanation{}, ErrMissingPool
+	}
+	target := helper.Stringify(pools.Target)
+	equivalent := toSet(pools.EquivPool)
+
+	describe := func(candidate string) string {
+		_, isEquiv := equivalent[candidate]
+		steps := describeSteps(pools.Derivations[candidate])
+		if isEquiv {
+			if steps == "" {
+				return fmt.Sprintf("%s is equivalent to %s.", candidate, target)
+			}
+			return fmt.Sprintf("%s is equivalent to %s:\n%s", candidate, target, steps)
+		}
+		reason := ""
+		if row, ok := pools.Counterexamples[candidate]; ok {
+			reason = fmt.Sprintf(" Under %s the two formulas take different values.", row)
+		}
+		if steps == "" {
+			return fmt.Sprintf("%s is not equivalent to %s.%s", candidate, target, reason)
+		}
+		return fmt.Sprintf("%s is not equivalent to %s.%s It differs by a truth-changing rewrite:\n%s", candidate, target, reason, steps)
+	}
+
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		return Explanation{Text: describe(params.Data["G"])}, nil
+	}
+
+	options := make([]string, len(params.Choice.Options))
+	for i, candidate := range params.Choice.Options {
+		options[i] = describe(candidate)
+	}
+	return Explanation{Text: joinCorrect(options, params.Choice.CorrectIndexes), Options: options}, nil
+}
+
+func explainInference(params Params) (Explanation, error) {
+	pools := params.Pools.Inference
+	if pools == nil {
+		return Explanation{}, ErrMissingPool
+	}
+	if params.Plan.Intent == "INF_COUNTEREXAMPLE" {
+		return explainCounterexample(params, pools)
+	}
+	valid := toSet(pools.ValidConclusions)
+	rule := strings.ReplaceAll(pools.TemplateName, "_", " ")
+
+	describe := func(conclusion string) string {
+		if _, ok := valid[conclusion]; ok {
+			return fmt.Sprintf("%s follows from the premises %s by %s.", conclusion, pools.Premises, rule)
+		}
+		row, ok := pools.Counterexamples[conclusion]
+		if branch, open := pools.OpenBranches[conclusion]; ok && open {
+			return fmt.Sprintf("%s does not follow from the premises %s: the tableau for the premises and the negation of %s keeps the branch %s open, so under %s the premises hold but %s is false.",
+				conclusion, pools.Premises, conclusion, branch, row, conclusion)
+		}
+		if ok {
+			return fmt.Sprintf("%s does not follow from the premises %s: under %s the premises hold but %s is false.", conclusion, pools.Premises, row, conclusion)
+		}
+		return fmt.Sprintf("%s does not follow from the premises %s: some assignment makes every premise true and %s false.", conclusion, pools.Premises, conclusion)
+	}
+
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		return Explanation{Text: describe(params.Data["Conclusion"])}, nil
+	}
+
+	options := make([]string, len(params.Choice.Options))
+	for i, conclusion := range params.Choice.Options {
+		options[i] = describe(conclusion)
+	}
+	return Explanation{Text: joinCorrect(options, params.Choice.CorrectIndexes), Options: options}, nil
+}
+
+// explainCounterexample 反例题的选项是赋值，逐个说明它是否推翻了题干中的推理
+func explainCounterexample(params Params, pools *core.InferencePools) (Explanation, error) {
+	conclusion := pools.CounterexampleConclusion
+	refuting := toSet(pools.CounterexampleSet)
+
+	options := make([]string, len(params.Choice.Options))
+	for i, row := range params.Choice.Options {
+		if _, ok := refuting[row]; ok {
+			options[i] = fmt.Sprintf("Under %s the premises %s hold but %s is false, so the inference is invalid.", row, pools.Premises, conclusion)
+		} else {
+			options[i] = fmt.Sprintf("%s is not a counterexample: under it some premise is false or %s is true.", row, conclusion)
+		}
+	}
+	text := joinCorrect(options, params.Choice.CorrectIndexes)
+	// 开放分支即反例，给出一条供学生对照
+	if branch, ok := pools.OpenBranches[conclusion]; ok {
+		text += fmt.Sprintf("\nThe tableau for %s and the negation of %s keeps the branch %s open; the assignment it gives is a counterexample.",
+			pools.Premises, conclusion, branch)
+	}
+	return Explanation{Text: text, Options: options}, nil
+}
+
+func explainNormalForm(params Params) (Explanation, error) {
+	pools := params.Pools.NormalForm
+	if pools == nil {
+		return Explanation{}, ErrMissingPool
+	}
+	formula := helper.Stringify(pools.Formula)
+
+	form := params.Data["Form"]
+	if params.Plan.Intent == "NF_DNF" {
+		form = "DNF"
+	} else if params.Plan.Intent == "NF_CNF" {
+		form = "CNF"
+	}
+	correct := toSet(pools.CNFPool)
+	notes := pools.CNFNotes
+	if form == "DNF" {
+		correct = toSet(pools.DNFPool)
+		notes = pools.DNFNotes
+	}
+
+	describe := func(candidate string) string {
+		note := notes[candidate]
+		if _, ok := correct[candidate]; ok {
+			return fmt.Sprintf("%s is a %s of %s: %s.", candidate, form, formula, note)
+		}
+		reason := ""
+		if row, ok := pools.Counterexamples[candidate]; ok {
+			reason = fmt.Sprintf(" Under %s the two formulas take different values.", row)
+		}
+		return fmt.Sprintf("%s is not a %s of %s: %s.%s", candidate, form, formula, note, reason)
+	}
+
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		return Explanation{Text: describe(params.Data["G"])}, nil
+	}
+
+	options := make([]string, len(params.Choice.Options))
+	for i, candidate := range params.Choice.Options {
+		options[i] = describe(candidate)
+	}
+	return Explanation{Text: joinCorrect(options, params.Choice.CorrectIndexes), Options: options}, nil
+}
+
+func explainClassification(params Params) (Explanation, error) {
+	pools := params.Pools.Classification
+	if pools == nil {
+		return Explanation{}, ErrMissingPool
+	}
+	tautologies := toSet(pools.Tautologies)
+	contradictions := toSet(pools.Contradictions)
+
+	describe := func(formula string) string {
+		instance := ""
+		if schema, ok := pools.Schemas[formula]; ok {
+			instance = fmt.Sprintf(" (an instance of %s)", schema)
+		}
+		_, isTautology := tautologies[formula]
+		_, isContradiction := contradictions[formula]
+		if params.Plan.Intent == "CLS_SATISFIABLE" {
+			if isContradiction {
+				return fmt.Sprintf("%s is unsatisfiable%s: it is false under every assignment.", formula, instance)
+			}
+			return fmt.Sprintf("%s is satisfiable%s: it is true under %s.", formula, instance, pools.Satisfying[formula])
+		}
+		switch {
+		case isTautology:
+			return fmt.Sprintf("%s is a tautology%s: it is true under every assignment.", formula, instance)
+		case isContradiction:
+			return fmt.Sprintf("%s is a contradiction%s: it is false under every assignment.", formula, instance)
+		default:
+			return fmt.Sprintf("%s is contingent%s: it is true under %s and false under %s.", formula, instance, pools.Satisfying[formula], pools.Falsifying[formula])
+		}
+	}
+
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		return Explanation{Text: describe(params.Data["F"])}, nil
+	}
+
+	options := make([]string, len(params.Choice.Options))
+	for i, formula := range params.Choice.Options {
+		options[i] = describe(formula)
+	}
+	return Explanation{Text: joinCorrect(options, params.Choice.CorrectIndexes), Options: options}, nil
+}
+
+func explainProof(params Params) (Explanation, error) {
+	pools := params.Pools.Proof
+	if pools == nil || len(pools.CorrectSteps) == 0 {
+		return Explanation{}, ErrMissingPool
+	}
+	schema := fmt.Sprintf("%s (%s)", pools.Rule, proof.Rule(pools.Rule).Schema())
+	justified := fmt.Sprintf("%s is an instance of %s", pools.CorrectSteps[0], schema)
+
+	describe := func(candidate string) string {
+		switch params.Plan.Intent {
+		case "PRF_RULE":
+			if candidate == pools.Rule {
+				return fmt.Sprintf("%s justifies line %d: %s.", candidate, pools.Line, justified)
+			}
+			return fmt.Sprintf("%s does not justify line %d: %s.", candidate, pools.Line, pools.Notes[candidate])
+		case "PRF_FORMULA":
+			if candidate == pools.Formula {
+				return fmt.Sprintf("%s belongs on line %d: %s.", candidate, pools.Line, justified)
+			}
+			return fmt.Sprintf("%s does not belong on line %d: %s.", candidate, pools.Line, pools.Notes[candidate])
+		default:
+			if note, wrong := pools.Notes[candidate]; wrong {
+				return fmt.Sprintf("%s is not a valid step: %s.", candidate, note)
+			}
+			return fmt.Sprintf("%s is a valid step: it is an instance of %s.", candidate, schema)
+		}
+	}
+
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		return Explanation{Text: describe(params.Data["Step"])}, nil
+	}
+
+	options := make([]string, len(params.Choice.Options))
+	for i, candidate := range params.Choice.Options {
+		options[i] = describe(candidate)
+	}
+	return Explanation{Text: joinCorrect(options, params.Choice.CorrectIndexes), Options: options}, nil
+}
+
+func explainResolution(params Params) (Explanation, error) {
+	pools := params.Pools.Resolution
+	if pools == nil || pools.Resolvent == "" {
+		return Explanation{}, ErrMissingPool
+	}
+	unsat := toSet(pools.UnsatSets)
+
+	describe := func(candidate string) string {
+		if params.Plan.Intent == "RES_UNSAT" {
+			if _, ok := unsat[candidate]; ok {
+				return fmt.Sprintf("%s is unsatisfiable: %s.", candidate, pools.SetNotes[candidate])
+			}
+			return fmt.Sprintf("%s is satisfiable: %s.", candidate, pools.SetNotes[candidate])
+		}
+		if candidate == pools.Resolvent {
+			return fmt.Sprintf("%s is the resolvent of %s and %s: resolving on %s removes %s and ¬%s and keeps the remaining literals.",
+				candidate, pools.Left, pools.Right, pools.Pivot, pools.Pivot, pools.Pivot)
+		}
+		return fmt.Sprintf("%s is not the resolvent of %s and %s: %s.", candidate, pools.Left, pools.Right, pools.ResolventNotes[candidate])
+	}
+
+	// 消解结果题附上完整的反驳，说明这一步在其中的位置
+	refutation := ""
+	if params.Plan.Intent != "RES_UNSAT" {
+		lines := make([]string, len(pools.Refutation))
+		for i, step := range pools.Refutation {
+			lines[i] = fmt.Sprintf("%d. %s", i+1, step)
+		}
+		refutation = fmt.Sprintf("\nRefutation of %s:\n%s", pools.Clauses, strings.Join(lines, "\n"))
+	}
+
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		return Explanation{Text: describe(params.Data["C"]) + refutation}, nil
+	}
+
+	options := make([]string, len(params.Choice.Options))
+	for i, candidate := range params.Choice.Options {
+		options[i] = describe(candidate)
+	}
+	return Explanation{Text: joinCorrect(options, params.Choice.CorrectIndexes) + refutation, Options: options}, nil
+}
+
+func explainTableau(params Params) (Explanation, error) {
+	pools := params.Pools.Tableau
+	if pools == nil {
+		return Explanation{}, ErrMissingPool
+	}
+
+	if params.Plan.QType == models.QuestionTypeTrueFalse {
+		conclusion := params.Data["Conclusion"]
+		note, ok := pools.ConclusionNotes[conclusion]
+		if !ok {
+			return Explanation{}, ErrMissingPool
+		}
+		if params.TFAnswer {
+			return Explanation{Text: fmt.Sprintf("The tableau for %s and the negation of %s closes: %s.", pools.Premises, conclusion, note)}, nil
+		}
+		return Explanation{Text: fmt.Sprintf("The tableau for %s and the negation of %s does not close: %s.", pools.Premises, conclusion, note)}, nil
+	}
+
+	open := toSet(pools.OpenBranches)
+	options := make([]string, len(params.Choice.Options))
+	for i, branch := range params.Choice.Options {
+		if _, ok := open[branch]; ok {
+			options[i] = fmt.Sprintf("%s is an open branch: %s.", branch, pools.BranchNotes[branch])
+		} else {
+			options[i] = fmt.Sprintf("%s is not an open branch: %s.", branch, pools.BranchNotes[branch])
+		}
+	}
+	// 开放分支即反例，说明结论不能由前提推出
+	summary := fmt.Sprintf("\nThe tableau has %d branches. Every open branch is a counterexample: %s does not follow from %s.",
+		pools.Branches, pools.Conclusion, pools.Premises)
+	return Explanation{Text: joinCorrect(options, params.Choice.CorrectIndexes) + summary, Options: options}, nil
+}
+
+// describeSteps 把推导步骤逐行渲染成 "1. de morgan: ¬(p ∧ q) ⇒ ¬p ∨ ¬q" 的形式
+func describeSteps(steps []core.DerivationStep) string {
+	lines := make([]string, len(steps))
+	for i, step := range steps {
+		lines[i] = fmt.Sprintf("%d. %s: %s ⇒ %s", i+1, strings.ReplaceAll(step.Rule, "_", " "), step.Before, step.After)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinCorrect 汇总正确选项的解释作为整题解释
+func joinCorrect(options []string, correct []int) string {
+	parts := make([]string, 0, len(correct))
+	for _, idx := range correct {
+		if idx >= 0 && idx < len(options) {
+			parts = append(parts, options[idx])
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+func truthLetter(value bool) string {
+	if value {
+		return "T"
+	}
+	return "F"
+}
@@ -0,0 +1,410 @@
+package explain
+
+import (
+	"backend/generation/builder/choice"
+	"backend/generation/core"
+	"backend/generation/sampler"
+	"backend/models"
+	"strings"
+	"testing"
+)
+
+func TestExplainTruthTable(t *testing.T) {
+	formula := &core.Node{Kind: core.And, Left: &core.Node{Kind: core.Var, Name: "p"}, Right: &core.Node{Kind: core.Var, Name: "q"}}
+	pools := core.CandidatePools{TruthTable: &core.TruthTablePools{
+		Formula:  formula,
+		Vars:     []string{"p", "q"},
+		TrueSet:  []string{"p=T, q=T"},
+		FalseSet: []string{"p=F, q=F", "p=T, q=F", "p=F, q=T"},
+	}}
+	params := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryTruthTable, QType: models.QuestionTypeSingleChoice, Intent: "TT_TRUE_ASSIGNMENTS"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"p=F, q=T", "p=T, q=T"}, CorrectIndexes: []int{1}},
+	}
+
+	exp, err := NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(exp.Text, "p=T, q=T ⇒ T") || !strings.Contains(exp.Text, "p=F, q=F ⇒ F") {
+		t.Errorf("truth table rows missing from explanation:\n%s", exp.Text)
+	}
+	if len(exp.Options) != 2 {
+		t.Fatalf("got %d option explanations, want 2", len(exp.Options))
+	}
+	if !strings.Contains(exp.Options[0], "false") || !strings.Contains(exp.Options[1], "true") {
+		t.Errorf("unexpected option explanations: %q", exp.Options)
+	}
+}
+
+func TestExplainEquivalenceRuleChain(t *testing.T) {
+	target := &core.Node{Kind: core.Not, Left: &core.Node{Kind: core.And, Left: &core.Node{Kind: core.Var, Name: "p"}, Right: &core.Node{Kind: core.Var, Name: "q"}}}
+	pools := core.CandidatePools{Equivalence: &core.EquivalencePools{
+		Target:       target,
+		Vars:         []string{"p", "q"},
+		EquivPool:    []string{"¬q ∨ ¬p"},
+		NonEquivPool: []string{"¬(p ∨ q)"},
+		Derivations: map[string][]core.DerivationStep{
+			"¬q ∨ ¬p": {
+				{Rule: "de_morgan", Before: "¬(p ∧ q)", After: "¬p ∨ ¬q"},
+				{Rule: "commutativity", Before: "¬p ∨ ¬q", After: "¬q ∨ ¬p"},
+			},
+			"¬(p ∨ q)": {{Rule: "flip_operator", Path: "L", Before: "p ∧ q", After: "p ∨ q"}},
+		},
+	}}
+	params := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryEquivalence, QType: models.QuestionTypeSingleChoice, Intent: "EQ_EQUIVALENT"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"¬(p ∨ q)", "¬q ∨ ¬p"}, CorrectIndexes: []int{1}},
+	}
+
+	exp, err := NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(exp.Options[1], "1. de morgan: ¬(p ∧ q) ⇒ ¬p ∨ ¬q\n2. commutativity: ¬p ∨ ¬q ⇒ ¬q ∨ ¬p") {
+		t.Errorf("derivation steps missing: %q", exp.Options[1])
+	}
+	if !strings.Contains(exp.Options[0], "not equivalent") {
+		t.Errorf("distractor should be explained as not equivalent: %q", exp.Options[0])
+	}
+	if exp.Text != exp.Options[1] {
+		t.Errorf("explanation = %q, want the correct option's explanation", exp.Text)
+	}
+}
+
+func TestExplainInferenceTF(t *testing.T) {
+	pools := core.CandidatePools{Inference: &core.InferencePools{
+		TemplateName:       "modus_tollens",
+		Premises:           "p → q, ¬q",
+		ValidConclusions:   []string{"¬p"},
+		InvalidConclusions: []string{"p"},
+	}}
+	params := Params{
+		Plan:     sampler.Plan{Category: models.QuestionCategoryInference, QType: models.QuestionTypeTrueFalse, Intent: "INF_VALIDITY_TF"},
+		Pools:    pools,
+		Data:     map[string]string{"Conclusion": "¬p"},
+		TFAnswer: true,
+		Choice:   choice.Choice{Options: []string{"True", "False"}, CorrectIndexes: []int{0}},
+	}
+
+	exp, err := NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(exp.Text, "modus tollens") {
+		t.Errorf("template name missing: %q", exp.Text)
+	}
+	if exp.Options != nil {
+		t.Errorf("true/false questions should not carry option explanations, got %q", exp.Options)
+	}
+}
+
+func TestExplainCounterexamples(t *testing.T) {
+	pools := core.CandidatePools{Inference: &core.InferencePools{
+		TemplateName:             "modus_ponens",
+		Premises:                 "p → q",
+		Vars:                     []string{"p", "q"},
+		ValidConclusions:         []string{"¬p ∨ q"},
+		InvalidConclusions:       []string{"q"},
+		Counterexamples:          map[string]string{"q": "p=F, q=F"},
+		CounterexampleConclusion: "q",
+		CounterexampleSet:        []string{"p=F, q=F"},
+		NonCounterexampleSet:     []string{"p=T, q=F", "p=F, q=T", "p=T, q=T"},
+	}}
+
+	underivable := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryInference, QType: models.QuestionTypeSingleChoice, Intent: "INF_UNDERIVABLE"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"q", "¬p ∨ q"}, CorrectIndexes: []int{0}},
+	}
+	exp, err := NewBuilder().BuildExplanation(underivable)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(exp.Options[0], "under p=F, q=F the premises hold but q is false") {
+		t.Errorf("witness missing from explanation: %q", exp.Options[0])
+	}
+
+	counter := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryInference, QType: models.QuestionTypeSingleChoice, Intent: "INF_COUNTEREXAMPLE"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"p=T, q=T", "p=F, q=F"}, CorrectIndexes: []int{1}},
+	}
+	exp, err = NewBuilder().BuildExplanation(counter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(exp.Options[0], "not a counterexample") || !strings.Contains(exp.Options[1], "inference is invalid") {
+		t.Errorf("unexpected option explanations: %q", exp.Options)
+	}
+	if exp.Text != exp.Options[1] {
+		t.Errorf("explanation = %q, want the correct option's explanation", exp.Text)
+	}
+}
+
+func TestExplainOpenBranches(t *testing.T) {
+	pools := core.CandidatePools{Inference: &core.InferencePools{
+		TemplateName:             "modus_ponens",
+		Premises:                 "p → q",
+		Vars:                     []string{"p", "q"},
+		ValidConclusions:         []string{"¬p ∨ q"},
+		InvalidConclusions:       []string{"q"},
+		Counterexamples:          map[string]string{"q": "p=F, q=F"},
+		OpenBranches:             map[string]string{"q": "¬p, ¬q"},
+		CounterexampleConclusion: "q",
+		CounterexampleSet:        []string{"p=F, q=F"},
+		NonCounterexampleSet:     []string{"p=T, q=F", "p=F, q=T", "p=T, q=T"},
+	}}
+
+	underivable := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryInference, QType: models.QuestionTypeSingleChoice, Intent: "INF_UNDERIVABLE"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"q", "¬p ∨ q"}, CorrectIndexes: []int{0}},
+	}
+	exp, err := NewBuilder().BuildExplanation(underivable)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(exp.Options[0], "keeps the branch ¬p, ¬q open, so under p=F, q=F") {
+		t.Errorf("open branch missing from explanation: %q", exp.Options[0])
+	}
+
+	counter := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryInference, QType: models.QuestionTypeSingleChoice, Intent: "INF_COUNTEREXAMPLE"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"p=T, q=T", "p=F, q=F"}, CorrectIndexes: []int{1}},
+	}
+	exp, err = NewBuilder().BuildExplanation(counter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(exp.Text, "keeps the branch ¬p, ¬q open") {
+		t.Errorf("open branch missing from explanation: %q", exp.Text)
+	}
+}
+
+func TestExplainNormalFormTF(t *testing.T) {
+	formula := &core.Node{Kind: core.Not, Left: &core.Node{Kind: core.And, Left: &core.Node{Kind: core.Var, Name: "p"}, Right: &core.Node{Kind: core.Var, Name: "q"}}}
+	pools := core.CandidatePools{NormalForm: &core.NormalFormPools{
+		Formula:        formula,
+		Vars:           []string{"p", "q"},
+		CNFPool:        []string{"¬p ∨ ¬q"},
+		CNFDistractors: []string{"¬p ∧ ¬q"},
+		CNFNotes: map[string]string{
+			"¬p ∨ ¬q": "it is the CNF obtained by distributing",
+			"¬p ∧ ¬q": "wrong De Morgan step: ¬(p ∧ q) rewritten as ¬p ∧ ¬q",
+		},
+		DNFNotes:        map[string]string{"¬p ∧ ¬q": "the literal p has the wrong sign"},
+		Counterexamples: map[string]string{"¬p ∧ ¬q": "p=T, q=F"},
+	}}
+	params := Params{
+		Plan:  sampler.Plan{Category: models.QuestionCategoryNormalForm, QType: models.QuestionTypeTrueFalse, Intent: "NF_PAIR_TF"},
+		Pools: pools,
+		Data:  map[string]string{"G": "¬p ∧ ¬q", "Form": "CNF"},
+	}
+
+	exp, err := NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"is not a CNF of ¬(p ∧ q)", "wrong De Morgan step", "Under p=T, q=F"} {
+		if !strings.Contains(exp.Text, want) {
+			t.Errorf("explanation %q missing %q", exp.Text, want)
+		}
+	}
+}
+
+func TestExplainClassification(t *testing.T) {
+	pools := core.CandidatePools{Classification: &core.ClassificationPools{
+		Tautologies:    []string{"p ∨ ¬p"},
+		Contradictions: []string{"q ∧ ¬q"},
+		Contingents:    []string{"p → q"},
+		Schemas:        map[string]string{"p ∨ ¬p": "A ∨ ¬A"},
+		Satisfying:     map[string]string{"p ∨ ¬p": "p=F", "p → q": "p=F, q=F"},
+		Falsifying:     map[string]string{"q ∧ ¬q": "q=F", "p → q": "p=T, q=F"},
+	}}
+	params := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryClassification, QType: models.QuestionTypeSingleChoice, Intent: "CLS_TAUTOLOGY"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"p → q", "p ∨ ¬p", "q ∧ ¬q"}, CorrectIndexes: []int{1}},
+	}
+
+	exp, err := NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"p → q is contingent: it is true under p=F, q=F and false under p=T, q=F.",
+		"p ∨ ¬p is a tautology (an instance of A ∨ ¬A): it is true under every assignment.",
+		"q ∧ ¬q is a contradiction: it is false under every assignment.",
+	}
+	for i, w := range want {
+		if exp.Options[i] != w {
+			t.Errorf("option %d: got %q, want %q", i, exp.Options[i], w)
+		}
+	}
+	if exp.Text != want[1] {
+		t.Errorf("text = %q, want %q", exp.Text, want[1])
+	}
+}
+
+func TestExplainProof(t *testing.T) {
+	pools := core.CandidatePools{Proof: &core.ProofPools{
+		Line:            3,
+		Rule:            "Modus Ponens",
+		RuleDistractors: []string{"Modus Tollens"},
+		CorrectSteps:    []string{"3. q (Modus Ponens, 1, 2)"},
+		WrongSteps:      []string{"3. q (Modus Tollens, 1, 2)"},
+		Notes: map[string]string{
+			"Modus Tollens":              "q does not follow from line(s) 1, 2 by Modus Tollens (A → B, ¬B ⊢ ¬A)",
+			"3. q (Modus Tollens, 1, 2)": "q does not follow from line(s) 1, 2 by Modus Tollens (A → B, ¬B ⊢ ¬A)",
+		},
+	}}
+	params := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryProof, QType: models.QuestionTypeSingleChoice, Intent: "PRF_RULE"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"Modus Tollens", "Modus Ponens"}, CorrectIndexes: []int{1}},
+	}
+
+	exp, err := NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"Modus Tollens does not justify line 3: q does not follow from line(s) 1, 2 by Modus Tollens (A → B, ¬B ⊢ ¬A).",
+		"Modus Ponens justifies line 3: 3. q (Modus Ponens, 1, 2) is an instance of Modus Ponens (A → B, A ⊢ B).",
+	}
+	for i, w := range want {
+		if exp.Options[i] != w {
+			t.Errorf("option %d: got %q, want %q", i, exp.Options[i], w)
+		}
+	}
+
+	params.Plan.QType, params.Plan.Intent = models.QuestionTypeTrueFalse, "PRF_STEP_TF"
+	params.Data = map[string]string{"Step": "3. q (Modus Tollens, 1, 2)"}
+	exp, err = NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "3. q (Modus Tollens, 1, 2) is not a valid step: q does not follow from line(s) 1, 2 by Modus Tollens (A → B, ¬B ⊢ ¬A)."; exp.Text != want {
+		t.Errorf("text = %q, want %q", exp.Text, want)
+	}
+}
+
+func TestExplainResolution(t *testing.T) {
+	pools := core.CandidatePools{Resolution: &core.ResolutionPools{
+		Clauses:              "{p}, {¬q}, {¬p, q}",
+		Refutation:           []string{"{p}, {¬p, q} ⊢ {q}", "{¬q}, {q} ⊢ □"},
+		Left:                 "{p}",
+		Right:                "{¬p, q}",
+		Pivot:                "p",
+		Resolvent:            "{q}",
+		ResolventDistractors: []string{"{p, ¬p, q}"},
+		ResolventNotes:       map[string]string{"{p, ¬p, q}": "the complementary literals p and ¬p must both be removed"},
+		UnsatSets:            []string{"{p}, {¬q}, {¬p, q}"},
+		SatSets:              []string{"{p}, {¬p, q}"},
+		SetNotes: map[string]string{
+			"{p}, {¬q}, {¬p, q}": "resolution derives the empty clause: {p}, {¬p, q} ⊢ {q}; {¬q}, {q} ⊢ □",
+			"{p}, {¬p, q}":       "every clause is true under p=T, q=T",
+		},
+	}}
+	params := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryResolution, QType: models.QuestionTypeSingleChoice, Intent: "RES_RESOLVENT"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"{p, ¬p, q}", "{q}"}, CorrectIndexes: []int{1}},
+	}
+
+	exp, err := NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"{p, ¬p, q} is not the resolvent of {p} and {¬p, q}: the complementary literals p and ¬p must both be removed.",
+		"{q} is the resolvent of {p} and {¬p, q}: resolving on p removes p and ¬p and keeps the remaining literals.",
+	}
+	for i, w := range want {
+		if exp.Options[i] != w {
+			t.Errorf("option %d: got %q, want %q", i, exp.Options[i], w)
+		}
+	}
+	if wantText := want[1] + "\nRefutation of {p}, {¬q}, {¬p, q}:\n1. {p}, {¬p, q} ⊢ {q}\n2. {¬q}, {q} ⊢ □"; exp.Text != wantText {
+		t.Errorf("text = %q, want %q", exp.Text, wantText)
+	}
+
+	params.Plan.QType, params.Plan.Intent = models.QuestionTypeSingleChoice, "RES_UNSAT"
+	params.Choice = choice.Choice{Options: []string{"{p}, {¬p, q}", "{p}, {¬q}, {¬p, q}"}, CorrectIndexes: []int{1}}
+	exp, err = NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = []string{
+		"{p}, {¬p, q} is satisfiable: every clause is true under p=T, q=T.",
+		"{p}, {¬q}, {¬p, q} is unsatisfiable: resolution derives the empty clause: {p}, {¬p, q} ⊢ {q}; {¬q}, {q} ⊢ □.",
+	}
+	for i, w := range want {
+		if exp.Options[i] != w {
+			t.Errorf("option %d: got %q, want %q", i, exp.Options[i], w)
+		}
+	}
+	if exp.Text != want[1] {
+		t.Errorf("text = %q, want %q", exp.Text, want[1])
+	}
+}
+
+func TestExplainTableau(t *testing.T) {
+	pools := core.CandidatePools{Tableau: &core.TableauPools{
+		Premises:          "p ∨ q",
+		Conclusion:        "p",
+		Formulas:          "p ∨ q, ¬p",
+		Branches:          2,
+		OpenBranches:      []string{"¬p, q"},
+		BranchDistractors: []string{"p, ¬p", "¬p, ¬q"},
+		BranchNotes: map[string]string{
+			"¬p, q":  "no pair of complementary literals occurs on it, and every formula is true under p=F, q=T",
+			"p, ¬p":  "the branch closes because it contains p and ¬p",
+			"¬p, ¬q": "no branch of the tableau has these literals, since they make p ∨ q false",
+		},
+		ClosingConclusions: []string{"q ∨ p"},
+		OpenConclusions:    []string{"p"},
+		ConclusionNotes: map[string]string{
+			"q ∨ p": "all 2 branches close, so q ∨ p follows from the premises",
+			"p":     "the branch ¬p, q stays open, so under p=F, q=T every premise is true and p is false",
+		},
+	}}
+	params := Params{
+		Plan:   sampler.Plan{Category: models.QuestionCategoryTableau, QType: models.QuestionTypeSingleChoice, Intent: "TAB_OPEN_BRANCH"},
+		Pools:  pools,
+		Choice: choice.Choice{Options: []string{"p, ¬p", "¬p, q", "¬p, ¬q"}, CorrectIndexes: []int{1}},
+	}
+
+	exp, err := NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"p, ¬p is not an open branch: the branch closes because it contains p and ¬p.",
+		"¬p, q is an open branch: no pair of complementary literals occurs on it, and every formula is true under p=F, q=T.",
+		"¬p, ¬q is not an open branch: no branch of the tableau has these literals, since they make p ∨ q false.",
+	}
+	for i, w := range want {
+		if exp.Options[i] != w {
+			t.Errorf("option %d: got %q, want %q", i, exp.Options[i], w)
+		}
+	}
+	if wantText := want[1] + "\nThe tableau has 2 branches. Every open branch is a counterexample: p does not follow from p ∨ q."; exp.Text != wantText {
+		t.Errorf("text = %q, want %q", exp.Text, wantText)
+	}
+
+	params.Plan.QType, params.Plan.Intent = models.QuestionTypeTrueFalse, "TAB_CLOSES_TF"
+	params.Data = map[string]string{"Conclusion": "q ∨ p"}
+	params.TFAnswer = true
+	exp, err = NewBuilder().BuildExplanation(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "The tableau for p ∨ q and the negation of q ∨ p closes: all 2 branches close, so q ∨ p follows from the premises."; exp.Text != want {
+		t.Errorf("text = %q, want %q", exp.Text, want)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"backend/models"
 	"fmt"
 	"math/rand/v2"
+	"strconv"
 )
 
 type PrepareResult struct {
@@ -51,6 +52,68 @@ func PreparePromptData(plan sampler.Plan, pools core.CandidatePools, rng *rand.R
 			}
 			data["Conclusion"] = conclusion
 			tfAnswer = isValid
+		} else if plan.Intent == "INF_COUNTEREXAMPLE" {
+			// 反例题的结论由生成器选定，选项是推翻它的赋值
+			data["Conclusion"] = pools.Inference.CounterexampleConclusion
+		}
+	case models.QuestionCategoryClassification:
+		if plan.QType == models.QuestionTypeTrueFalse {
+			claimed, isCorrect, err := sampleClassificationTF(pools.Classification, rng)
+			if err != nil {
+				return PrepareResult{}, err
+			}
+			data["F"] = helper.Stringify(pools.Classification.Formula)
+			data["Class"] = claimed
+			tfAnswer = isCorrect
+		}
+	case models.QuestionCategoryNormalForm:
+		data["F"] = helper.Stringify(pools.NormalForm.Formula)
+		if plan.QType == models.QuestionTypeTrueFalse {
+			candidate, form, isCorrect, err := sampleNormalFormTF(pools.NormalForm, rng)
+			if err != nil {
+				return PrepareResult{}, err
+			}
+			data["G"] = candidate
+			data["Form"] = form
+			tfAnswer = isCorrect
+		}
+	case models.QuestionCategoryProof:
+		data["Premises"] = pools.Proof.Premises
+		data["Proof"] = pools.Proof.Listing
+		data["Line"] = strconv.Itoa(pools.Proof.Line)
+		if plan.QType == models.QuestionTypeTrueFalse {
+			step, isCorrect, err := sampleProofTF(pools.Proof, rng)
+			if err != nil {
+				return PrepareResult{}, err
+			}
+			data["Step"] = step
+			tfAnswer = isCorrect
+		}
+	case models.QuestionCategoryResolution:
+		data["Premises"] = pools.Resolution.Premises
+		data["Conclusion"] = pools.Resolution.Conclusion
+		data["Clauses"] = pools.Resolution.Clauses
+		data["Left"] = pools.Resolution.Left
+		data["Right"] = pools.Resolution.Right
+		if plan.QType == models.QuestionTypeTrueFalse {
+			candidate, isCorrect, err := sampleResolutionTF(pools.Resolution, rng)
+			if err != nil {
+				return PrepareResult{}, err
+			}
+			data["C"] = candidate
+			tfAnswer = isCorrect
+		}
+	case models.QuestionCategoryTableau:
+		data["Premises"] = pools.Tableau.Premises
+		data["Conclusion"] = pools.Tableau.Conclusion
+		data["Formulas"] = pools.Tableau.Formulas
+		if plan.QType == models.QuestionTypeTrueFalse {
+			conclusion, closes, err := sampleTableauTF(pools.Tableau, rng)
+			if err != nil {
+				return PrepareResult{}, err
+			}
+			data["Conclusion"] = conclusion
+			tfAnswer = closes
 		}
 	default:
 		return PrepareResult{}, fmt.Errorf("service: unsupported category %s", plan.Category)
@@ -122,3 +185,113 @@ func sampleInferenceTF(pools *core.InferencePools, rng *rand.Rand) (string, bool
 	}
 	return pools.InvalidConclusions[idx-len(pools.ValidConclusions)], false, nil
 }
+
+// sampleNormalFormTF 从 CNF / DNF 的四个池中抽一个候选，同时返回它所属的范式
+func sampleNormalFormTF(pools *core.NormalFormPools, rng *rand.Rand) (string, string, bool, error) {
+	if rng == nil {
+		return "", "", false, fmt.Errorf("service: rng must not be nil")
+	}
+	if pools == nil {
+		return "", "", false, fmt.Errorf("service: nil normal form pools")
+	}
+
+	groups := []struct {
+		pool    []string
+		form    string
+		correct bool
+	}{
+		{pools.CNFPool, "CNF", true},
+		{pools.CNFDistractors, "CNF", false},
+		{pools.DNFPool, "DNF", true},
+		{pools.DNFDistractors, "DNF", false},
+	}
+	total := 0
+	for _, g := range groups {
+		total += len(g.pool)
+	}
+	if total == 0 {
+		return "", "", false, fmt.Errorf("service: normal form pools empty")
+	}
+
+	idx := rng.IntN(total)
+	for _, g := range groups {
+		if idx < len(g.pool) {
+			return g.pool[idx], g.form, g.correct, nil
+		}
+		idx -= len(g.pool)
+	}
+	return "", "", false, fmt.Errorf("service: normal form pools empty")
+}
+
+// classPhrases 题干中各类别的说法，如 "{F} is a tautology"
+var classPhrases = map[string]string{
+	core.ClassTautology:     "a tautology",
+	core.ClassContradiction: "a contradiction",
+	core.ClassContingent:    "contingent",
+}
+
+// sampleClassificationTF 一半概率给出公式的真实类别，否则从另外两类中任选一个
+func sampleClassificationTF(pools *core.ClassificationPools, rng *rand.Rand) (string, bool, error) {
+	if rng == nil {
+		return "", false, fmt.Errorf("service: rng must not be nil")
+	}
+	if pools == nil || pools.Formula == nil {
+		return "", false, fmt.Errorf("service: nil classification pools")
+	}
+
+	if rng.IntN(2) == 0 {
+		return classPhrases[pools.Class], true, nil
+	}
+	others := make([]string, 0, 2)
+	for _, class := range []string{core.ClassTautology, core.ClassContradiction, core.ClassContingent} {
+		if class != pools.Class {
+			others = append(others, class)
+		}
+	}
+	return classPhrases[others[rng.IntN(len(others))]], false, nil
+}
+
+// sampleProofTF 一半概率给出正确的下一步，否则从错误的写法中任选一个
+func sampleProofTF(pools *core.ProofPools, rng *rand.Rand) (string, bool, error) {
+	if rng == nil {
+		return "", false, fmt.Errorf("service: rng must not be nil")
+	}
+	if pools == nil || len(pools.CorrectSteps) == 0 {
+		return "", false, fmt.Errorf("service: nil proof pools")
+	}
+
+	if len(pools.WrongSteps) == 0 || rng.IntN(2) == 0 {
+		return pools.CorrectSteps[rng.IntN(len(pools.CorrectSteps))], true, nil
+	}
+	return pools.WrongSteps[rng.IntN(len(pools.WrongSteps))], false, nil
+}
+
+// sampleResolutionTF 一半概率给出正确的消解结果，否则从干扰项中任选一个
+func sampleResolutionTF(pools *core.ResolutionPools, rng *rand.Rand) (string, bool, error) {
+	if rng == nil {
+		return "", false, fmt.Errorf("service: rng must not be nil")
+	}
+	if pools == nil || pools.Resolvent == "" {
+		return "", false, fmt.Errorf("service: nil resolution pools")
+	}
+
+	if len(pools.ResolventDistractors) == 0 || rng.IntN(2) == 0 {
+		return pools.Resolvent, true, nil
+	}
+	return pools.ResolventDistractors[rng.IntN(len(pools.ResolventDistractors))], false, nil
+}
+
+// sampleTableauTF 一半概率给出表格关闭的结论，否则给出表格有开放分支的结论
+func sampleTableauTF(pools *core.TableauPools, rng *rand.Rand) (string, bool, error) {
+	if rng == nil {
+		return "", false, fmt.Errorf("service: rng must not be nil")
+	}
+	if pools == nil || len(pools.ClosingConclusions)+len(pools.OpenConclusions) == 0 {
+		return "", false, fmt.Errorf("service: nil tableau pools")
+	}
+
+	if len(pools.OpenConclusions) == 0 || (len(pools.ClosingConclusions) > 0 && rng.IntN(2) == 0) {
+		return pools.ClosingConclusions[rng.IntN(len(pools.ClosingConclusions))], true, nil
+	}
+	return pools.OpenConclusions[rng.IntN(len(pools.OpenConclusions))], false, nil
+}
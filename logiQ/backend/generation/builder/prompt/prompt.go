@@ -12,7 +12,8 @@ import (
 )
 
 type Prompt struct {
-	Text string
+	Text          string
+	TemplateIndex int // 所用模板在该题型模板列表中的下标
 }
 
 type Builder struct{}
@@ -62,5 +63,5 @@ func (Builder) BuildPrompt(params Params, rng *rand.Rand) (Prompt, error) {
 		text = strings.ReplaceAll(text, placeholder, params.Data[key])
 	}
 
-	return Prompt{Text: text}, nil
+	return Prompt{Text: text, TemplateIndex: idx}, nil
 }
@@ -3,6 +3,8 @@ package config
 import (
 	"backend/generation/core"
 	"backend/models"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"os"
 	"path/filepath"
@@ -17,21 +19,52 @@ type AppConfig struct {
 	Intents            map[string]IntentSpec                           `yaml:"intents"`
 	Inference          InferenceConfig                                 `yaml:"inference"`
 	Equivalence        EquivalenceConfig                               `yaml:"equivalence"`
+	NormalForm         NormalFormConfig                                `yaml:"normal_form"`
+	Validator          ValidatorConfig                                 `yaml:"validator"`
+	// Version 是配置文件内容的哈希，记录在题目的 blueprint 中，用于判断重新生成时配置是否变化
+	Version string `yaml:"-"`
 }
 
+// configFiles 参与生成的配置文件，顺序固定以保证 Version 稳定
+var configFiles = []string{"config.yaml", "inference.yaml", "equivalence.yaml", "normalform.yaml"}
+
 var OpNameToKind = map[string]core.NodeKind{
-	"NOT": core.Not,
-	"AND": core.And,
-	"OR":  core.Or,
-	"IMP": core.Impl,
-	"IFF": core.Iff,
-	"VAR": core.Var, // Added VAR for inf config parsing
+	"NOT":   core.Not,
+	"AND":   core.And,
+	"OR":    core.Or,
+	"IMP":   core.Impl,
+	"IFF":   core.Iff,
+	"XOR":   core.Xor,
+	"NAND":  core.Nand,
+	"NOR":   core.Nor,
+	"TRUE":  core.True,
+	"FALSE": core.False,
+	"VAR":   core.Var, // Added VAR for inf config parsing
+}
+
+// OpName 返回运算符在配置文件中的名字，是 OpNameToKind 的反查
+func OpName(kind core.NodeKind) string {
+	for name, k := range OpNameToKind {
+		if k == kind {
+			return name
+		}
+	}
+	return ""
 }
 
 type plannerConfig struct {
 	DifficultyProfiles map[models.QuestionDifficulty]DifficultyProfile `yaml:"difficulty_profiles"`
 	Planner            PlannerWeights                                  `yaml:"planner"`
 	Intents            map[string]IntentSpec                           `yaml:"intents"`
+	Validator          ValidatorConfig                                 `yaml:"validator"`
+}
+
+// ValidatorConfig 选择等价 / 可推出判定使用的实现
+type ValidatorConfig struct {
+	// Engine: truth_table | bitset | sat | hybrid，留空时为 bitset
+	Engine string `yaml:"engine"`
+	// SATMinVars hybrid 模式下变量数达到该值时改用 SAT 求解器
+	SATMinVars int `yaml:"sat_min_vars,omitempty"`
 }
 
 type inferenceFile struct {
@@ -42,6 +75,10 @@ type equivalenceFile struct {
 	Equivalence EquivalenceConfig `yaml:"equivalence"`
 }
 
+type normalFormFile struct {
+	NormalForm NormalFormConfig `yaml:"normal_form"`
+}
+
 func LoadConfig() (AppConfig, error) {
 	_, filename, _, _ := runtime.Caller(0)
 	baseDir := filepath.Dir(filename)
@@ -55,6 +92,7 @@ func LoadConfig() (AppConfig, error) {
 	cfg.DifficultyProfiles = planner.DifficultyProfiles
 	cfg.Planner = planner.Planner
 	cfg.Intents = planner.Intents
+	cfg.Validator = planner.Validator
 
 	var inf inferenceFile
 	if err := loadYAML(filepath.Join(baseDir, "inference.yaml"), &inf); err != nil {
@@ -74,9 +112,38 @@ func LoadConfig() (AppConfig, error) {
 		cfg.Equivalence = eq.Equivalence
 	}
 
+	var nf normalFormFile
+	if err := loadYAML(filepath.Join(baseDir, "normalform.yaml"), &nf); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return AppConfig{}, err
+		}
+	} else {
+		cfg.NormalForm = nf.NormalForm
+	}
+
+	version, err := hashFiles(baseDir, configFiles)
+	if err != nil {
+		return AppConfig{}, err
+	}
+	cfg.Version = version
+
 	return cfg, nil
 }
 
+// hashFiles 计算配置文件内容的 sha256，取前 12 位作为版本号；缺失的文件视为空
+func hashFiles(baseDir string, names []string) (string, error) {
+	h := sha256.New()
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(baseDir, name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		h.Write([]byte(name))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
 func loadYAML(path string, out any) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
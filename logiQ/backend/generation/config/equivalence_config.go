@@ -14,6 +14,12 @@ type EquivalenceRulesCatalog struct {
 
 type EquivalenceDifficultyConfig struct {
 	ChainStepsDist map[int]float64 `yaml:"chain_steps_dist"`
+	// 干扰项距离目标公式的最大变换步数，0 表示不限制
+	MaxDistractorSteps int `yaml:"max_distractor_steps,omitempty"`
+	// 候选公式字符串的最大长度（字节），0 表示使用默认的 MAX_EXPR_LENGTH；变量较多的公式需要放宽
+	MaxExprLength int `yaml:"max_expr_length,omitempty"`
+	// 覆盖 difficulty_profiles 中的变量数 / 深度分布；变量较多时应配合 validator.engine: sat 或 hybrid 使用
+	ProfileOverride `yaml:",inline"`
 }
 
 type EquivalenceConfig struct {
@@ -0,0 +1,23 @@
+package config
+
+import "backend/models"
+
+// ProfileOverride 按类别覆盖 difficulty_profiles 中的变量数 / 深度分布和运算符，留空则沿用
+type ProfileOverride struct {
+	VarsDist   map[int]float64 `yaml:"vars_dist,omitempty"`
+	DepthDist  map[int]float64 `yaml:"depth_dist,omitempty"`
+	AllowedOps []string        `yaml:"allowed_ops,omitempty"`
+	// 公式至少用到的不同变量数（不超过抽到的变量数），0 表示至少两个
+	MinVars int `yaml:"min_vars,omitempty"`
+}
+
+type NormalFormDifficultyConfig struct {
+	ProfileOverride `yaml:",inline"`
+	// 正确答案（最简 CNF / DNF）的规模上限，超过则重新生成公式
+	MaxClauses  int `yaml:"max_clauses"`
+	MaxLiterals int `yaml:"max_literals"`
+}
+
+type NormalFormConfig struct {
+	Difficulty map[models.QuestionDifficulty]NormalFormDifficultyConfig `yaml:"difficulty"`
+}
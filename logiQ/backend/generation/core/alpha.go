@@ -0,0 +1,143 @@
+package core
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// CanonicalVarNames are the names canonical renaming assigns, in order: p to
+// z, then a to o.
+var CanonicalVarNames = [...]string{
+	"p", "q", "r", "s", "t", "u", "v", "w", "x", "y", "z",
+	"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o",
+}
+
+// Vars returns the distinct variables of the formulas in order of first
+// occurrence, reading each formula left to right.
+func Vars(nodes ...*Node) []string {
+	seen := make(map[string]bool)
+	var vars []string
+	var walk func(*Node)
+	walk = func(n *Node) {
+		if n == nil {
+			return
+		}
+		if n.Kind == Var && !seen[n.Name] {
+			seen[n.Name] = true
+			vars = append(vars, n.Name)
+		}
+		walk(n.Left)
+		walk(n.Right)
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return vars
+}
+
+// Rename returns a copy of n with the variables in mapping replaced
+// simultaneously, so a mapping may swap names. Unmapped variables are kept.
+func Rename(n *Node, mapping map[string]string) *Node {
+	if n == nil {
+		return nil
+	}
+	out := &Node{Kind: n.Kind, Name: n.Name}
+	if n.Kind == Var {
+		if name, ok := mapping[n.Name]; ok {
+			out.Name = name
+		}
+	}
+	out.Left = Rename(n.Left, mapping)
+	out.Right = Rename(n.Right, mapping)
+	return out
+}
+
+// RenameAll applies Rename to every formula.
+func RenameAll(nodes []*Node, mapping map[string]string) []*Node {
+	out := make([]*Node, len(nodes))
+	for i, n := range nodes {
+		out[i] = Rename(n, mapping)
+	}
+	return out
+}
+
+// CanonicalRenaming maps the variables of the formulas, in order of first
+// occurrence, to CanonicalVarNames. Variables beyond the available names
+// keep their own.
+func CanonicalRenaming(nodes ...*Node) map[string]string {
+	vars := Vars(nodes...)
+	mapping := make(map[string]string, len(vars))
+	for i, v := range vars {
+		if i < len(CanonicalVarNames) {
+			mapping[v] = CanonicalVarNames[i]
+		}
+	}
+	return mapping
+}
+
+// Canonicalize renames the formulas jointly by first occurrence, e.g.
+// "r → s, s" becomes "p → q, q". Two lists are alpha-equivalent exactly when
+// their canonical forms are equal.
+func Canonicalize(nodes ...*Node) []*Node {
+	return RenameAll(nodes, CanonicalRenaming(nodes...))
+}
+
+// AlphaEquivalent reports whether a and b are the same formula up to a
+// one-to-one renaming of variables. Operand order matters: p ∧ q and q ∧ p
+// are alpha-equivalent, (p ∧ q) → p and (q ∧ p) → p are not.
+func AlphaEquivalent(a, b *Node) bool {
+	return AlphaEquivalentAll([]*Node{a}, []*Node{b})
+}
+
+// AlphaEquivalentAll is AlphaEquivalent for lists, with one renaming shared
+// by all formulas: "p → q, p" matches "r → s, r" but not "r → s, s".
+func AlphaEquivalentAll(a, b []*Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	forward := make(map[string]string)
+	backward := make(map[string]string)
+	for i := range a {
+		if !alphaMatch(a[i], b[i], forward, backward) {
+			return false
+		}
+	}
+	return true
+}
+
+func alphaMatch(a, b *Node, forward, backward map[string]string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind {
+		return false
+	}
+	if a.Kind == Var {
+		// 双向映射保证改名是一一对应的
+		if to, ok := forward[a.Name]; ok {
+			return to == b.Name
+		}
+		if _, ok := backward[b.Name]; ok {
+			return false
+		}
+		forward[a.Name], backward[b.Name] = b.Name, a.Name
+		return true
+	}
+	return alphaMatch(a.Left, b.Left, forward, backward) && alphaMatch(a.Right, b.Right, forward, backward)
+}
+
+// RandomRenaming maps vars to distinct names drawn uniformly from pool. The
+// pool may contain the variables themselves; apply the result with Rename,
+// which renames simultaneously.
+func RandomRenaming(rng *rand.Rand, vars, pool []string) (map[string]string, error) {
+	if len(pool) < len(vars) {
+		return nil, fmt.Errorf("core: cannot rename %d variables with %d names", len(vars), len(pool))
+	}
+	names := append([]string(nil), pool...)
+	rng.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+	mapping := make(map[string]string, len(vars))
+	for i, v := range vars {
+		mapping[v] = names[i]
+	}
+	return mapping, nil
+}
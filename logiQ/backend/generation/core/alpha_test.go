@@ -0,0 +1,88 @@
+package core
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+func v(name string) *Node { return &Node{Kind: Var, Name: name} }
+
+func bin(kind NodeKind, left, right *Node) *Node { return &Node{Kind: kind, Left: left, Right: right} }
+
+func TestCanonicalize(t *testing.T) {
+	// r → s, s ∧ ¬t, t
+	nodes := []*Node{bin(Impl, v("r"), v("s")), bin(And, v("s"), &Node{Kind: Not, Left: v("t")}), v("t")}
+	if got := Vars(nodes...); !reflect.DeepEqual(got, []string{"r", "s", "t"}) {
+		t.Fatalf("Vars = %v", got)
+	}
+	want := []*Node{bin(Impl, v("p"), v("q")), bin(And, v("q"), &Node{Kind: Not, Left: v("r")}), v("r")}
+	if got := Canonicalize(nodes...); !reflect.DeepEqual(got, want) {
+		t.Errorf("Canonicalize = %v, want %v", got, want)
+	}
+	if nodes[0].Left.Name != "r" {
+		t.Error("Canonicalize modified its input")
+	}
+
+	// 交换名字：同时改名，不会串改
+	swapped := Rename(bin(Or, v("p"), v("q")), map[string]string{"p": "q", "q": "p"})
+	if !reflect.DeepEqual(swapped, bin(Or, v("q"), v("p"))) {
+		t.Errorf("swap = %v", swapped)
+	}
+}
+
+func TestAlphaEquivalent(t *testing.T) {
+	cases := []struct {
+		a, b *Node
+		want bool
+	}{
+		{bin(Impl, v("p"), v("q")), bin(Impl, v("x"), v("y")), true},
+		{bin(Impl, v("p"), v("q")), bin(Impl, v("q"), v("p")), true},
+		{bin(Impl, v("p"), v("p")), bin(Impl, v("x"), v("y")), false},
+		{bin(Impl, v("p"), v("q")), bin(Impl, v("x"), v("x")), false},
+		// 只比较结构，不考虑交换律
+		{bin(Impl, bin(And, v("p"), v("q")), v("p")), bin(Impl, bin(And, v("q"), v("p")), v("p")), false},
+		{bin(And, v("p"), &Node{Kind: True}), bin(And, v("q"), &Node{Kind: False}), false},
+		{bin(And, v("p"), v("q")), bin(Or, v("p"), v("q")), false},
+	}
+	for i, c := range cases {
+		if got := AlphaEquivalent(c.a, c.b); got != c.want {
+			t.Errorf("case %d: AlphaEquivalent = %v, want %v", i, got, c.want)
+		}
+	}
+
+	// 列表共享同一个改名
+	premises := []*Node{bin(Impl, v("p"), v("q")), v("p")}
+	if !AlphaEquivalentAll(premises, []*Node{bin(Impl, v("r"), v("s")), v("r")}) {
+		t.Error("p → q, p should match r → s, r")
+	}
+	if AlphaEquivalentAll(premises, []*Node{bin(Impl, v("r"), v("s")), v("s")}) {
+		t.Error("p → q, p should not match r → s, s")
+	}
+}
+
+func TestRandomRenaming(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	node := bin(Iff, bin(Xor, v("p"), v("q")), &Node{Kind: Not, Left: v("r")})
+	pool := []string{"p", "q", "r", "s", "t"}
+	changed := false
+	for i := 0; i < 20; i++ {
+		mapping, err := RandomRenaming(rng, Vars(node), pool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		renamed := Rename(node, mapping)
+		if !AlphaEquivalent(node, renamed) {
+			t.Fatalf("renaming %v is not one-to-one", mapping)
+		}
+		if !reflect.DeepEqual(renamed, node) {
+			changed = true
+		}
+	}
+	if !changed {
+		t.Error("random renaming never changed the formula")
+	}
+	if _, err := RandomRenaming(rng, Vars(node), pool[:2]); err == nil {
+		t.Error("expected an error for a pool that is too small")
+	}
+}
@@ -12,6 +12,13 @@ const (
 	Or
 	Impl
 	Iff
+	// Xor, Nand and Nor are binary like And / Or; True and False are
+	// constants (⊤ / ⊥) without children.
+	Xor
+	Nand
+	Nor
+	True
+	False
 )
 
 // Node represents a propositional formula AST node.
@@ -35,35 +42,195 @@ type EquivalencePools struct {
 	Vars         []string
 	EquivPool    []string
 	NonEquivPool []string
+	// Derivations maps every pooled candidate to the rewrite steps that
+	// produced it from Target, in application order.
+	Derivations map[string][]DerivationStep
+	// Counterexamples maps every non-equivalent candidate to an assignment
+	// (rendered like "p=T, q=F") under which it differs from Target.
+	Counterexamples map[string]string
+}
+
+// DerivationStep records a single rule application: which rule fired, where
+// (Path is a string of 'L'/'R' moves from the root, empty for the root
+// itself) and the rewritten subterm before and after.
+type DerivationStep struct {
+	Rule   string
+	Path   string
+	Before string
+	After  string
 }
 
 // InferencePools holds inference question candidates.
 type InferencePools struct {
+	TemplateName       string
 	Premises           string
 	Vars               []string
 	ValidConclusions   []string
 	InvalidConclusions []string
+	// Counterexamples maps invalid conclusions to an assignment under which
+	// every premise holds and the conclusion is false.
+	Counterexamples map[string]string
+	// OpenBranches maps invalid conclusions to an open branch, such as
+	// "p, ¬q", of the tableau for the premises and the negated conclusion.
+	// The counterexample recorded for such a conclusion is that branch's
+	// assignment.
+	OpenBranches map[string]string
+
+	// INF_COUNTEREXAMPLE only: the invalid conclusion shown in the stem, the
+	// assignments refuting it and the remaining assignments as distractors.
+	CounterexampleConclusion string
+	CounterexampleSet        []string
+	NonCounterexampleSet     []string
+
+	// TemplatePremises / TemplateValid are the instantiated template before
+	// equivalence transformations; proof exercises are posed on them, since
+	// every template conclusion follows by the basic inference rules.
+	TemplatePremises []*Node
+	TemplateValid    []*Node
+}
+
+// NormalFormPools holds normal form conversion candidates. The correct pools
+// contain CNFs / DNFs equivalent to Formula; the distractor pools contain
+// near-miss conversions in the same shape that are not equivalent.
+type NormalFormPools struct {
+	Formula        *Node
+	Vars           []string
+	CNFPool        []string
+	CNFDistractors []string
+	DNFPool        []string
+	DNFDistractors []string
+	// CNFNotes / DNFNotes map every pooled candidate to how it was obtained
+	// (for correct candidates) or which mistake produced it (for distractors).
+	// They are kept apart because a string such as "¬p ∨ q" can be read as
+	// either form.
+	CNFNotes map[string]string
+	DNFNotes map[string]string
+	// Counterexamples maps every distractor to an assignment under which it
+	// differs from Formula.
+	Counterexamples map[string]string
+}
+
+// Semantic classes of a formula used by classification questions.
+const (
+	ClassTautology     = "tautology"
+	ClassContradiction = "contradiction"
+	ClassContingent    = "contingent"
+)
+
+// ClassificationPools holds formulas grouped by their semantic class. Every
+// pooled formula has been classified by the validator, so a formula built
+// from a tautology schema that happens to be contingent lands in Contingents.
+type ClassificationPools struct {
+	// Formula and Class are the formula asked about in true/false questions.
+	Formula        *Node
+	Class          string
+	Tautologies    []string
+	Contradictions []string
+	Contingents    []string
+	// Schemas maps formulas built from a schema (e.g. "A → (B → A)") to it.
+	Schemas map[string]string
+	// Satisfying / Falsifying map every satisfiable / non-tautological
+	// formula to an assignment that makes it true / false.
+	Satisfying map[string]string
+	Falsifying map[string]string
+}
+
+// ProofPools holds a natural-deduction proof built from an inference
+// template pair and the candidates for one of its derived lines.
+type ProofPools struct {
+	TemplateName string
+	Premises     string
+	// Listing renders the proof for the question stem: the asked line has its
+	// rule or formula replaced by "?", and true/false questions show only the
+	// lines before it.
+	Listing string
+	Line    int
+	// Rule and Formula are the justification and formula on Line.
+	Rule               string
+	RuleDistractors    []string
+	Formula            string
+	FormulaDistractors []string
+	// CorrectSteps / WrongSteps are renderings of Line such as
+	// "3. q (Modus Ponens, 1, 2)"; the wrong ones use a distractor rule or
+	// formula.
+	CorrectSteps []string
+	WrongSteps   []string
+	// Notes maps every distractor and wrong step to the checker's reason for
+	// rejecting it.
+	Notes map[string]string
+}
+
+// ResolutionPools holds the clause form of an inference template instance, a
+// resolution refutation of it and the candidates for resolution questions.
+// Clauses and clause sets use set notation, e.g. "{p, ¬q}, {q}".
+type ResolutionPools struct {
+	TemplateName string
+	Premises     string
+	Conclusion   string
+	// Clauses is the clause set of Premises and the negated Conclusion;
+	// Refutation lists the steps deriving the empty clause from it.
+	Clauses    string
+	Refutation []string
+	// Left and Right are the clauses of one refutation step, resolved on
+	// Pivot to Resolvent.
+	Left                 string
+	Right                string
+	Pivot                string
+	Resolvent            string
+	ResolventDistractors []string
+	// UnsatSets / SatSets are clause sets whose unsatisfiability has been
+	// established by a refutation / refuted by a satisfying assignment.
+	UnsatSets []string
+	SatSets   []string
+	// ResolventNotes maps every resolvent distractor to the mistake that
+	// produced it. SetNotes maps every unsatisfiable set to its refutation and
+	// every satisfiable set to an assignment satisfying it. They are kept
+	// apart because "{p, q}" reads both as a clause and as a one-clause set.
+	ResolventNotes map[string]string
+	SetNotes       map[string]string
+}
+
+// TableauPools holds analytic tableaux built from an inference template
+// instance. Branches are rendered by their literals, e.g. "p, ¬q".
+type TableauPools struct {
+	TemplateName string
+	Premises     string
+	// Conclusion is an invalid conclusion of the template; Formulas lists
+	// Premises and its negation, the root of the tableau asked about.
+	Conclusion string
+	Formulas   string
+	// Branches is the number of branches of that tableau; OpenBranches are
+	// its open ones, BranchDistractors its closed ones and literal sets that
+	// are no branch at all.
+	Branches          int
+	OpenBranches      []string
+	BranchDistractors []string
+	// ClosingConclusions / OpenConclusions are the conclusions for which the
+	// tableau of the premises and the negated conclusion closes / stays open.
+	ClosingConclusions []string
+	OpenConclusions    []string
+	// BranchNotes maps every branch option to why it is or is not open.
+	// ConclusionNotes maps every conclusion to how its tableau closes or to
+	// the counterexample read off an open branch.
+	BranchNotes     map[string]string
+	ConclusionNotes map[string]string
 }
 
 // CandidatePools aggregates category-specific pools.
 type CandidatePools struct {
-	TruthTable  *TruthTablePools
-	Equivalence *EquivalencePools
-	Inference   *InferencePools
+	TruthTable     *TruthTablePools
+	Equivalence    *EquivalencePools
+	Inference      *InferencePools
+	NormalForm     *NormalFormPools
+	Classification *ClassificationPools
+	Proof          *ProofPools
+	Resolution     *ResolutionPools
+	Tableau        *TableauPools
 }
 
-// Blueprint captures metadata for regenerating a question.
-type Blueprint struct {
-	Seed           int64
-	Difficulty     models.QuestionDifficulty
-	Category       models.QuestionCategory
-	QType          models.QuestionType
-	Intent         string
-	MCCorrectCount int
-	ProfileSample  map[string]any
-	TemplateID     string
-	PoolsSummary   map[string]any
-}
+// Blueprint captures metadata for regenerating a question. It is persisted
+// on models.Question, hence the alias.
+type Blueprint = models.QuestionBlueprint
 
 // Clone returns a deep copy of the node (safe for nil receivers).
 func (n *Node) Clone() *Node {
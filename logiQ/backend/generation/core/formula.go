@@ -0,0 +1,79 @@
+package core
+
+import (
+	"backend/models"
+	"fmt"
+)
+
+// kindNames 与配置文件 allowed_ops 中的运算符名字一致
+var kindNames = map[NodeKind]string{
+	Var:   "VAR",
+	Not:   "NOT",
+	And:   "AND",
+	Or:    "OR",
+	Impl:  "IMP",
+	Iff:   "IFF",
+	Xor:   "XOR",
+	Nand:  "NAND",
+	Nor:   "NOR",
+	True:  "TRUE",
+	False: "FALSE",
+}
+
+// EncodeFormula converts an AST into its persisted form (nil for nil).
+func EncodeFormula(n *Node) *models.FormulaNode {
+	if n == nil {
+		return nil
+	}
+	return &models.FormulaNode{
+		Op:    kindNames[n.Kind],
+		Name:  n.Name,
+		Left:  EncodeFormula(n.Left),
+		Right: EncodeFormula(n.Right),
+	}
+}
+
+// DecodeFormula rebuilds an AST from its persisted form, checking that every
+// operator is known and has the right number of children.
+func DecodeFormula(f *models.FormulaNode) (*Node, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var kind NodeKind
+	found := false
+	for k, name := range kindNames {
+		if name == f.Op {
+			kind, found = k, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("core: unknown operator %q", f.Op)
+	}
+
+	children := 0
+	switch kind {
+	case Var:
+		if f.Name == "" {
+			return nil, fmt.Errorf("core: variable without a name")
+		}
+	case Not:
+		children = 1
+	case True, False:
+	default:
+		children = 2
+	}
+	if (f.Left != nil) != (children >= 1) || (f.Right != nil) != (children == 2) {
+		return nil, fmt.Errorf("core: operator %s expects %d operands", f.Op, children)
+	}
+
+	left, err := DecodeFormula(f.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := DecodeFormula(f.Right)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Kind: kind, Name: f.Name, Left: left, Right: right}, nil
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"backend/models"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFormulaRoundTrip(t *testing.T) {
+	p := &Node{Kind: Var, Name: "p"}
+	q := &Node{Kind: Var, Name: "q"}
+	node := &Node{Kind: Impl,
+		Left:  &Node{Kind: Not, Left: &Node{Kind: Xor, Left: p, Right: q}},
+		Right: &Node{Kind: Nand, Left: &Node{Kind: True}, Right: &Node{Kind: Nor, Left: q, Right: &Node{Kind: False}}},
+	}
+	encoded := EncodeFormula(node)
+
+	raw, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var fromJSON models.FormulaNode
+	if err := json.Unmarshal(raw, &fromJSON); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	doc, err := bson.Marshal(encoded)
+	if err != nil {
+		t.Fatalf("bson: %v", err)
+	}
+	var fromBSON models.FormulaNode
+	if err := bson.Unmarshal(doc, &fromBSON); err != nil {
+		t.Fatalf("bson: %v", err)
+	}
+
+	for name, f := range map[string]*models.FormulaNode{"json": &fromJSON, "bson": &fromBSON} {
+		decoded, err := DecodeFormula(f)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		if !reflect.DeepEqual(decoded, node) {
+			t.Errorf("%s: decoded %+v, want %+v", name, decoded, node)
+		}
+	}
+}
+
+func TestDecodeFormulaErrors(t *testing.T) {
+	p := &models.FormulaNode{Op: "VAR", Name: "p"}
+	cases := []*models.FormulaNode{
+		{Op: "IMPLIES", Left: p, Right: p},
+		{Op: "VAR"},
+		{Op: "NOT"},
+		{Op: "NOT", Left: p, Right: p},
+		{Op: "AND", Left: p},
+		{Op: "TRUE", Left: p},
+		{Op: "OR", Left: p, Right: &models.FormulaNode{Op: "NOT"}},
+	}
+	for _, f := range cases {
+		if _, err := DecodeFormula(f); err == nil {
+			t.Errorf("decode %+v: expected an error", f)
+		}
+	}
+}
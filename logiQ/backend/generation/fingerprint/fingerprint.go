@@ -0,0 +1,401 @@
+// Package fingerprint reduces a generated question to a key that identifies
+// it up to surface differences: variable letters (alpha-renaming), the order
+// of commutative operands, the order of premises, literals and clauses, and
+// the order of the options. Two questions with the same fingerprint ask the
+// same thing, so the bank keeps only one of them.
+//
+// The key is computed from the structured formulas of the question
+// (models.QuestionFormulas), not from its text, so the notation a question is
+// rendered in does not matter. Questions without structured formulas, such
+// as those entered by hand, are fingerprinted by their text with OfText.
+package fingerprint
+
+import (
+	"backend/generation/core"
+	"backend/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Question is the content of a generated question that the fingerprint
+// covers.
+type Question struct {
+	Intent   string
+	Type     models.QuestionType
+	Formulas *models.QuestionFormulas
+	// Options are the option texts. Only options without a structured form in
+	// Formulas, such as rule names or True / False, are read from them.
+	Options []string
+}
+
+// Of returns the fingerprint of q: the hex SHA-256 of Canonical(q).
+func Of(q Question) (string, error) {
+	canonical, err := Canonical(q)
+	if err != nil {
+		return "", err
+	}
+	return hash(canonical), nil
+}
+
+// OfText returns the fingerprint of a question known only by its text. The
+// text is compared as written, apart from white space and the order of the
+// options; the "text" prefix keeps it apart from fingerprints computed by Of.
+func OfText(text string, options []string) string {
+	sorted := make([]string, len(options))
+	for i, o := range options {
+		sorted[i] = strings.Join(strings.Fields(o), " ")
+	}
+	sort.Strings(sorted)
+	return hash("text\n" + strings.Join(strings.Fields(text), " ") + "\n" + strings.Join(sorted, "\n"))
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Canonical returns the normalised description of q that Of hashes. It fails
+// if a stored formula cannot be decoded.
+func Canonical(q Question) (string, error) {
+	keys, stem, err := stemItems(q.Formulas)
+	if err != nil {
+		return "", err
+	}
+	n := len(q.Options)
+	if q.Formulas != nil {
+		n = max(n, len(q.Formulas.Options), len(q.Formulas.OptionSets))
+	}
+	options := make([]item, n)
+	for i := range options {
+		var text string
+		if i < len(q.Options) {
+			text = q.Options[i]
+		}
+		if options[i], err = optionItem(q.Formulas, i, text); err != nil {
+			return "", err
+		}
+	}
+	head := q.Intent + "\n" + string(q.Type) + "\n"
+	return head + describe(keys, stem, options, canonicalNames(keys, stem, options)), nil
+}
+
+// stemItems 按固定顺序列出题干中的公式、子句、赋值和证明，没有的项不列出
+func stemItems(f *models.QuestionFormulas) ([]string, []item, error) {
+	if f == nil {
+		return nil, nil, nil
+	}
+	var (
+		keys  []string
+		items []item
+	)
+	add := func(key string, it item, err error) error {
+		if err != nil {
+			return fmt.Errorf("fingerprint: %s: %w", key, err)
+		}
+		keys = append(keys, key)
+		items = append(items, it)
+		return nil
+	}
+	if f.Target != nil {
+		if err := add(formulaOf("target", f.Target)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if f.Candidate != nil {
+		if err := add(formulaOf("candidate", f.Candidate)); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, set := range []struct {
+		key   string
+		kind  itemKind
+		nodes []*models.FormulaNode
+	}{
+		{"premises", setItem, f.Premises},
+		{"clauses", clauseSetItem, f.Clauses},
+		{"clause_set", clauseSetItem, f.ClauseSet},
+		{"assignment", clauseSetItem, f.Assignment},
+	} {
+		if len(set.nodes) == 0 {
+			continue
+		}
+		it, err := setOf(set.kind, set.nodes)
+		if err := add(set.key, it, err); err != nil {
+			return nil, nil, err
+		}
+	}
+	for i, line := range f.Proof {
+		formula, err := core.DecodeFormula(line.Formula)
+		it := item{kind: lineItem, text: line.Rule + " " + fmt.Sprint(line.Cites)}
+		if formula != nil {
+			it.nodes = []*core.Node{formula}
+		}
+		if err := add("line"+strconv.Itoa(i+1), it, err); err != nil {
+			return nil, nil, err
+		}
+	}
+	if f.Line != 0 {
+		keys, items = append(keys, "asked"), append(items, item{kind: textItem, text: strconv.Itoa(f.Line)})
+	}
+	if f.Label != "" {
+		keys, items = append(keys, "label"), append(items, item{kind: textItem, text: f.Label})
+	}
+	return keys, items, nil
+}
+
+// optionItem 第 i 个选项：公式、集合，或者不含变量的文本
+func optionItem(f *models.QuestionFormulas, i int, text string) (item, error) {
+	if f != nil && i < len(f.Options) && f.Options[i] != nil {
+		_, it, err := formulaOf("option", f.Options[i])
+		return it, err
+	}
+	if f != nil && i < len(f.OptionSets) && f.OptionSets[i] != nil {
+		it, err := setOf(clauseSetItem, f.OptionSets[i])
+		if err != nil {
+			return item{}, fmt.Errorf("fingerprint: option %d: %w", i+1, err)
+		}
+		return it, nil
+	}
+	return item{kind: textItem, text: strings.TrimSpace(text)}, nil
+}
+
+func formulaOf(key string, f *models.FormulaNode) (string, item, error) {
+	node, err := core.DecodeFormula(f)
+	return key, item{kind: formulaItem, nodes: []*core.Node{node}}, err
+}
+
+func setOf(kind itemKind, formulas []*models.FormulaNode) (item, error) {
+	it := item{kind: kind}
+	for _, f := range formulas {
+		node, err := core.DecodeFormula(f)
+		if err != nil {
+			return item{}, err
+		}
+		if kind == clauseSetItem {
+			it.clauses = append(it.clauses, literals(node))
+		} else {
+			it.nodes = append(it.nodes, node)
+		}
+	}
+	return it, nil
+}
+
+// literals 把写成文字析取的子句拆成文字，⊥ 为空子句；赋值和分支中的文字各自成为单文字子句
+func literals(clause *core.Node) []*core.Node {
+	switch {
+	case clause == nil || clause.Kind == core.False:
+		return nil
+	case clause.Kind == core.Or:
+		return append(literals(clause.Left), literals(clause.Right)...)
+	default:
+		return []*core.Node{clause}
+	}
+}
+
+// describe 按 names 重命名变量后写出题干各项与选项集合
+func describe(keys []string, stem, options []item, names map[string]string) string {
+	var b strings.Builder
+	for i, k := range keys {
+		b.WriteString(k + "=" + stem[i].render(names) + "\n")
+	}
+	rendered := make([]string, len(options))
+	for i, it := range options {
+		rendered[i] = it.render(names)
+	}
+	sort.Strings(rendered)
+	b.WriteString("options=" + strings.Join(rendered, " | "))
+	return b.String()
+}
+
+// canonicalNames 给变量取与字母无关的名字
+// 每个变量的颜色由它在题目中出现的位置决定（把它写成 "*"、其他变量写成各自的颜色后的整道题），
+// 反复细化直到颜色不再分裂；仍有多个变量同色时固定其中一个再继续细化。
+// 同色的变量通常可以互换，固定哪一个结果都一样；极少数不能互换的情况只会漏判重复，不会误判
+func canonicalNames(keys []string, stem, options []item) map[string]string {
+	seen := make(map[string]bool)
+	var vars []string
+	for _, it := range append(append([]item(nil), stem...), options...) {
+		for _, v := range it.vars() {
+			if !seen[v] {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	sort.Strings(vars)
+
+	colour := make(map[string]string, len(vars))
+	for _, v := range vars {
+		colour[v] = "_"
+	}
+	classes := func() int {
+		distinct := make(map[string]bool)
+		for _, c := range colour {
+			distinct[c] = true
+		}
+		return len(distinct)
+	}
+	refine := func() {
+		for {
+			before := classes()
+			sigs := make(map[string]string, len(vars))
+			for _, v := range vars {
+				marked := make(map[string]string, len(vars))
+				for w, c := range colour {
+					marked[w] = c
+				}
+				marked[v] = "*"
+				sigs[v] = colour[v] + "\x00" + describe(keys, stem, options, marked)
+			}
+			colour = rank(sigs, "c")
+			if classes() == before {
+				return
+			}
+		}
+	}
+
+	refine()
+	for classes() < len(vars) {
+		// 固定最小的同色类中字典序最小的变量
+		var pick string
+		for _, v := range vars {
+			if sameColour(colour, v) && (pick == "" || colour[v] < colour[pick]) {
+				pick = v
+			}
+		}
+		colour[pick] += "!"
+		refine()
+	}
+	return rank(colour, "v")
+}
+
+// rank 把值替换成它在所有不同值中的名次，名字为 prefix 加从 1 开始的序号
+func rank(values map[string]string, prefix string) map[string]string {
+	distinct := make([]string, 0, len(values))
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			distinct = append(distinct, v)
+		}
+	}
+	sort.Strings(distinct)
+	index := make(map[string]string, len(distinct))
+	for i, v := range distinct {
+		index[v] = prefix + strconv.Itoa(i+1)
+	}
+	out := make(map[string]string, len(values))
+	for k, v := range values {
+		out[k] = index[v]
+	}
+	return out
+}
+
+func sameColour(colour map[string]string, v string) bool {
+	for w, c := range colour {
+		if w != v && c == colour[v] {
+			return true
+		}
+	}
+	return false
+}
+
+type itemKind int
+
+const (
+	textItem      itemKind = iota // 不含变量的文本：规则名、行号、判断题的选项、题干声称的类别
+	formulaItem                   // 单个公式
+	setItem                       // 公式集合：前提
+	clauseSetItem                 // 子句集合：子句集、被消解的子句、赋值和分支（文字各为一个子句）
+	lineItem                      // 证明的一行：公式（被隐去时没有）以及规则和引用的行号
+)
+
+// item 题干中的一项或一个选项
+type item struct {
+	kind    itemKind
+	text    string
+	nodes   []*core.Node   // formulaItem / lineItem 为一个公式，setItem 为集合中的元素
+	clauses [][]*core.Node // clauseSetItem
+}
+
+// vars 列出出现的变量，可能重复
+func (it item) vars() []string {
+	var out []string
+	switch it.kind {
+	case textItem:
+	case clauseSetItem:
+		for _, c := range it.clauses {
+			out = append(out, core.Vars(c...)...)
+		}
+	default:
+		out = core.Vars(it.nodes...)
+	}
+	return out
+}
+
+// render 按 names 重命名变量后写出，交换律运算对象与集合元素按重命名后的写法排序
+func (it item) render(names map[string]string) string {
+	switch it.kind {
+	case textItem:
+		return "text:" + it.text
+	case formulaItem:
+		return "formula:" + canonicalFormula(it.nodes[0], names)
+	case lineItem:
+		formula := "?"
+		if len(it.nodes) > 0 {
+			formula = canonicalFormula(it.nodes[0], names)
+		}
+		return "line:" + formula + " " + it.text
+	case clauseSetItem:
+		clauses := make([]string, len(it.clauses))
+		for i, c := range it.clauses {
+			clauses[i] = "{" + canonicalSet(c, names) + "}"
+		}
+		sort.Strings(clauses)
+		return "clauses:" + strings.Join(clauses, " ")
+	default:
+		return "set:" + canonicalSet(it.nodes, names)
+	}
+}
+
+// commutative 交换律成立的二元联结词
+var commutative = map[core.NodeKind]bool{
+	core.And: true, core.Or: true, core.Iff: true,
+	core.Xor: true, core.Nand: true, core.Nor: true,
+}
+
+var opSymbols = map[core.NodeKind]string{
+	core.Not: "¬", core.And: "∧", core.Or: "∨", core.Impl: "→", core.Iff: "↔",
+	core.Xor: "⊕", core.Nand: "↑", core.Nor: "↓", core.True: "⊤", core.False: "⊥",
+}
+
+// canonicalFormula 写成带括号的前缀形式，如 (∧ v1 (¬ v2))，交换律运算的两个子公式按写法排序
+// 自底向上一次算出，不必反复调用 Stringify 作为排序键
+func canonicalFormula(node *core.Node, names map[string]string) string {
+	switch node.Kind {
+	case core.Var:
+		return names[node.Name]
+	case core.True, core.False:
+		return opSymbols[node.Kind]
+	case core.Not:
+		return "(¬ " + canonicalFormula(node.Left, names) + ")"
+	}
+	left, right := canonicalFormula(node.Left, names), canonicalFormula(node.Right, names)
+	if commutative[node.Kind] && right < left {
+		left, right = right, left
+	}
+	return "(" + opSymbols[node.Kind] + " " + left + " " + right + ")"
+}
+
+func canonicalSet(nodes []*core.Node, names map[string]string) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = canonicalFormula(n, names)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,205 @@
+package fingerprint
+
+import (
+	"backend/generation/core"
+	"backend/generation/parser"
+	"backend/models"
+	"math/rand/v2"
+	"testing"
+)
+
+func encode(t *testing.T, s string) *models.FormulaNode {
+	t.Helper()
+	node, err := parser.Parse(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return core.EncodeFormula(node)
+}
+
+func encodeAll(t *testing.T, ss ...string) []*models.FormulaNode {
+	t.Helper()
+	out := make([]*models.FormulaNode, len(ss))
+	for i, s := range ss {
+		out[i] = encode(t, s)
+	}
+	return out
+}
+
+func mustOf(t *testing.T, q Question) string {
+	t.Helper()
+	fp, err := Of(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fp
+}
+
+func mustCanonical(t *testing.T, q Question) string {
+	t.Helper()
+	s, err := Canonical(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSameQuestionUpToSurface(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b Question
+	}{
+		{
+			name: "renaming and commutativity",
+			a: Question{Intent: "EQ_EQUIVALENT", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{Target: encode(t, "(p ∧ q) → r"),
+					Options: encodeAll(t, "¬r → ¬(p ∧ q)", "r → (p ∧ q)", "p ∨ r", "q")}},
+			b: Question{Intent: "EQ_EQUIVALENT", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{Target: encode(t, "(s ∧ r) → p"),
+					Options: encodeAll(t, "s ∨ p", "¬p → ¬(r ∧ s)", "r", "p → (s ∧ r)")}},
+		},
+		{
+			name: "premise order",
+			a: Question{Intent: "INF_DERIVABLE", Type: models.QuestionTypeMultipleChoice,
+				Formulas: &models.QuestionFormulas{Premises: encodeAll(t, "p → q", "q → r"),
+					Options: encodeAll(t, "p → r", "r → p")}},
+			b: Question{Intent: "INF_DERIVABLE", Type: models.QuestionTypeMultipleChoice,
+				Formulas: &models.QuestionFormulas{Premises: encodeAll(t, "s → p", "q → s"),
+					Options: encodeAll(t, "p → q", "q → p")}},
+		},
+		{
+			name: "assignments",
+			a: Question{Intent: "TT_TRUE_ASSIGNMENTS", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{Target: encode(t, "p ∧ ¬q"),
+					OptionSets: [][]*models.FormulaNode{encodeAll(t, "p", "¬q"), encodeAll(t, "¬p", "¬q"), encodeAll(t, "p", "q")}}},
+			b: Question{Intent: "TT_TRUE_ASSIGNMENTS", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{Target: encode(t, "¬p ∧ q"),
+					OptionSets: [][]*models.FormulaNode{encodeAll(t, "p", "q"), encodeAll(t, "¬p", "q"), encodeAll(t, "¬p", "¬q")}}},
+		},
+		{
+			name: "clause sets",
+			a: Question{Intent: "RES_UNSAT", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{
+					OptionSets: [][]*models.FormulaNode{encodeAll(t, "p ∨ ¬q", "q", "¬p"), encodeAll(t, "p", "q")}}},
+			b: Question{Intent: "RES_UNSAT", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{
+					OptionSets: [][]*models.FormulaNode{encodeAll(t, "r", "s"), encodeAll(t, "¬s", "s ∨ ¬r", "r")}}},
+		},
+		{
+			name: "proof listing",
+			a: Question{Intent: "PRF_RULE", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{Line: 3, Proof: []models.ProofLine{
+					{Formula: encode(t, "p → q"), Rule: "Premise"},
+					{Formula: encode(t, "p"), Rule: "Premise"},
+					{Formula: encode(t, "q"), Cites: []int{1, 2}},
+				}},
+				Options: []string{"Modus Ponens", "Modus Tollens"}},
+			b: Question{Intent: "PRF_RULE", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{Line: 3, Proof: []models.ProofLine{
+					{Formula: encode(t, "r → p"), Rule: "Premise"},
+					{Formula: encode(t, "r"), Rule: "Premise"},
+					{Formula: encode(t, "p"), Cites: []int{1, 2}},
+				}},
+				Options: []string{"Modus Tollens", "Modus Ponens"}},
+		},
+		{
+			// 选项文本的记法不参与指纹，只读结构化的公式
+			name: "notation",
+			a: Question{Intent: "CLS_TAUTOLOGY", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{Options: encodeAll(t, "p ∨ ¬p", "p ∧ q")},
+				Options:  []string{"p ∨ ¬p", "p ∧ q"}},
+			b: Question{Intent: "CLS_TAUTOLOGY", Type: models.QuestionTypeSingleChoice,
+				Formulas: &models.QuestionFormulas{Options: encodeAll(t, "p ∨ ¬p", "p ∧ q")},
+				Options:  []string{"p | ~p", "p & q"}},
+		},
+	}
+	for _, c := range cases {
+		if mustOf(t, c.a) != mustOf(t, c.b) {
+			t.Errorf("%s: fingerprints differ\n%s\n---\n%s", c.name, mustCanonical(t, c.a), mustCanonical(t, c.b))
+		}
+	}
+}
+
+func TestDifferentQuestions(t *testing.T) {
+	formulas := &models.QuestionFormulas{Options: encodeAll(t, "p ∨ ¬p", "p ∧ q", "p → q", "¬p")}
+	base := Question{Intent: "CLS_TAUTOLOGY", Type: models.QuestionTypeSingleChoice, Formulas: formulas}
+	evaluation := Question{Intent: "TT_EVAL_AT_ASSIGNMENT", Type: models.QuestionTypeTrueFalse,
+		Formulas: &models.QuestionFormulas{Target: encode(t, "p → q"), Assignment: encodeAll(t, "p", "¬q")},
+		Options:  []string{"True", "False"}}
+	variants := []Question{
+		{Intent: "CLS_CONTRADICTION", Type: base.Type, Formulas: formulas},
+		{Intent: base.Intent, Type: models.QuestionTypeMultipleChoice, Formulas: formulas},
+		{Intent: base.Intent, Type: base.Type, Formulas: &models.QuestionFormulas{Options: encodeAll(t, "p ∨ ¬p", "p ∧ q", "q → p", "¬p")}},
+		{Intent: base.Intent, Type: base.Type, Formulas: &models.QuestionFormulas{Options: encodeAll(t, "p ∨ ¬p", "p ∧ p", "p → q", "¬p")}},
+		evaluation,
+		// 赋值不同的求值题是不同的题目
+		{Intent: evaluation.Intent, Type: evaluation.Type,
+			Formulas: &models.QuestionFormulas{Target: encode(t, "p → q"), Assignment: encodeAll(t, "p", "q")},
+			Options:  evaluation.Options},
+		// 声称的类别不同
+		{Intent: "CLS_CLASS_TF", Type: models.QuestionTypeTrueFalse,
+			Formulas: &models.QuestionFormulas{Target: encode(t, "p ∨ ¬p"), Label: "a tautology"}, Options: evaluation.Options},
+		{Intent: "CLS_CLASS_TF", Type: models.QuestionTypeTrueFalse,
+			Formulas: &models.QuestionFormulas{Target: encode(t, "p ∨ ¬p"), Label: "a contradiction"}, Options: evaluation.Options},
+	}
+	seen := map[string]int{mustOf(t, base): -1}
+	for i, v := range variants {
+		fp := mustOf(t, v)
+		if j, ok := seen[fp]; ok {
+			t.Errorf("variant %d collides with %d:\n%s", i, j, mustCanonical(t, v))
+		}
+		seen[fp] = i
+	}
+}
+
+// TestRandomRenaming 随机改名后的推理题与原题指纹相同
+func TestRandomRenaming(t *testing.T) {
+	premises, err := parser.ParseList("¬r ∨ (p ∨ s), ¬¬r, (p ∨ s) → (t ∧ u)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conclusions, err := parser.ParseList("t ∧ u, ¬r, p ∨ s, ¬t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	question := func(mapping map[string]string) Question {
+		f := &models.QuestionFormulas{}
+		for _, p := range core.RenameAll(premises, mapping) {
+			f.Premises = append(f.Premises, core.EncodeFormula(p))
+		}
+		for _, o := range core.RenameAll(conclusions, mapping) {
+			f.Options = append(f.Options, core.EncodeFormula(o))
+		}
+		return Question{Intent: "INF_DERIVABLE", Type: models.QuestionTypeMultipleChoice, Formulas: f}
+	}
+
+	want := mustOf(t, question(nil))
+	rng := rand.New(rand.NewPCG(3, 4))
+	for i := 0; i < 20; i++ {
+		mapping, err := core.RandomRenaming(rng, core.Vars(premises...), []string{"p", "q", "r", "s", "t", "u", "w", "x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := question(mapping); mustOf(t, got) != want {
+			t.Errorf("renaming %v changes the fingerprint:\n%s", mapping, mustCanonical(t, got))
+		}
+	}
+}
+
+func TestUndecodableFormula(t *testing.T) {
+	q := Question{Intent: "CLS_TAUTOLOGY", Type: models.QuestionTypeSingleChoice,
+		Formulas: &models.QuestionFormulas{Target: &models.FormulaNode{Op: "MAYBE"}}}
+	if _, err := Of(q); err == nil {
+		t.Error("expected an error for an unknown operator")
+	}
+}
+
+func TestOfText(t *testing.T) {
+	a := OfText("Is  p → q a tautology?", []string{"True", "False"})
+	if b := OfText("Is p → q a tautology? ", []string{"False", "True"}); a != b {
+		t.Error("white space or option order changes the text fingerprint")
+	}
+	if b := OfText("Is q → p a tautology?", []string{"True", "False"}); a == b {
+		t.Error("different texts share a fingerprint")
+	}
+}
@@ -0,0 +1,215 @@
+package cls
+
+import (
+	"backend/generation/builder/choice"
+	"backend/generation/core"
+	"backend/generation/generator/shared"
+	"backend/generation/helper"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"math/rand/v2"
+)
+
+// perClass 每个类别收集的公式数，足够 MC 题的 3 个正确项
+const perClass = 4
+
+type ClassificationGenerator struct {
+	validator validator.Validator
+}
+
+func NewClassificationGenerator(v validator.Validator) ClassificationGenerator {
+	return ClassificationGenerator{validator: v}
+}
+
+// Generate 生成恒真 / 恒假 / 可满足分类题
+// 随机公式几乎都是非恒真非恒假的，因此恒真式和恒假式由模式（schema）配合随机子公式刻意构造，
+// 每个候选最终的类别都以 validator 的判定为准
+func (g ClassificationGenerator) Generate(rng *rand.Rand, prof sampler.Profile, plan sampler.Plan) (core.CandidatePools, map[string]any, error) {
+	if rng == nil {
+		return core.CandidatePools{}, nil, shared.ErrRngRequired
+	}
+	vars := shared.CanonicalVars(prof.Vars)
+	attempts := 0
+	for {
+		if attempts >= shared.MAX_ATTEMPTS {
+			return core.CandidatePools{}, nil, shared.ErrGenerationBudgetExceeded
+		}
+		attempts++
+
+		clsPools, nodes := g.buildPools(rng, prof, vars)
+		// 判断题从某一类中随机取一个公式
+		classes := make([]string, 0, 3)
+		for _, class := range []string{core.ClassTautology, core.ClassContradiction, core.ClassContingent} {
+			if len(poolOf(&clsPools, class)) > 0 {
+				classes = append(classes, class)
+			}
+		}
+		if len(classes) > 0 {
+			class := classes[rng.IntN(len(classes))]
+			pool := poolOf(&clsPools, class)
+			clsPools.Formula = nodes[pool[rng.IntN(len(pool))]]
+			clsPools.Class = class
+		}
+
+		if !g.isPlanFeasible(plan, clsPools) {
+			continue
+		}
+
+		pools := core.CandidatePools{Classification: &clsPools}
+		hints := map[string]any{
+			"tautologies":    len(clsPools.Tautologies),
+			"contradictions": len(clsPools.Contradictions),
+			"contingents":    len(clsPools.Contingents),
+		}
+		return pools, hints, nil
+	}
+}
+
+// buildPools 按类别轮流构造候选，直到每类都有 perClass 个或预算用尽
+// 返回的 map 把候选字符串映射回公式
+func (g ClassificationGenerator) buildPools(rng *rand.Rand, prof sampler.Profile, vars []string) (core.ClassificationPools, map[string]*core.Node) {
+	pools := core.ClassificationPools{
+		Schemas:    make(map[string]string),
+		Satisfying: make(map[string]string),
+		Falsifying: make(map[string]string),
+	}
+	nodes := make(map[string]*core.Node)
+
+	wanted := []string{core.ClassTautology, core.ClassContradiction, core.ClassContingent}
+	for try := 0; try < 4*perClass*len(wanted); try++ {
+		want := wanted[try%len(wanted)]
+		if len(poolOf(&pools, want)) >= perClass {
+			full := true
+			for _, class := range wanted {
+				full = full && len(poolOf(&pools, class)) >= perClass
+			}
+			if full {
+				break
+			}
+			continue
+		}
+
+		formula, schemaName := g.candidate(rng, prof, vars, want)
+		s := helper.Stringify(formula)
+		if _, dup := nodes[s]; dup || len(s) > shared.MAX_EXPR_LENGTH {
+			continue
+		}
+		used := shared.FilterVars(vars, formula)
+		class, satisfying, falsifying := g.classify(formula, used)
+		// 以实际类别为准；该类已满时丢弃
+		if len(poolOf(&pools, class)) >= perClass {
+			continue
+		}
+		nodes[s] = formula
+		addTo(&pools, class, s)
+		if schemaName != "" {
+			pools.Schemas[s] = schemaName
+		}
+		if satisfying != nil {
+			pools.Satisfying[s] = helper.AssignmentStringify(used, satisfying)
+		}
+		if falsifying != nil {
+			pools.Falsifying[s] = helper.AssignmentStringify(used, falsifying)
+		}
+	}
+	return pools, nodes
+}
+
+// candidate 构造一个期望属于 want 类的公式；非恒真非恒假的公式一半来自形似恒真式的错误模式，一半完全随机
+func (g ClassificationGenerator) candidate(rng *rand.Rand, prof sampler.Profile, vars []string, want string) (*core.Node, string) {
+	var schemas []schema
+	switch want {
+	case core.ClassTautology:
+		schemas = tautologySchemas
+	case core.ClassContradiction:
+		schemas = contradictionSchemas
+	default:
+		if rng.IntN(2) == 0 {
+			return shared.RandomFormula(rng, prof), ""
+		}
+		schemas = nearMissSchemas
+	}
+	sc := schemas[rng.IntN(len(schemas))]
+	a := subformula(rng, prof, vars)
+	b := subformula(rng, prof, vars)
+	return sc.Build(a, b, vars[rng.IntN(len(vars))]), sc.Name
+}
+
+// subformula 生成模式中的子公式 A / B；模式本身占两层，子公式深度相应减少
+func subformula(rng *rand.Rand, prof sampler.Profile, vars []string) *core.Node {
+	depth := 1 + rng.IntN(max(1, prof.MaxDepth-2))
+	if depth <= 1 {
+		return shared.NewVar(vars[rng.IntN(len(vars))])
+	}
+	sub := prof
+	sub.MaxDepth = depth
+	return shared.RandomFormula(rng, sub)
+}
+
+// classify 用 validator 判定公式类别，同时返回使其为真 / 为假的赋值（不存在时为 nil）
+func (g ClassificationGenerator) classify(formula *core.Node, vars []string) (string, map[string]bool, map[string]bool) {
+	// 与 ⊤ 取值不同的赋值即令公式为假的赋值，与 ⊥ 同理
+	falsifying, notTautology := g.validator.EquivalenceCounterexample(formula, shared.Constant(true), vars)
+	satisfying, satisfiable := g.validator.EquivalenceCounterexample(formula, shared.Constant(false), vars)
+	switch {
+	case !notTautology:
+		return core.ClassTautology, satisfying, nil
+	case !satisfiable:
+		return core.ClassContradiction, nil, falsifying
+	default:
+		return core.ClassContingent, satisfying, falsifying
+	}
+}
+
+func poolOf(pools *core.ClassificationPools, class string) []string {
+	switch class {
+	case core.ClassTautology:
+		return pools.Tautologies
+	case core.ClassContradiction:
+		return pools.Contradictions
+	default:
+		return pools.Contingents
+	}
+}
+
+func addTo(pools *core.ClassificationPools, class, formula string) {
+	switch class {
+	case core.ClassTautology:
+		pools.Tautologies = append(pools.Tautologies, formula)
+	case core.ClassContradiction:
+		pools.Contradictions = append(pools.Contradictions, formula)
+	default:
+		pools.Contingents = append(pools.Contingents, formula)
+	}
+}
+
+// isPlanFeasible 检查是否满足计划要求
+func (ClassificationGenerator) isPlanFeasible(plan sampler.Plan, pools core.ClassificationPools) bool {
+	if plan.Intent == "CLS_CLASS_TF" {
+		return pools.Formula != nil
+	}
+
+	tautologies, contradictions, contingents := len(pools.Tautologies), len(pools.Contradictions), len(pools.Contingents)
+	var correct, distractors int
+	switch plan.Intent {
+	case "CLS_TAUTOLOGY":
+		correct, distractors = tautologies, contradictions+contingents
+	case "CLS_CONTRADICTION":
+		correct, distractors = contradictions, tautologies+contingents
+	case "CLS_SATISFIABLE":
+		correct, distractors = tautologies+contingents, contradictions
+	default:
+		return false
+	}
+
+	switch plan.QType {
+	case models.QuestionTypeSingleChoice:
+		return correct >= 1 && distractors >= 3
+	case models.QuestionTypeMultipleChoice:
+		k, rest := choice.OptionCounts(plan)
+		return correct >= k && distractors >= rest
+	default:
+		return false
+	}
+}
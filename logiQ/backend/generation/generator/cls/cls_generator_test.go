@@ -0,0 +1,102 @@
+package cls
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+	"backend/generation/parser"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"math/rand/v2"
+	"testing"
+)
+
+var prof = sampler.Profile{
+	Vars:       3,
+	MaxDepth:   4,
+	AllowedOps: []core.NodeKind{core.Not, core.And, core.Or, core.Impl, core.Iff},
+}
+
+// truthCount 逐行枚举，统计公式为真的行数
+func truthCount(t *testing.T, formula string) (int, int) {
+	t.Helper()
+	node, err := parser.Parse(formula)
+	if err != nil {
+		t.Fatalf("candidate %q does not parse: %v", formula, err)
+	}
+	vars := []string{"p", "q", "r"}
+	table := validator.Compile(node, vars).Table()
+	return table.Count(), table.Rows()
+}
+
+func TestGenerateClassifiesCandidates(t *testing.T) {
+	g := NewClassificationGenerator(validator.NewBitsetValidator())
+	plan := sampler.Plan{QType: models.QuestionTypeMultipleChoice, Intent: "CLS_TAUTOLOGY", MCCorrectCount: 3}
+	for seed := uint64(0); seed < 8; seed++ {
+		pools, _, err := g.Generate(rand.New(rand.NewPCG(seed, 3)), prof, plan)
+		if err != nil {
+			t.Fatalf("seed %d: unexpected error: %v", seed, err)
+		}
+		c := pools.Classification
+		if len(c.Tautologies) < 3 {
+			t.Fatalf("seed %d: got %d tautologies, want at least 3", seed, len(c.Tautologies))
+		}
+		for _, f := range c.Tautologies {
+			if n, rows := truthCount(t, f); n != rows {
+				t.Errorf("%s is not a tautology (%d/%d rows true)", f, n, rows)
+			}
+		}
+		for _, f := range c.Contradictions {
+			if n, _ := truthCount(t, f); n != 0 {
+				t.Errorf("%s is not a contradiction (%d rows true)", f, n)
+			}
+			if c.Falsifying[f] == "" {
+				t.Errorf("contradiction %s has no falsifying assignment", f)
+			}
+		}
+		for _, f := range c.Contingents {
+			if n, rows := truthCount(t, f); n == 0 || n == rows {
+				t.Errorf("%s is not contingent (%d/%d rows true)", f, n, rows)
+			}
+			if c.Satisfying[f] == "" || c.Falsifying[f] == "" {
+				t.Errorf("contingent %s is missing a witness", f)
+			}
+		}
+	}
+}
+
+func TestGenerateTrueFalse(t *testing.T) {
+	g := NewClassificationGenerator(validator.NewDefaultValidator())
+	plan := sampler.Plan{QType: models.QuestionTypeTrueFalse, Intent: "CLS_CLASS_TF"}
+	seen := make(map[string]bool)
+	for seed := uint64(0); seed < 32; seed++ {
+		pools, _, err := g.Generate(rand.New(rand.NewPCG(seed, 4)), prof, plan)
+		if err != nil {
+			t.Fatalf("seed %d: unexpected error: %v", seed, err)
+		}
+		if pools.Classification.Formula == nil {
+			t.Fatalf("seed %d: no formula chosen", seed)
+		}
+		seen[pools.Classification.Class] = true
+	}
+	for _, class := range []string{core.ClassTautology, core.ClassContradiction, core.ClassContingent} {
+		if !seen[class] {
+			t.Errorf("class %s never asked about", class)
+		}
+	}
+}
+
+func TestSchemas(t *testing.T) {
+	a, _ := parser.Parse("p ∧ q")
+	b, _ := parser.Parse("q → r")
+	for _, sc := range tautologySchemas {
+		if n, rows := truthCount(t, helper.Stringify(sc.Build(a.Clone(), b.Clone(), "r"))); n != rows {
+			t.Errorf("schema %s is not a tautology", sc.Name)
+		}
+	}
+	for _, sc := range contradictionSchemas {
+		if n, _ := truthCount(t, helper.Stringify(sc.Build(a.Clone(), b.Clone(), "r"))); n != 0 {
+			t.Errorf("schema %s is not a contradiction", sc.Name)
+		}
+	}
+}
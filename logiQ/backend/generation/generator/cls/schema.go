@@ -0,0 +1,54 @@
+package cls
+
+import (
+	"backend/generation/core"
+	"backend/generation/generator/shared"
+)
+
+// schema 用子公式 A、B 实例化出一个公式；v 为构造 v ∨ ¬v / v ∧ ¬v 时使用的变量，
+// 对应名称中的 B
+type schema struct {
+	Name  string
+	Build func(a, b *core.Node, v string) *core.Node
+}
+
+func not(n *core.Node) *core.Node        { return shared.Unary(core.Not, n) }
+func and(l, r *core.Node) *core.Node     { return shared.Binary(core.And, l, r) }
+func or(l, r *core.Node) *core.Node      { return shared.Binary(core.Or, l, r) }
+func implies(l, r *core.Node) *core.Node { return shared.Binary(core.Impl, l, r) }
+func iff(l, r *core.Node) *core.Node     { return shared.Binary(core.Iff, l, r) }
+
+// tautologySchemas 无论 A、B 取什么都恒真
+var tautologySchemas = []schema{
+	{"A ∨ (B ∨ ¬B)", func(a, _ *core.Node, v string) *core.Node { return or(a, shared.TautologyFromVar(v)) }},
+	{"(B ∧ ¬B) → A", func(a, _ *core.Node, v string) *core.Node { return implies(shared.ContradictionFromVar(v), a) }},
+	{"A ∨ ¬A", func(a, _ *core.Node, _ string) *core.Node { return or(a, not(a.Clone())) }},
+	{"¬(A ∧ ¬A)", func(a, _ *core.Node, _ string) *core.Node { return not(and(a, not(a.Clone()))) }},
+	{"A → (B → A)", func(a, b *core.Node, _ string) *core.Node { return implies(a, implies(b, a.Clone())) }},
+	{"(A ∧ B) → A", func(a, b *core.Node, _ string) *core.Node { return implies(and(a, b), a.Clone()) }},
+	{"A → (A ∨ B)", func(a, b *core.Node, _ string) *core.Node { return implies(a, or(a.Clone(), b)) }},
+	{"((A → B) ∧ A) → B", func(a, b *core.Node, _ string) *core.Node { return implies(and(implies(a, b), a.Clone()), b.Clone()) }},
+	{"(A → B) ∨ (B → A)", func(a, b *core.Node, _ string) *core.Node { return or(implies(a, b), implies(b.Clone(), a.Clone())) }},
+}
+
+// contradictionSchemas 无论 A、B 取什么都恒假
+var contradictionSchemas = []schema{
+	{"A ∧ (B ∧ ¬B)", func(a, _ *core.Node, v string) *core.Node { return and(a, shared.ContradictionFromVar(v)) }},
+	{"¬(A ∨ (B ∨ ¬B))", func(a, _ *core.Node, v string) *core.Node { return not(or(a, shared.TautologyFromVar(v))) }},
+	{"A ∧ ¬A", func(a, _ *core.Node, _ string) *core.Node { return and(a, not(a.Clone())) }},
+	{"A ↔ ¬A", func(a, _ *core.Node, _ string) *core.Node { return iff(a, not(a.Clone())) }},
+	{"¬(A → (B → A))", func(a, b *core.Node, _ string) *core.Node { return not(implies(a, implies(b, a.Clone()))) }},
+	{"(A → B) ∧ (A ∧ ¬B)", func(a, b *core.Node, _ string) *core.Node { return and(implies(a, b), and(a.Clone(), not(b.Clone()))) }},
+	{"(A ∨ B) ∧ ¬(A ∨ B)", func(a, b *core.Node, _ string) *core.Node { return and(or(a, b), not(or(a.Clone(), b.Clone()))) }},
+}
+
+// nearMissSchemas 形似恒真式的常见错误，一般既非恒真也非恒假，用作干扰项
+var nearMissSchemas = []schema{
+	{"(A → B) → (B → A)", func(a, b *core.Node, _ string) *core.Node {
+		return implies(implies(a, b), implies(b.Clone(), a.Clone()))
+	}},
+	{"A → (A ∧ B)", func(a, b *core.Node, _ string) *core.Node { return implies(a, and(a.Clone(), b)) }},
+	{"((A → B) ∧ B) → A", func(a, b *core.Node, _ string) *core.Node { return implies(and(implies(a, b), b.Clone()), a.Clone()) }},
+	{"(A ∨ B) → A", func(a, b *core.Node, _ string) *core.Node { return implies(or(a, b), a.Clone()) }},
+	{"A ∨ ¬B", func(a, b *core.Node, _ string) *core.Node { return or(a, not(b)) }},
+}
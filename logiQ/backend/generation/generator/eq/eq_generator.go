@@ -1,6 +1,7 @@
 package eq
 
 import (
+	"backend/generation/builder/choice"
 	"backend/generation/config"
 	"backend/generation/core"
 	"backend/generation/generator/shared"
@@ -39,9 +40,11 @@ func (g EquivalenceGenerator) Generate(rng *rand.Rand, prof sampler.Profile, pla
 		//2. 利用等价/非等价规则生成候选集合
 		equivCandidates := GenerateEquivalentVariants(targetFormula, rng, g.cfg, prof.EqProfile.ChainSteps)
 		nonEquivCandidates := GenerateNonEquivalentVariants(targetFormula, rng, g.cfg, prof.EqProfile.ChainSteps)
+		// 按距离目标公式的步数筛选干扰项，步数越少越接近目标，越难分辨
+		nonEquivCandidates = FilterByDistance(nonEquivCandidates, 1, prof.EqProfile.MaxDistractorSteps)
 
 		//3. run validator for each candidate, filtering by equivalence / non-equivalence
-		equivPool, nonEquivPool := g.filterCandidates(targetFormula, equivCandidates, nonEquivCandidates, usedVars)
+		equivPool, nonEquivPool, derivations, counterexamples := g.filterCandidates(targetFormula, equivCandidates, nonEquivCandidates, usedVars, maxExprLength(prof))
 
 		// 4. 若候选数量不足，继续重试
 		if len(equivPool) == 0 || len(nonEquivPool) == 0 {
@@ -49,10 +52,12 @@ func (g EquivalenceGenerator) Generate(rng *rand.Rand, prof sampler.Profile, pla
 		}
 
 		eqPools := core.EquivalencePools{
-			Target:       targetFormula,
-			Vars:         usedVars,
-			EquivPool:    equivPool,
-			NonEquivPool: nonEquivPool,
+			Target:          targetFormula,
+			Vars:            usedVars,
+			EquivPool:       equivPool,
+			NonEquivPool:    nonEquivPool,
+			Derivations:     derivations,
+			Counterexamples: counterexamples,
 		}
 
 		// 5. 根据 plan.Intent / plan.QType 确认是否满足正确/干扰项数量需求
@@ -72,27 +77,43 @@ func (g EquivalenceGenerator) Generate(rng *rand.Rand, prof sampler.Profile, pla
 // filterCandidates
 // 过滤等价候选, 非等价候选
 // 删除长度过长的公式
-// 返回字符串形式的公式
-func (g EquivalenceGenerator) filterCandidates(target *core.Node, equivCandidates []*core.Node, nonEquivCandidates []*core.Node, vars []string) ([]string, []string) {
+// 返回字符串形式的公式，每个保留候选对应的推导步骤，以及非等价候选与目标取值不同的赋值
+func (g EquivalenceGenerator) filterCandidates(target *core.Node, equivCandidates []Variant, nonEquivCandidates []Variant, vars []string, maxLen int) ([]string, []string, map[string][]core.DerivationStep, map[string]string) {
 	equi := make([]string, 0, len(equivCandidates))
 	nonEqui := make([]string, 0, len(nonEquivCandidates))
+	derivations := make(map[string][]core.DerivationStep, len(equivCandidates)+len(nonEquivCandidates))
+	counterexamples := make(map[string]string, len(nonEquivCandidates))
 
 	targetStr := helper.Stringify(target)
 	for _, candidate := range equivCandidates {
-		candidateStr := helper.Stringify(candidate)
-		if g.validator.Equivalent(target, candidate, vars) && candidateStr != targetStr && len(candidateStr) <= shared.MAX_EXPR_LENGTH {
+		candidateStr := helper.Stringify(candidate.Node)
+		if g.validator.Equivalent(target, candidate.Node, vars) && candidateStr != targetStr && len(candidateStr) <= maxLen {
 			equi = append(equi, candidateStr)
+			derivations[candidateStr] = candidate.Steps
 		}
 	}
 
 	for _, candidate := range nonEquivCandidates {
-		candidateStr := helper.Stringify(candidate)
-		if !g.validator.Equivalent(target, candidate, vars) && len(candidateStr) <= shared.MAX_EXPR_LENGTH {
+		candidateStr := helper.Stringify(candidate.Node)
+		if len(candidateStr) > maxLen {
+			continue
+		}
+		if assign, differ := g.validator.EquivalenceCounterexample(target, candidate.Node, vars); differ {
 			nonEqui = append(nonEqui, candidateStr)
+			derivations[candidateStr] = candidate.Steps
+			counterexamples[candidateStr] = helper.AssignmentStringify(vars, assign)
 		}
 	}
 
-	return equi, nonEqui
+	return equi, nonEqui, derivations, counterexamples
+}
+
+// maxExprLength 候选公式的长度上限，未配置时使用 shared.MAX_EXPR_LENGTH
+func maxExprLength(prof sampler.Profile) int {
+	if prof.EqProfile.MaxExprLength > 0 {
+		return prof.EqProfile.MaxExprLength
+	}
+	return shared.MAX_EXPR_LENGTH
 }
 
 // 检是否满足计划要求
@@ -113,11 +134,7 @@ func (EquivalenceGenerator) isPlanFeasible(plan sampler.Plan, pools core.Equival
 			return false
 		}
 	case models.QuestionTypeMultipleChoice:
-		correct := plan.MCCorrectCount
-		if correct <= 0 || correct > 4 {
-			correct = 2
-		}
-		neededDistractor := 4 - correct
+		correct, neededDistractor := choice.OptionCounts(plan)
 		switch plan.Intent {
 		case "EQ_EQUIVALENT":
 			return len(pools.EquivPool) >= correct && len(pools.NonEquivPool) >= neededDistractor
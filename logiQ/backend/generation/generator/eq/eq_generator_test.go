@@ -53,3 +53,21 @@ func TestGenerate(t *testing.T) {
 	}
 
 }
+
+func TestGenerateRecordsDerivations(t *testing.T) {
+	appCfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	generator := NewEquivalenceGenerator(validator.NewDefaultValidator(), appCfg.Equivalence)
+	pools, _, err := generator.Generate(rand.New(rand.NewPCG(3, 9)), prof, plan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	eqPools := pools.Equivalence
+	for _, candidate := range append(append([]string{}, eqPools.EquivPool...), eqPools.NonEquivPool...) {
+		if len(eqPools.Derivations[candidate]) == 0 {
+			t.Errorf("candidate %s has no rule chain", candidate)
+		}
+	}
+}
@@ -19,21 +19,21 @@ const (
 	ruleGroupNonEquivalent
 )
 
-// GenerateEquivalentVariants 根据难度配置生成与根公式等价的候选。
-func GenerateEquivalentVariants(root *core.Node, rng *rand.Rand, cfg config.EquivalenceConfig, chainStep int) []*core.Node {
+// GenerateEquivalentVariants 根据难度配置生成与根公式等价的候选，附带各自的规则链。
+func GenerateEquivalentVariants(root *core.Node, rng *rand.Rand, cfg config.EquivalenceConfig, chainStep int) []Variant {
 	return generateVariants(root, rng, cfg, chainStep, ruleGroupEquivalent)
 }
 
-// GenerateNonEquivalentVariants 根据难度配置生成与根公式不等价的候选。
-func GenerateNonEquivalentVariants(root *core.Node, rng *rand.Rand, cfg config.EquivalenceConfig, chainStep int) []*core.Node {
+// GenerateNonEquivalentVariants 根据难度配置生成与根公式不等价的候选，附带各自的规则链。
+func GenerateNonEquivalentVariants(root *core.Node, rng *rand.Rand, cfg config.EquivalenceConfig, chainStep int) []Variant {
 	return generateVariants(root, rng, cfg, chainStep, ruleGroupNonEquivalent)
 }
 
-func generateVariants(root *core.Node, rng *rand.Rand, cfg config.EquivalenceConfig, chainStep int, group ruleGroup) []*core.Node {
+func generateVariants(root *core.Node, rng *rand.Rand, cfg config.EquivalenceConfig, chainStep int, group ruleGroup) []Variant {
 
 	rules := collectConfiguredRules(cfg, group)
 	executor := RuleExecutor{Rng: rng, Rules: rules, Limit: chainStep}
-	return executor.Execute(root)
+	return executor.ExecuteVariants(root)
 }
 
 func collectConfiguredRules(cfg config.EquivalenceConfig, group ruleGroup) []Rule {
@@ -76,6 +76,11 @@ var builtinRuleRegistry = map[ruleGroup]map[string]Rule{
 		"biconditional_expansion": {Name: "biconditional_expansion", Apply: WrapDeterministic(BiconditionalVariants)},
 		"associativity":           {Name: "associativity", Apply: WrapDeterministic(associativityVariants)},
 		"distributivity":          {Name: "distributivity", Apply: WrapDeterministic(DistributivityVariants)},
+		"xor_expansion":           {Name: "xor_expansion", Apply: WrapDeterministic(XorVariants)},
+		"nand_nor_expansion":      {Name: "nand_nor_expansion", Apply: WrapDeterministic(NandNorVariants)},
+		"identity":                {Name: "identity", Apply: WrapDeterministic(identityVariants)},
+		"domination":              {Name: "domination", Apply: WrapDeterministic(dominationVariants)},
+		"constant_negation":       {Name: "constant_negation", Apply: WrapDeterministic(constantNegationVariants)},
 	},
 	ruleGroupNonEquivalent: {
 		"negate_root":         {Name: "negate_root", Apply: WrapDeterministic(negateRootVariants)},
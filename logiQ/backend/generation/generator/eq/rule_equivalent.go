@@ -19,7 +19,9 @@ func commutativityVariants(node *core.Node) []*core.Node {
 	if node.Left == nil || node.Right == nil {
 		return nil
 	}
-	if node.Kind != core.And && node.Kind != core.Or && node.Kind != core.Iff {
+	switch node.Kind {
+	case core.And, core.Or, core.Iff, core.Xor, core.Nand, core.Nor:
+	default:
 		return nil
 	}
 	clone := node.Clone()
@@ -84,13 +86,96 @@ func BiconditionalVariants(node *core.Node) []*core.Node {
 	return nil
 }
 
+// XorVariants 展开异或：A ⊕ B ≡ (A ∨ B) ∧ ¬(A ∧ B) ≡ ¬(A ↔ B)
+func XorVariants(node *core.Node) []*core.Node {
+	if node.Kind != core.Xor || node.Left == nil || node.Right == nil {
+		return nil
+	}
+	either := &core.Node{Kind: core.Or, Left: node.Left.Clone(), Right: node.Right.Clone()}
+	both := &core.Node{Kind: core.And, Left: node.Left.Clone(), Right: node.Right.Clone()}
+	iff := &core.Node{Kind: core.Iff, Left: node.Left.Clone(), Right: node.Right.Clone()}
+	return []*core.Node{
+		{Kind: core.And, Left: either, Right: &core.Node{Kind: core.Not, Left: both}},
+		{Kind: core.Not, Left: iff},
+	}
+}
+
+// NandNorVariants 展开与非、或非：A ↑ B ≡ ¬(A ∧ B)，A ↓ B ≡ ¬(A ∨ B)
+func NandNorVariants(node *core.Node) []*core.Node {
+	if node.Left == nil || node.Right == nil {
+		return nil
+	}
+	var kind core.NodeKind
+	switch node.Kind {
+	case core.Nand:
+		kind = core.And
+	case core.Nor:
+		kind = core.Or
+	default:
+		return nil
+	}
+	return []*core.Node{{Kind: core.Not, Left: &core.Node{Kind: kind, Left: node.Left.Clone(), Right: node.Right.Clone()}}}
+}
+
+// identityVariants 同一律：A ∧ ⊤ ≡ A，A ∨ ⊥ ≡ A（常量在左侧时相同）
+func identityVariants(node *core.Node) []*core.Node {
+	var unit core.NodeKind
+	switch node.Kind {
+	case core.And:
+		unit = core.True
+	case core.Or:
+		unit = core.False
+	default:
+		return nil
+	}
+	var out []*core.Node
+	if node.Right != nil && node.Right.Kind == unit && node.Left != nil {
+		out = append(out, node.Left.Clone())
+	}
+	if node.Left != nil && node.Left.Kind == unit && node.Right != nil {
+		out = append(out, node.Right.Clone())
+	}
+	return shared.DedupNodes(out)
+}
+
+// dominationVariants 零律：A ∨ ⊤ ≡ ⊤，A ∧ ⊥ ≡ ⊥
+func dominationVariants(node *core.Node) []*core.Node {
+	var zero core.NodeKind
+	switch node.Kind {
+	case core.And:
+		zero = core.False
+	case core.Or:
+		zero = core.True
+	default:
+		return nil
+	}
+	if (node.Left != nil && node.Left.Kind == zero) || (node.Right != nil && node.Right.Kind == zero) {
+		return []*core.Node{{Kind: zero}}
+	}
+	return nil
+}
+
+// constantNegationVariants ¬⊤ ≡ ⊥，¬⊥ ≡ ⊤
+func constantNegationVariants(node *core.Node) []*core.Node {
+	if node.Kind != core.Not || node.Left == nil {
+		return nil
+	}
+	switch node.Left.Kind {
+	case core.True:
+		return []*core.Node{{Kind: core.False}}
+	case core.False:
+		return []*core.Node{{Kind: core.True}}
+	}
+	return nil
+}
+
 func associativityVariants(node *core.Node) []*core.Node {
 	if node.Left == nil || node.Right == nil {
 		return nil
 	}
 	var variants []*core.Node
 	switch node.Kind {
-	case core.And, core.Or:
+	case core.And, core.Or, core.Xor:
 		if node.Right.Kind == node.Kind && node.Right.Left != nil && node.Right.Right != nil {
 			left := &core.Node{Kind: node.Kind, Left: node.Left.Clone(), Right: node.Right.Left.Clone()}
 			variant := &core.Node{Kind: node.Kind, Left: left, Right: node.Right.Right.Clone()}
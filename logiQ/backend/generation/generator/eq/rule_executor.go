@@ -14,19 +14,69 @@ type RuleExecutor struct {
 }
 
 type ruleState struct {
-	node *core.Node
-	used stringSet
+	node  *core.Node
+	used  stringSet
+	steps []core.DerivationStep
 }
 
 type stringSet map[string]struct{}
 
+// Variant is a rewritten formula together with the ordered steps that
+// produced it from the root.
+type Variant struct {
+	Node  *core.Node
+	Steps []core.DerivationStep
+}
+
+// Rules returns the rule names of the derivation in application order.
+func (v Variant) Rules() []string {
+	names := make([]string, len(v.Steps))
+	for i, step := range v.Steps {
+		names[i] = step.Rule
+	}
+	return names
+}
+
+// Distance is the number of rewrite steps between the root and the variant.
+func (v Variant) Distance() int {
+	return len(v.Steps)
+}
+
+// FilterByDistance keeps the variants whose distance lies in [min, max].
+// A non-positive max means no upper bound.
+func FilterByDistance(variants []Variant, min, max int) []Variant {
+	out := make([]Variant, 0, len(variants))
+	for _, v := range variants {
+		d := v.Distance()
+		if d < min || (max > 0 && d > max) {
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// Execute returns the distinct formulas reachable from root.
 func (e RuleExecutor) Execute(root *core.Node) []*core.Node {
+	variants := e.ExecuteVariants(root)
+	out := make([]*core.Node, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, v.Node)
+	}
+	return shared.DedupNodes(out)
+}
+
+// ExecuteVariants is like Execute but also reports, for every formula, the
+// derivation that first reached it. Because the search is breadth first the
+// recorded derivation is one of the shortest.
+func (e RuleExecutor) ExecuteVariants(root *core.Node) []Variant {
 	if root == nil || e.Limit <= 0 || len(e.Rules) == 0 {
 		return nil
 	}
 
-	// results 保存已生成的唯一公式候选（按字符串签名去重）
-	results := make(map[string]*core.Node)
+	// results 保存已生成的唯一公式候选（按字符串签名去重），order 保持首次出现的顺序
+	results := make(map[string]Variant)
+	order := make([]string, 0)
 	// frontier 维护当前深度可继续扩展的状态集合
 	frontier := []ruleState{{node: root.Clone(), used: stringSet{}}}
 
@@ -42,15 +92,23 @@ func (e RuleExecutor) Execute(root *core.Node) []*core.Node {
 					continue
 				}
 
-				for _, variant := range applyRuleRecursive(st.node, e.Rng, rule.Apply) {
+				for _, rw := range applyRuleRecursive(st.node, e.Rng, rule.Apply, "") {
+					variant := rw.root
 					if variant == nil {
 						continue
 					}
 
-					// 记录新公式，避免重复收集
+					// 记录新公式及其推导步骤，避免重复收集
 					sig := helper.Stringify(variant)
+					steps := appendStep(st.steps, core.DerivationStep{
+						Rule:   rule.Name,
+						Path:   rw.path,
+						Before: helper.Stringify(rw.before),
+						After:  helper.Stringify(rw.after),
+					})
 					if _, ok := results[sig]; !ok {
-						results[sig] = variant
+						results[sig] = Variant{Node: variant, Steps: steps}
+						order = append(order, sig)
 					}
 					// 达到步数上限或本层已见则跳过
 					if step+1 >= e.Limit {
@@ -62,7 +120,7 @@ func (e RuleExecutor) Execute(root *core.Node) []*core.Node {
 
 					// 将当前规则标记入 used，供后续层判断是否复用
 					seen.add(sig)
-					next = append(next, ruleState{node: variant, used: st.used.cloneWith(rule.Name)})
+					next = append(next, ruleState{node: variant, used: st.used.cloneWith(rule.Name), steps: steps})
 				}
 			}
 		}
@@ -73,12 +131,18 @@ func (e RuleExecutor) Execute(root *core.Node) []*core.Node {
 		frontier = next
 	}
 
-	// 生成最终列表并再做一次去重
-	out := make([]*core.Node, 0, len(results))
-	for _, node := range results {
-		out = append(out, node)
+	out := make([]Variant, 0, len(order))
+	for _, sig := range order {
+		out = append(out, results[sig])
 	}
-	return shared.DedupNodes(out)
+	return out
+}
+
+// appendStep 复制推导步骤后追加，避免不同分支共享底层数组
+func appendStep(steps []core.DerivationStep, step core.DerivationStep) []core.DerivationStep {
+	out := make([]core.DerivationStep, len(steps), len(steps)+1)
+	copy(out, steps)
+	return append(out, step)
 }
 
 func (s stringSet) contains(val string) bool {
@@ -105,26 +169,36 @@ func (s stringSet) cloneWith(val string) stringSet {
 	return out
 }
 
-func applyRuleRecursive(node *core.Node, rng *rand.Rand, apply func(*core.Node, *rand.Rand) []*core.Node) []*core.Node {
+// rewrite 是一次规则应用的结果：替换后的整棵树，以及被改写子项的位置和前后形态
+type rewrite struct {
+	root   *core.Node
+	path   string
+	before *core.Node
+	after  *core.Node
+}
+
+func applyRuleRecursive(node *core.Node, rng *rand.Rand, apply func(*core.Node, *rand.Rand) []*core.Node, path string) []rewrite {
 	if node == nil {
 		return nil
 	}
-	var variants []*core.Node
+	var variants []rewrite
 	for _, replacement := range apply(node, rng) {
-		variants = append(variants, replacement)
+		variants = append(variants, rewrite{root: replacement, path: path, before: node, after: replacement})
 	}
 	if node.Left != nil {
-		for _, leftVariant := range applyRuleRecursive(node.Left, rng, apply) {
+		for _, leftVariant := range applyRuleRecursive(node.Left, rng, apply, path+"L") {
 			clone := node.Clone()
-			clone.Left = leftVariant
-			variants = append(variants, clone)
+			clone.Left = leftVariant.root
+			leftVariant.root = clone
+			variants = append(variants, leftVariant)
 		}
 	}
 	if node.Right != nil {
-		for _, rightVariant := range applyRuleRecursive(node.Right, rng, apply) {
+		for _, rightVariant := range applyRuleRecursive(node.Right, rng, apply, path+"R") {
 			clone := node.Clone()
-			clone.Right = rightVariant
-			variants = append(variants, clone)
+			clone.Right = rightVariant.root
+			rightVariant.root = clone
+			variants = append(variants, rightVariant)
 		}
 	}
 	return variants
@@ -0,0 +1,128 @@
+package eq
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+	"backend/generation/parser"
+	"backend/generation/validator"
+	"math/rand/v2"
+	"testing"
+)
+
+// replaceAt 按路径替换子项，返回新的树
+func replaceAt(node *core.Node, path string, sub *core.Node) *core.Node {
+	if path == "" {
+		return sub
+	}
+	clone := node.Clone()
+	if path[0] == 'L' {
+		clone.Left = replaceAt(node.Left, path[1:], sub)
+	} else {
+		clone.Right = replaceAt(node.Right, path[1:], sub)
+	}
+	return clone
+}
+
+func subtermAt(node *core.Node, path string) *core.Node {
+	for _, dir := range path {
+		if dir == 'L' {
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+	return node
+}
+
+func TestExecuteVariantsStepsReplay(t *testing.T) {
+	root, err := parser.Parse("¬(p ∧ q) → (r ∨ ¬¬s)")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	executor := RuleExecutor{
+		Rng: rand.New(rand.NewPCG(1, 2)),
+		Rules: []Rule{
+			builtinRuleRegistry[ruleGroupEquivalent]["de_morgan"],
+			builtinRuleRegistry[ruleGroupEquivalent]["commutativity"],
+			builtinRuleRegistry[ruleGroupEquivalent]["double_negation"],
+		},
+		Limit: 3,
+	}
+
+	variants := executor.ExecuteVariants(root)
+	if len(variants) == 0 {
+		t.Fatal("expected variants")
+	}
+	for _, v := range variants {
+		if v.Distance() == 0 || v.Distance() > executor.Limit {
+			t.Fatalf("variant %s has distance %d", helper.Stringify(v.Node), v.Distance())
+		}
+		// 按记录的步骤逐步重放，应当得到同一个公式
+		cur := root
+		for _, step := range v.Steps {
+			if got := helper.Stringify(subtermAt(cur, step.Path)); got != step.Before {
+				t.Fatalf("step %s at %q: subterm %q, recorded before %q", step.Rule, step.Path, got, step.Before)
+			}
+			after, err := parser.Parse(step.After)
+			if err != nil {
+				t.Fatalf("parse after %q: %v", step.After, err)
+			}
+			cur = replaceAt(cur, step.Path, after)
+		}
+		if helper.Stringify(cur) != helper.Stringify(v.Node) {
+			t.Errorf("replay of %v gives %s, want %s", v.Rules(), helper.Stringify(cur), helper.Stringify(v.Node))
+		}
+	}
+}
+
+func TestFilterByDistance(t *testing.T) {
+	step := core.DerivationStep{Rule: "negate_root"}
+	variants := []Variant{
+		{Steps: []core.DerivationStep{step}},
+		{Steps: []core.DerivationStep{step, step}},
+		{Steps: []core.DerivationStep{step, step, step}},
+	}
+	if got := len(FilterByDistance(variants, 1, 1)); got != 1 {
+		t.Errorf("max 1: got %d variants, want 1", got)
+	}
+	if got := len(FilterByDistance(variants, 2, 0)); got != 2 {
+		t.Errorf("min 2 unbounded: got %d variants, want 2", got)
+	}
+}
+
+func TestExtendedConnectiveRules(t *testing.T) {
+	v := validator.NewDefaultValidator()
+	cases := []struct {
+		rule  string
+		input string
+		want  []string
+	}{
+		{"xor_expansion", "p ⊕ q", []string{"(p ∨ q) ∧ ¬(p ∧ q)", "¬(p ↔ q)"}},
+		{"nand_nor_expansion", "p ↑ q", []string{"¬(p ∧ q)"}},
+		{"nand_nor_expansion", "p ↓ ¬q", []string{"¬(p ∨ ¬q)"}},
+		{"identity", "(p → q) ∧ ⊤", []string{"p → q"}},
+		{"identity", "⊥ ∨ p", []string{"p"}},
+		{"domination", "p ∨ ⊤", []string{"⊤"}},
+		{"domination", "⊥ ∧ (p ⊕ q)", []string{"⊥"}},
+		{"constant_negation", "¬⊤", []string{"⊥"}},
+		{"commutativity", "p ↑ q", []string{"q ↑ p"}},
+	}
+	for _, c := range cases {
+		node, err := parser.Parse(c.input)
+		if err != nil {
+			t.Fatalf("parse %q: %v", c.input, err)
+		}
+		got := builtinRuleRegistry[ruleGroupEquivalent][c.rule].Apply(node, nil)
+		if len(got) != len(c.want) {
+			t.Fatalf("%s(%s) gives %d results, want %d", c.rule, c.input, len(got), len(c.want))
+		}
+		for i, g := range got {
+			if helper.Stringify(g) != c.want[i] {
+				t.Errorf("%s(%s)[%d] = %s, want %s", c.rule, c.input, i, helper.Stringify(g), c.want[i])
+			}
+			if !v.Equivalent(node, g, []string{"p", "q"}) {
+				t.Errorf("%s(%s) = %s is not equivalent", c.rule, c.input, helper.Stringify(g))
+			}
+		}
+	}
+}
@@ -29,6 +29,12 @@ func flipOperatorVariants(node *core.Node) []*core.Node {
 		return []*core.Node{{Kind: core.And, Left: node.Left.Clone(), Right: node.Right.Clone()}}
 	case core.Iff:
 		return []*core.Node{{Kind: core.Impl, Left: node.Left.Clone(), Right: node.Right.Clone()}}
+	case core.Xor:
+		return []*core.Node{{Kind: core.Or, Left: node.Left.Clone(), Right: node.Right.Clone()}}
+	case core.Nand:
+		return []*core.Node{{Kind: core.Nor, Left: node.Left.Clone(), Right: node.Right.Clone()}}
+	case core.Nor:
+		return []*core.Node{{Kind: core.Nand, Left: node.Left.Clone(), Right: node.Right.Clone()}}
 	default:
 		return nil
 	}
@@ -38,6 +44,10 @@ func mutateLiteralVariants(node *core.Node, rng *rand.Rand) []*core.Node {
 	switch node.Kind {
 	case core.Var:
 		return []*core.Node{{Kind: core.Not, Left: node.Clone()}}
+	case core.True:
+		return []*core.Node{{Kind: core.False}}
+	case core.False:
+		return []*core.Node{{Kind: core.True}}
 	case core.Not:
 		if node.Left != nil && node.Left.Kind == core.Var {
 			return []*core.Node{node.Left.Clone()}
@@ -0,0 +1,114 @@
+package inf
+
+import (
+	"backend/generation/builder/choice"
+	"backend/generation/core"
+	"backend/generation/generator/shared"
+	"backend/generation/helper"
+	"backend/generation/sampler"
+	"backend/generation/tableau"
+	"math/rand/v2"
+	"sort"
+)
+
+// maxBranches 为不可推出的结论构造表格时的分支数上限，超过时只保留验证器给出的反例
+const maxBranches = 32
+
+// maxRows 每组最多求出的赋值数；推理模板最多用到 7 个变量，此时两组合起来正好是全部赋值
+const maxRows = 128
+
+// prepareCounterexample 为 INF_COUNTEREXAMPLE 选择题干中的结论，并把赋值分成
+// 反例（前提全真且结论为假）和非反例两组，分别作为正确项和干扰项。
+// 两组赋值都由验证器求出，因此使用配置的判定引擎，变量多时也不必枚举全部 2^n 个赋值。
+// 没有结论能凑够选项时返回 false，由调用方重试。
+func (g InferenceGenerator) prepareCounterexample(pools *core.InferencePools, plan sampler.Plan, premises, invalidCons []*core.Node, rng *rand.Rand) bool {
+	needCorrect, needDistractor := choice.OptionCounts(plan)
+	falsum := &core.Node{Kind: core.False}
+
+	// 按随机顺序尝试各个不可推出的结论
+	for _, idx := range rng.Perm(len(invalidCons)) {
+		concl := invalidCons[idx]
+		refuting := g.models(premises, concl, pools.Vars)
+		// 非反例：使"前提全真且结论为假"不成立的赋值
+		refuted := shared.Unary(core.Not, conjoin(append(append([]*core.Node(nil), premises...), shared.Unary(core.Not, concl))))
+		others := g.models([]*core.Node{refuted}, falsum, pools.Vars)
+		if len(refuting) < needCorrect || len(others) < needDistractor {
+			continue
+		}
+		pools.CounterexampleConclusion = helper.Stringify(concl)
+		pools.CounterexampleSet = rows(pools.Vars, refuting)
+		pools.NonCounterexampleSet = rows(pools.Vars, others)
+		return true
+	}
+	return false
+}
+
+// models 用验证器逐个求出使 premises 全真、concl 为假的赋值：每求出一个，就把排除它的
+// 子句加入前提再求，最多 maxRows 个。结果按 helper.EnumerateAssignments 的顺序排列
+func (g InferenceGenerator) models(premises []*core.Node, concl *core.Node, vars []string) []map[string]bool {
+	constraints := append([]*core.Node(nil), premises...)
+	var found []map[string]bool
+	for len(found) < maxRows {
+		assign, ok := g.validator.DerivationCounterexample(constraints, concl, vars)
+		if !ok {
+			break
+		}
+		found = append(found, assign)
+		constraints = append(constraints, shared.Unary(core.Not, cube(vars, assign)))
+	}
+	sort.Slice(found, func(i, j int) bool { return rowIndex(vars, found[i]) < rowIndex(vars, found[j]) })
+	return found
+}
+
+// cube 把赋值写成文字的合取，如 p ∧ ¬q
+func cube(vars []string, assign map[string]bool) *core.Node {
+	lits := make([]*core.Node, len(vars))
+	for i, name := range vars {
+		lits[i] = &core.Node{Kind: core.Var, Name: name}
+		if !assign[name] {
+			lits[i] = shared.Unary(core.Not, lits[i])
+		}
+	}
+	return conjoin(lits)
+}
+
+func conjoin(nodes []*core.Node) *core.Node {
+	acc := nodes[0]
+	for _, n := range nodes[1:] {
+		acc = shared.Binary(core.And, acc, n)
+	}
+	return acc
+}
+
+// rowIndex 赋值在 helper.EnumerateAssignments 中的位置：第 i 个变量为真对应第 i 位
+func rowIndex(vars []string, assign map[string]bool) int {
+	idx := 0
+	for i, name := range vars {
+		if assign[name] {
+			idx |= 1 << i
+		}
+	}
+	return idx
+}
+
+func rows(vars []string, assigns []map[string]bool) []string {
+	out := make([]string, len(assigns))
+	for i, assign := range assigns {
+		out[i] = helper.AssignmentStringify(vars, assign)
+	}
+	return out
+}
+
+// openBranch 构造前提与结论否定的表格，返回最左边的开放分支；表格封闭或过大时返回 false
+func openBranch(premises []*core.Node, concl *core.Node) (tableau.Branch, bool) {
+	formulas := append(append([]*core.Node(nil), premises...), shared.Unary(core.Not, concl))
+	tab, err := tableau.Build(formulas, maxBranches)
+	if err != nil {
+		return tableau.Branch{}, false
+	}
+	open := tab.Open()
+	if len(open) == 0 {
+		return tableau.Branch{}, false
+	}
+	return open[0], true
+}
@@ -1,9 +1,11 @@
 package inf
 
 import (
+	"backend/generation/builder/choice"
 	"backend/generation/config"
 	"backend/generation/core"
 	"backend/generation/generator/shared"
+	"backend/generation/helper"
 	"backend/generation/sampler"
 	"backend/generation/validator"
 	"backend/models"
@@ -35,31 +37,20 @@ func (g InferenceGenerator) Generate(rng *rand.Rand, prof sampler.Profile, plan
 		}
 		attempts++
 
-		pair, err := g.selectTemplatePairRandomly(g.cfg.TemplatePairs, prof.InfProfile.ChainSteps, rng)
+		// 选模板、解析前提和结论、绑定并替换占位符
+		inst, err := g.Instantiate(rng, prof.InfProfile)
 		if err != nil {
 			return core.CandidatePools{}, nil, err
 		}
-		// 解析前提和结论模板
-		premises, validCons, inValidCons, err := extractPremisesAndConclusions(*pair)
-		if err != nil {
-			return core.CandidatePools{}, nil, err
-		}
-		// 获取占位符的绑定
-		bindings, err := g.prepareBindings(rng, pair.Slots)
-		if err != nil {
-			return core.CandidatePools{}, nil, err
-		}
-		// 替换占位符
-		premisesInst, validConInst, inValidConInst := instantiateTemplatePair(premises, validCons, inValidCons, bindings)
 
 		// 跳过验证的步骤，因为模板已经保证了正确性，验证inf性能开销较大
 
 		// 对前提和结论进行等价变换，拓展，去重等  增加多样性
-		finalPremise, transValid, transInvalid := ExpandAndTransform(premisesInst, validConInst, inValidConInst, rng)
+		finalPremise, transValid, transInvalid := ExpandAndTransform(inst.Premises, inst.Valid, inst.Invalid, rng)
 
 		// 验证结论的正确性
 		usedVars := collectVars(finalPremise)
-		finalValid, finalInvalid := g.validateInference(finalPremise, transValid, transInvalid, usedVars)
+		finalValid, finalInvalid, witnesses := g.validateInference(finalPremise, transValid, transInvalid, usedVars)
 
 		// 验证生成的题目是否满足计划要求
 		if g.isPlanFeasible(plan, finalValid, finalInvalid) == false {
@@ -71,10 +62,31 @@ func (g InferenceGenerator) Generate(rng *rand.Rand, prof sampler.Profile, plan
 
 		// 构建pools
 		infPools := core.InferencePools{
+			TemplateName:       inst.Name,
 			Premises:           premiseStr,
 			ValidConclusions:   validStrs,
 			InvalidConclusions: inValidStrs,
 			Vars:               usedVars, // 只考虑前提中的变量，结论不会引入新变量
+			Counterexamples:    make(map[string]string, len(witnesses)),
+			OpenBranches:       make(map[string]string, len(witnesses)),
+			// 未变换的模板实例，供证明练习使用
+			TemplatePremises: inst.Premises,
+			TemplateValid:    inst.Valid,
+		}
+		for i, assign := range witnesses {
+			// 表格法的开放分支本身就是反例，有分支时用它的赋值，解析中的分支与赋值一致
+			if branch, ok := openBranch(finalPremise, finalInvalid[i]); ok {
+				infPools.OpenBranches[inValidStrs[i]] = branch.String()
+				assign = branch.Assignment(usedVars)
+			}
+			if assign != nil {
+				infPools.Counterexamples[inValidStrs[i]] = helper.AssignmentStringify(usedVars, assign)
+			}
+		}
+
+		// 反例题：选一个不可推出的结论，列出推翻它的赋值
+		if plan.Intent == "INF_COUNTEREXAMPLE" && !g.prepareCounterexample(&infPools, plan, finalPremise, finalInvalid, rng) {
+			continue
 		}
 
 		return core.CandidatePools{
@@ -113,20 +125,21 @@ func (g InferenceGenerator) isPlanFeasible(plan sampler.Plan, validCons []*core.
 			return len(inValidCons) >= 1 && len(validCons) >= 3
 		case "INF_VALIDITY_TF":
 			return true
+		case "INF_COUNTEREXAMPLE":
+			// 选项数量在 prepareCounterexample 中检查
+			return len(inValidCons) >= 1
 		default:
 			return false
 		}
 	case models.QuestionTypeMultipleChoice:
-		correct := plan.MCCorrectCount
-		if correct <= 0 || correct > 4 {
-			correct = 2
-		}
-		neededDistractor := 4 - correct
+		correct, neededDistractor := choice.OptionCounts(plan)
 		switch plan.Intent {
 		case "INF_DERIVABLE":
 			return len(validCons) >= correct && len(inValidCons) >= neededDistractor
 		case "INF_UNDERIVABLE":
 			return len(inValidCons) >= correct && len(validCons) >= neededDistractor
+		case "INF_COUNTEREXAMPLE":
+			return len(inValidCons) >= 1
 		default:
 			return false
 		}
@@ -136,18 +149,24 @@ func (g InferenceGenerator) isPlanFeasible(plan sampler.Plan, validCons []*core.
 
 }
 
-func (g InferenceGenerator) validateInference(premises, validCon, invalidCon []*core.Node, vars []string) ([]*core.Node, []*core.Node) {
+// validateInference 过滤结论，同时返回与不可推出结论一一对应的反例赋值
+// 前提不可同时满足时结论同样不可推出，但没有反例，对应位置为 nil
+func (g InferenceGenerator) validateInference(premises, validCon, invalidCon []*core.Node, vars []string) ([]*core.Node, []*core.Node, []map[string]bool) {
 	validatedValid := make([]*core.Node, 0, len(validCon))
 	validatedInvalid := make([]*core.Node, 0, len(invalidCon))
+	witnesses := make([]map[string]bool, 0, len(invalidCon))
 	for _, node := range validCon {
 		if g.validator.Derivable(premises, node, vars) {
 			validatedValid = append(validatedValid, node)
 		}
 	}
 	for _, node := range invalidCon {
-		if !g.validator.Derivable(premises, node, vars) {
-			validatedInvalid = append(validatedInvalid, node)
+		if g.validator.Derivable(premises, node, vars) {
+			continue
 		}
+		assign, _ := g.validator.DerivationCounterexample(premises, node, vars)
+		validatedInvalid = append(validatedInvalid, node)
+		witnesses = append(witnesses, assign)
 	}
-	return validatedValid, validatedInvalid
+	return validatedValid, validatedInvalid, witnesses
 }
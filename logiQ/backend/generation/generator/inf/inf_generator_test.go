@@ -3,10 +3,13 @@ package inf
 import (
 	"backend/generation/config"
 	"backend/generation/core"
+	"backend/generation/gentest"
+	"backend/generation/helper"
 	"backend/generation/sampler"
 	"backend/generation/validator"
 	"backend/models"
 	"math/rand/v2"
+	"strings"
 	"testing"
 )
 
@@ -43,3 +46,72 @@ func TestInfGenerator(t *testing.T) {
 	}
 
 }
+
+func TestCounterexampleIntent(t *testing.T) {
+	cfg, _ := config.LoadConfig()
+	infG := NewInferenceGenerator(myValidator, cfg.Inference)
+	ceRng := rand.New(rand.NewPCG(2, 8))
+	cePlan := sampler.Plan{QType: models.QuestionTypeMultipleChoice, Intent: "INF_COUNTEREXAMPLE", MCCorrectCount: 2}
+
+	pools, _, err := infG.Generate(ceRng, prof, cePlan)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inf := pools.Inference
+	if inf.CounterexampleConclusion == "" {
+		t.Fatal("counterexample conclusion not chosen")
+	}
+	if len(inf.CounterexampleSet) < 2 || len(inf.NonCounterexampleSet) < 2 {
+		t.Fatalf("not enough options: %d counterexamples, %d others", len(inf.CounterexampleSet), len(inf.NonCounterexampleSet))
+	}
+	if total := len(inf.CounterexampleSet) + len(inf.NonCounterexampleSet); total != 1<<len(inf.Vars) {
+		t.Errorf("assignments split into %d rows, want %d", total, 1<<len(inf.Vars))
+	}
+	if _, ok := inf.Counterexamples[inf.CounterexampleConclusion]; !ok {
+		t.Errorf("no witness recorded for %s", inf.CounterexampleConclusion)
+	}
+	for _, invalid := range inf.InvalidConclusions {
+		if _, ok := inf.Counterexamples[invalid]; !ok {
+			t.Errorf("no witness recorded for invalid conclusion %s", invalid)
+		}
+	}
+}
+
+// TestOpenBranchCounterexamples 不可推出的结论带有表格的开放分支，记录的反例取自该分支，
+// 由验证器确认它使前提全真、结论为假
+func TestOpenBranchCounterexamples(t *testing.T) {
+	infG := NewInferenceGenerator(myValidator, gentest.Config(t).Inference)
+	plans := []sampler.Plan{
+		{QType: models.QuestionTypeSingleChoice, Intent: "INF_DERIVABLE"},
+		{QType: models.QuestionTypeSingleChoice, Intent: "INF_UNDERIVABLE"},
+	}
+	branches := 0
+	gentest.Run(t, infG, plans, func(c gentest.Case, pools core.CandidatePools) {
+		inf := pools.Inference
+		premises := gentest.MustParseList(t, inf.Premises)
+		for concl, branch := range inf.OpenBranches {
+			branches++
+			row, ok := inf.Counterexamples[concl]
+			if !ok {
+				t.Errorf("%s: open branch %s of %s has no counterexample", c, branch, concl)
+				continue
+			}
+			assign := make(map[string]bool, len(inf.Vars))
+			for _, part := range strings.Split(row, ", ") {
+				name, value, _ := strings.Cut(part, "=")
+				assign[name] = value == "T"
+			}
+			for _, p := range premises {
+				if !myValidator.Eval(p, assign) {
+					t.Errorf("%s: premise %s is false under %s (branch %s)", c, helper.Stringify(p), row, branch)
+				}
+			}
+			if myValidator.Eval(gentest.MustParse(t, concl), assign) {
+				t.Errorf("%s: %s is true under %s (branch %s)", c, concl, row, branch)
+			}
+		}
+	})
+	if branches == 0 {
+		t.Error("no open branches recorded")
+	}
+}
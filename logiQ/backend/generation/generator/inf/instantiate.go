@@ -5,6 +5,7 @@ import (
 	"backend/generation/core"
 	"backend/generation/generator/shared"
 	"backend/generation/helper"
+	"backend/generation/sampler"
 	"errors"
 	"fmt"
 	"math/rand/v2"
@@ -14,6 +15,9 @@ import (
 
 var AllVars = []string{"p", "q", "r", "s", "t", "u", "v", "w", "x", "y", "z"}
 
+// surfaceVars 实例化后随机改名时可选的字母；不用 v，避免与 ∨ 混淆
+var surfaceVars = []string{"p", "q", "r", "s", "t", "u", "w", "x", "y", "z"}
+
 // extractPremisesAndConclusions 从模板对中提取共享前提、有效结论和无效结论。
 func extractPremisesAndConclusions(template config.InferenceTemplatePair) ([]*core.Node, []*core.Node, []*core.Node, error) {
 	// 解析共享前提
@@ -139,9 +143,9 @@ func (g InferenceGenerator) prepareBindings(rng *rand.Rand, slots map[string]con
 			right := nextVar()
 			return shared.Binary(core.Or, shared.NewVar(left), shared.NewVar(right))
 		case "use_const_true":
-			return shared.TautologyFromVar(nextVar())
+			return shared.Constant(true)
 		case "use_const_false":
-			return shared.ContradictionFromVar(nextVar())
+			return shared.Constant(false)
 		default:
 			return shared.NewVar(nextVar())
 		}
@@ -190,6 +194,48 @@ func instantiateTemplatePair(premises []*core.Node, validCons []*core.Node, inVa
 
 }
 
+// Instance is a template pair instantiated with concrete formulas, before
+// any equivalence transformation.
+type Instance struct {
+	Name     string
+	Premises []*core.Node
+	Valid    []*core.Node
+	Invalid  []*core.Node
+}
+
+// Instantiate picks a random template pair with the given chain length and
+// fills its slots. The structure of the template is preserved, which other
+// categories (e.g. proofs) rely on. When prof.Letters is set the variable
+// letters are drawn from it, so one structure appears under different names.
+func (g InferenceGenerator) Instantiate(rng *rand.Rand, prof sampler.InfProfile) (Instance, error) {
+	pair, err := g.selectTemplatePairRandomly(g.cfg.TemplatePairs, prof.ChainSteps, rng)
+	if err != nil {
+		return Instance{}, err
+	}
+	premises, validCons, inValidCons, err := extractPremisesAndConclusions(*pair)
+	if err != nil {
+		return Instance{}, err
+	}
+	bindings, err := g.prepareBindings(rng, pair.Slots)
+	if err != nil {
+		return Instance{}, err
+	}
+	premisesInst, validInst, inValidInst := instantiateTemplatePair(premises, validCons, inValidCons, bindings)
+
+	// 绑定按槽位顺序从 p 开始取字母，这里整体随机改名；字母不够时保持原样
+	// 改名只从 prof.Letters 取随机数，生成用的 rng 与随机字母之前完全一致
+	if prof.Letters == nil {
+		return Instance{Name: pair.Name, Premises: premisesInst, Valid: validInst, Invalid: inValidInst}, nil
+	}
+	all := append(append(append([]*core.Node(nil), premisesInst...), validInst...), inValidInst...)
+	if mapping, err := core.RandomRenaming(prof.Letters, core.Vars(all...), surfaceVars); err == nil {
+		premisesInst = core.RenameAll(premisesInst, mapping)
+		validInst = core.RenameAll(validInst, mapping)
+		inValidInst = core.RenameAll(inValidInst, mapping)
+	}
+	return Instance{Name: pair.Name, Premises: premisesInst, Valid: validInst, Invalid: inValidInst}, nil
+}
+
 // stringifyInstances 将前提和结论实例化后的 AST 转换为字符串表示。
 func stringifyInstances(premises []*core.Node, validCons []*core.Node, inValidCons []*core.Node) (string, []string, []string) {
 	premiseStrs := make([]string, 0, len(premises))
@@ -222,5 +268,6 @@ func collectVars(nodes []*core.Node) []string {
 	for v := range varSet {
 		vars = append(vars, v)
 	}
+	sort.Strings(vars)
 	return vars
 }
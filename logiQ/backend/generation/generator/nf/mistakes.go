@@ -0,0 +1,288 @@
+package nf
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+	"backend/generation/normalform"
+	"fmt"
+)
+
+// form 表示题目考察的范式：CNF 的子句是析取、外层是合取；DNF 相反
+type form int
+
+const (
+	formCNF form = iota
+	formDNF
+)
+
+func (f form) String() string {
+	if f == formCNF {
+		return "CNF"
+	}
+	return "DNF"
+}
+
+// render 按该范式渲染子句集合，子句集合不经过规范化，保留冗余或错误的子句
+func (f form) render(clauses []normalform.Clause) string {
+	if f == formCNF {
+		return normalform.CNF(clauses).String()
+	}
+	return normalform.DNF(clauses).String()
+}
+
+func (f form) node(clauses []normalform.Clause) *core.Node {
+	if f == formCNF {
+		return normalform.CNF(clauses).Node()
+	}
+	return normalform.DNF(clauses).Node()
+}
+
+func (f form) convert(node *core.Node, limits normalform.Limits) ([]normalform.Clause, error) {
+	if f == formCNF {
+		return normalform.ToCNF(node, limits)
+	}
+	return normalform.ToDNF(node, limits)
+}
+
+func (f form) fromTruthTable(node *core.Node, vars []string, limits normalform.Limits) ([]normalform.Clause, error) {
+	if f == formCNF {
+		return normalform.CNFFromTruthTable(node, vars, limits)
+	}
+	return normalform.DNFFromTruthTable(node, vars, limits)
+}
+
+// variant 为一个候选范式及其来历（正确候选）或错误原因（干扰项）
+type variant struct {
+	clauses []normalform.Clause
+	note    string
+}
+
+// correctVariants 生成与 F 等价的同一范式的不同写法：
+//   - 分配律展开并化简后的最简形式
+//   - 由真值表得到的主范式（每行一个极大项 / 极小项）
+//   - 最简形式加上一个冗余子句：被吸收的子句、两个子句的消解式（DNF 中为共识项），
+//     或 p ∨ ¬p（DNF 中为 p ∧ ¬p）这样不影响取值的子句
+func correctVariants(f form, formula *core.Node, minimal []normalform.Clause, vars []string, limits normalform.Limits) []variant {
+	out := []variant{{clauses: minimal, note: fmt.Sprintf("it is the %s obtained by rewriting → / ↔ / ⊕ / ↑ / ↓, pushing ¬ inward and distributing", f)}}
+
+	if canonical, err := f.fromTruthTable(formula, vars, limits); err == nil {
+		rows := "false"
+		term := "maxterm"
+		if f == formDNF {
+			rows, term = "true", "minterm"
+		}
+		out = append(out, variant{clauses: canonical, note: fmt.Sprintf("it is the canonical %s with one %s for every row where the formula is %s", f, term, rows)})
+	}
+
+	withExtra := func(extra normalform.Clause, note string) {
+		clauses := append(append([]normalform.Clause(nil), minimal...), extra)
+		out = append(out, variant{clauses: clauses, note: note})
+	}
+
+	for _, c := range minimal {
+		if extra, ok := extendClause(c, vars); ok {
+			withExtra(extra, fmt.Sprintf("the extra clause %s is absorbed by %s", f.render([]normalform.Clause{extra}), f.render([]normalform.Clause{c})))
+			break
+		}
+	}
+
+	if a, b, extra, ok := findResolvent(minimal); ok {
+		name := "resolvent"
+		if f == formDNF {
+			name = "consensus"
+		}
+		withExtra(extra, fmt.Sprintf("the extra clause %s is the %s of %s and %s", f.render([]normalform.Clause{extra}), name, f.render([]normalform.Clause{a}), f.render([]normalform.Clause{b})))
+	}
+
+	if len(vars) > 0 {
+		extra := normalform.Clause{{Var: vars[0]}, {Var: vars[0], Negated: true}}
+		value := "true"
+		if f == formDNF {
+			value = "false"
+		}
+		withExtra(extra, fmt.Sprintf("the extra clause %s is always %s", f.render([]normalform.Clause{extra}), value))
+	}
+	return out
+}
+
+// extendClause 给子句加上一个未出现的变量，得到被原子句吸收的冗余子句
+func extendClause(c normalform.Clause, vars []string) (normalform.Clause, bool) {
+	present := make(map[string]struct{}, len(c))
+	for _, l := range c {
+		present[l.Var] = struct{}{}
+	}
+	for _, name := range vars {
+		if _, ok := present[name]; !ok {
+			return append(append(normalform.Clause(nil), c...), normalform.Literal{Var: name}).Sorted(), true
+		}
+	}
+	return nil, false
+}
+
+// findResolvent 找到两个恰好在一个变量上互补的子句，返回它们及其消解式
+// 消解式不能与已有子句相同，否则加上它并不改变写法
+func findResolvent(clauses []normalform.Clause) (normalform.Clause, normalform.Clause, normalform.Clause, bool) {
+	existing := make(map[string]struct{}, len(clauses))
+	for _, c := range clauses {
+		existing[normalform.CNF{c}.String()] = struct{}{}
+	}
+	for i, a := range clauses {
+		for _, b := range clauses[i+1:] {
+			resolvent, ok := resolve(a, b)
+			if !ok {
+				continue
+			}
+			if _, dup := existing[normalform.CNF{resolvent}.String()]; dup {
+				continue
+			}
+			return a, b, resolvent, true
+		}
+	}
+	return nil, nil, nil, false
+}
+
+// resolve 两个子句恰好有一对互补文字时，去掉这对文字后合并其余文字
+func resolve(a, b normalform.Clause) (normalform.Clause, bool) {
+	pivot := ""
+	for _, la := range a {
+		for _, lb := range b {
+			if la.Var == lb.Var && la.Negated != lb.Negated {
+				if pivot != "" {
+					// 多于一对互补文字时消解式恒真
+					return nil, false
+				}
+				pivot = la.Var
+			}
+		}
+	}
+	if pivot == "" {
+		return nil, false
+	}
+	out := make(normalform.Clause, 0, len(a)+len(b)-2)
+	seen := make(map[normalform.Literal]struct{})
+	for _, l := range append(append(normalform.Clause(nil), a...), b...) {
+		if _, dup := seen[l]; dup || l.Var == pivot {
+			continue
+		}
+		seen[l] = struct{}{}
+		out = append(out, l)
+	}
+	if len(out) == 0 {
+		return nil, false
+	}
+	return out.Sorted(), true
+}
+
+// distractorVariants 生成看起来像 F 的范式、但转换过程中犯了一步错误的候选：
+//   - 德摩根律用错：¬(A ∧ B) 写成 ¬A ∧ ¬B（或 ¬(A ∨ B) 写成 ¬A ∨ ¬B）
+//   - 漏掉一个子句
+//   - 分配律方向用反：把另一种范式的子句按本范式的联结词拼接
+//   - 某个文字的符号写反
+func distractorVariants(f form, formula *core.Node, minimal, dual []normalform.Clause, limits normalform.Limits) []variant {
+	out := make([]variant, 0)
+
+	for site := 0; ; site++ {
+		wrong, desc, ok := wrongDeMorgan(formula, site)
+		if !ok {
+			break
+		}
+		if clauses, err := f.convert(wrong, limits); err == nil {
+			out = append(out, variant{clauses: clauses, note: "wrong De Morgan step: " + desc})
+		}
+	}
+
+	if len(minimal) > 1 {
+		for i := range minimal {
+			clauses := make([]normalform.Clause, 0, len(minimal)-1)
+			clauses = append(append(clauses, minimal[:i]...), minimal[i+1:]...)
+			out = append(out, variant{clauses: clauses, note: fmt.Sprintf("the clause %s was dropped", f.render(minimal[i:i+1]))})
+		}
+	}
+
+	// 另一种范式的子句按本范式解释，相当于分配律的方向用反了
+	out = append(out, variant{clauses: dual, note: fmt.Sprintf("it distributes the wrong way: these are the clauses of the %s joined as a %s", 1-f, f)})
+
+	for i, c := range minimal {
+		for j := range c {
+			flipped := append(normalform.Clause(nil), c...)
+			flipped[j].Negated = !flipped[j].Negated
+			clauses := append([]normalform.Clause(nil), minimal...)
+			clauses[i] = flipped
+			out = append(out, variant{clauses: clauses, note: fmt.Sprintf("the literal %s has the wrong sign", c[j])})
+		}
+	}
+	return out
+}
+
+// wrongDeMorgan 在第 site 个被否定的 ∧ / ∨ 处只把否定分配到两边而不对换联结词，
+// 其余位置正常转换为 NNF；site 超出范围时返回 false
+func wrongDeMorgan(node *core.Node, site int) (*core.Node, string, bool) {
+	counter := site
+	var desc string
+	out := faultyNNF(node, false, &counter, &desc)
+	return out, desc, counter < 0
+}
+
+func faultyNNF(node *core.Node, negate bool, counter *int, desc *string) *core.Node {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case core.Var:
+		return normalform.Literal{Var: node.Name, Negated: negate}.Node()
+	case core.True, core.False:
+		return normalform.NNF(negated(node, negate))
+	case core.Not:
+		return faultyNNF(node.Left, !negate, counter, desc)
+	case core.Xor:
+		// A ⊕ B ≡ ¬(A ↔ B)
+		return faultyNNF(&core.Node{Kind: core.Iff, Left: node.Left, Right: node.Right}, !negate, counter, desc)
+	case core.Nand, core.Nor:
+		// A ↑ B ≡ ¬(A ∧ B)，A ↓ B ≡ ¬(A ∨ B)：在展开后的 ∧ / ∨ 上同样可能用错德摩根律
+		kind := core.And
+		if node.Kind == core.Nor {
+			kind = core.Or
+		}
+		return faultyNNF(&core.Node{Kind: kind, Left: node.Left, Right: node.Right}, !negate, counter, desc)
+	case core.And, core.Or:
+		kind := node.Kind
+		if negate {
+			if *counter == 0 {
+				// 在这里犯错：保留原联结词
+				*desc = fmt.Sprintf("%s rewritten as %s", helper.Stringify(&core.Node{Kind: core.Not, Left: node}), helper.Stringify(&core.Node{
+					Kind:  kind,
+					Left:  &core.Node{Kind: core.Not, Left: node.Left},
+					Right: &core.Node{Kind: core.Not, Left: node.Right},
+				}))
+			} else if kind == core.And {
+				kind = core.Or
+			} else {
+				kind = core.And
+			}
+			*counter--
+		}
+		return &core.Node{Kind: kind, Left: faultyNNF(node.Left, negate, counter, desc), Right: faultyNNF(node.Right, negate, counter, desc)}
+	case core.Impl:
+		if negate {
+			return &core.Node{Kind: core.And, Left: faultyNNF(node.Left, false, counter, desc), Right: faultyNNF(node.Right, true, counter, desc)}
+		}
+		return &core.Node{Kind: core.Or, Left: faultyNNF(node.Left, true, counter, desc), Right: faultyNNF(node.Right, false, counter, desc)}
+	case core.Iff:
+		// 与 normalform.NNF 相同的展开，左右子式各出现两次；只在第一份副本中计数，避免同一处错误被计为两个位置
+		left, right := faultyNNF(node.Left, !negate, counter, desc), faultyNNF(node.Right, false, counter, desc)
+		return &core.Node{
+			Kind:  core.And,
+			Left:  &core.Node{Kind: core.Or, Left: left, Right: right},
+			Right: &core.Node{Kind: core.Or, Left: normalform.NNF(negated(node.Left, negate)), Right: normalform.NNF(negated(node.Right, true))},
+		}
+	default:
+		return node.Clone()
+	}
+}
+
+func negated(node *core.Node, negate bool) *core.Node {
+	if negate {
+		return &core.Node{Kind: core.Not, Left: node}
+	}
+	return node
+}
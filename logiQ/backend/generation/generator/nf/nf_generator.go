@@ -0,0 +1,186 @@
+package nf
+
+import (
+	"backend/generation/builder/choice"
+	"backend/generation/config"
+	"backend/generation/core"
+	"backend/generation/generator/shared"
+	"backend/generation/helper"
+	"backend/generation/normalform"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"math/rand/v2"
+)
+
+type NormalFormGenerator struct {
+	validator validator.Validator
+	cfg       config.NormalFormConfig
+}
+
+func NewNormalFormGenerator(v validator.Validator, cfg config.NormalFormConfig) NormalFormGenerator {
+	return NormalFormGenerator{validator: v, cfg: cfg}
+}
+
+// Generate 生成范式转换题：随机公式 F，以及 F 的若干种正确 CNF / DNF 写法和转换出错的干扰项
+func (g NormalFormGenerator) Generate(rng *rand.Rand, prof sampler.Profile, plan sampler.Plan) (core.CandidatePools, map[string]any, error) {
+	if rng == nil {
+		return core.CandidatePools{}, nil, shared.ErrRngRequired
+	}
+	limits := g.limits(plan.Difficulty)
+	vars := shared.CanonicalVars(prof.Vars)
+	attempts := 0
+	for {
+		if attempts >= shared.MAX_ATTEMPTS {
+			return core.CandidatePools{}, nil, shared.ErrGenerationBudgetExceeded
+		}
+		attempts++
+
+		// 1. 随机生成公式 F；F 本身已是范式时题目没有意义
+		formula := shared.RandomFormula(rng, prof)
+		if isNormalForm(formula, core.And, core.Or) || isNormalForm(formula, core.Or, core.And) {
+			continue
+		}
+		usedVars := shared.FilterVars(vars, formula)
+
+		// 2. 最简 CNF / DNF，超过规模上限或为常量时重新生成
+		cnf, err := normalform.ToCNF(formula, limits)
+		if err != nil || cnf.IsConstant() {
+			continue
+		}
+		dnf, err := normalform.ToDNF(formula, limits)
+		if err != nil || dnf.IsConstant() {
+			continue
+		}
+
+		// 3. 构造正确候选与干扰项，逐个用 validator 确认
+		nfPools := core.NormalFormPools{
+			Formula:         formula,
+			Vars:            usedVars,
+			CNFNotes:        make(map[string]string),
+			DNFNotes:        make(map[string]string),
+			Counterexamples: make(map[string]string),
+		}
+		nfPools.CNFPool, nfPools.CNFDistractors = g.buildPools(formCNF, formula, cnf, dnf, usedVars, limits, nfPools.CNFNotes, nfPools.Counterexamples)
+		nfPools.DNFPool, nfPools.DNFDistractors = g.buildPools(formDNF, formula, dnf, cnf, usedVars, limits, nfPools.DNFNotes, nfPools.Counterexamples)
+
+		// 4. 根据 plan.Intent / plan.QType 确认是否满足正确/干扰项数量需求
+		if !g.isPlanFeasible(plan, nfPools) {
+			continue
+		}
+
+		pools := core.CandidatePools{
+			NormalForm: &nfPools,
+		}
+		hints := map[string]any{
+			"cnf_candidates": len(nfPools.CNFPool) + len(nfPools.CNFDistractors),
+			"dnf_candidates": len(nfPools.DNFPool) + len(nfPools.DNFDistractors),
+		}
+		return pools, hints, nil
+	}
+}
+
+// limits 返回该难度下正确答案的规模上限，未配置时使用 normalform.DefaultLimits
+func (g NormalFormGenerator) limits(difficulty models.QuestionDifficulty) normalform.Limits {
+	limits := normalform.DefaultLimits
+	if diffCfg, ok := g.cfg.Difficulty[difficulty]; ok {
+		if diffCfg.MaxClauses > 0 {
+			limits.MaxClauses = diffCfg.MaxClauses
+		}
+		if diffCfg.MaxLiterals > 0 {
+			limits.MaxLiterals = diffCfg.MaxLiterals
+		}
+	}
+	return limits
+}
+
+// buildPools 渲染并校验某一范式的候选
+// 正确候选必须与 F 等价；干扰项必须不等价，并记录一个取值不同的赋值
+// notes 记录每个候选的来历或错误原因，counterexamples 记录干扰项的反例
+func (g NormalFormGenerator) buildPools(f form, formula *core.Node, minimal, dual []normalform.Clause, vars []string, limits normalform.Limits, notes, counterexamples map[string]string) ([]string, []string) {
+	seen := make(map[string]struct{})
+	accept := func(clauses []normalform.Clause) (string, bool) {
+		if normalform.CNF(clauses).IsConstant() {
+			return "", false
+		}
+		s := f.render(clauses)
+		if _, dup := seen[s]; dup || len(s) > shared.MAX_EXPR_LENGTH {
+			return "", false
+		}
+		seen[s] = struct{}{}
+		return s, true
+	}
+
+	correct := make([]string, 0)
+	for _, v := range correctVariants(f, formula, minimal, vars, limits) {
+		s, ok := accept(v.clauses)
+		if !ok || !g.validator.Equivalent(formula, f.node(v.clauses), vars) {
+			continue
+		}
+		correct = append(correct, s)
+		notes[s] = v.note
+	}
+
+	distractors := make([]string, 0)
+	for _, v := range distractorVariants(f, formula, minimal, dual, limits) {
+		s, ok := accept(v.clauses)
+		if !ok {
+			continue
+		}
+		assign, differ := g.validator.EquivalenceCounterexample(formula, f.node(v.clauses), vars)
+		if !differ {
+			continue
+		}
+		distractors = append(distractors, s)
+		notes[s] = v.note
+		counterexamples[s] = helper.AssignmentStringify(vars, assign)
+	}
+	return correct, distractors
+}
+
+// isNormalForm 判断 node 是否已经是 outer 连接若干 inner 子句的形式
+// CNF 对应 (And, Or)，DNF 对应 (Or, And)
+func isNormalForm(node *core.Node, outer, inner core.NodeKind) bool {
+	if isClause(node, inner) {
+		return true
+	}
+	return node.Kind == outer && isNormalForm(node.Left, outer, inner) && isNormalForm(node.Right, outer, inner)
+}
+
+func isClause(node *core.Node, inner core.NodeKind) bool {
+	switch node.Kind {
+	case core.Var:
+		return true
+	case core.Not:
+		return node.Left.Kind == core.Var
+	case inner:
+		return isClause(node.Left, inner) && isClause(node.Right, inner)
+	default:
+		return false
+	}
+}
+
+// isPlanFeasible 检查是否满足计划要求
+func (NormalFormGenerator) isPlanFeasible(plan sampler.Plan, pools core.NormalFormPools) bool {
+	var correct, distractors []string
+	switch plan.Intent {
+	case "NF_CNF":
+		correct, distractors = pools.CNFPool, pools.CNFDistractors
+	case "NF_DNF":
+		correct, distractors = pools.DNFPool, pools.DNFDistractors
+	case "NF_PAIR_TF":
+		return len(pools.CNFDistractors)+len(pools.DNFDistractors) > 0
+	default:
+		return false
+	}
+
+	switch plan.QType {
+	case models.QuestionTypeSingleChoice:
+		return len(correct) >= 1 && len(distractors) >= 3
+	case models.QuestionTypeMultipleChoice:
+		k, rest := choice.OptionCounts(plan)
+		return len(correct) >= k && len(distractors) >= rest
+	default:
+		return false
+	}
+}
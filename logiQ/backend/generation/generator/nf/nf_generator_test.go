@@ -0,0 +1,158 @@
+package nf
+
+import (
+	"backend/generation/core"
+	"backend/generation/gentest"
+	"backend/generation/helper"
+	"backend/generation/parser"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"math/rand/v2"
+	"testing"
+)
+
+var prof = sampler.Profile{
+	Vars:       3,
+	MaxDepth:   3,
+	AllowedOps: []core.NodeKind{core.Not, core.And, core.Or, core.Impl, core.Iff},
+}
+
+func newGenerator(t *testing.T) NormalFormGenerator {
+	t.Helper()
+	return NewNormalFormGenerator(validator.NewBitsetValidator(), gentest.Config(t).NormalForm)
+}
+
+func TestGenerate(t *testing.T) {
+	g := newGenerator(t)
+	v := validator.NewDefaultValidator()
+	plans := []sampler.Plan{
+		{Difficulty: models.QuestionDifficultyMedium, QType: models.QuestionTypeSingleChoice, Intent: "NF_CNF"},
+		{Difficulty: models.QuestionDifficultyMedium, QType: models.QuestionTypeMultipleChoice, Intent: "NF_DNF", MCCorrectCount: 2},
+		{Difficulty: models.QuestionDifficultyMedium, QType: models.QuestionTypeTrueFalse, Intent: "NF_PAIR_TF"},
+	}
+	for i, plan := range plans {
+		pools, _, err := g.Generate(rand.New(rand.NewPCG(uint64(i), 7)), prof, plan)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", plan.Intent, err)
+		}
+		nf := pools.NormalForm
+		if nf == nil {
+			t.Fatalf("%s: expected normal form pools, got nil", plan.Intent)
+		}
+		t.Logf("F = %s", helper.Stringify(nf.Formula))
+
+		check := func(pool []string, notes map[string]string, wantEquiv bool) {
+			for _, s := range pool {
+				node, err := parser.Parse(s)
+				if err != nil {
+					t.Fatalf("candidate %q does not parse: %v", s, err)
+				}
+				if got := v.Equivalent(nf.Formula, node, nf.Vars); got != wantEquiv {
+					t.Errorf("candidate %s: equivalent = %v, want %v", s, got, wantEquiv)
+				}
+				if notes[s] == "" {
+					t.Errorf("candidate %s has no note", s)
+				}
+				if !wantEquiv && nf.Counterexamples[s] == "" {
+					t.Errorf("distractor %s has no counterexample", s)
+				}
+				t.Logf("  %v %s: %s", wantEquiv, s, notes[s])
+			}
+		}
+		check(nf.CNFPool, nf.CNFNotes, true)
+		check(nf.CNFDistractors, nf.CNFNotes, false)
+		check(nf.DNFPool, nf.DNFNotes, true)
+		check(nf.DNFDistractors, nf.DNFNotes, false)
+	}
+}
+
+func TestWrongDeMorgan(t *testing.T) {
+	// ¬(p ∧ q) ∨ r 只有一个被否定的 ∧
+	formula := &core.Node{
+		Kind: core.Or,
+		Left: &core.Node{Kind: core.Not, Left: &core.Node{
+			Kind:  core.And,
+			Left:  &core.Node{Kind: core.Var, Name: "p"},
+			Right: &core.Node{Kind: core.Var, Name: "q"},
+		}},
+		Right: &core.Node{Kind: core.Var, Name: "r"},
+	}
+	wrong, desc, ok := wrongDeMorgan(formula, 0)
+	if !ok {
+		t.Fatal("expected a De Morgan site")
+	}
+	if got, want := helper.Stringify(wrong), "(¬p ∧ ¬q) ∨ r"; got != want {
+		t.Errorf("wrong De Morgan = %s, want %s", got, want)
+	}
+	if want := "¬(p ∧ q) rewritten as ¬p ∧ ¬q"; desc != want {
+		t.Errorf("desc = %q, want %q", desc, want)
+	}
+	if _, _, ok := wrongDeMorgan(formula, 1); ok {
+		t.Error("expected no second De Morgan site")
+	}
+}
+
+func TestWrongDeMorganExtendedConnectives(t *testing.T) {
+	// p ↑ q 展开为 ¬(p ∧ q)，p ⊕ q 中没有被否定的 ∧ / ∨
+	nand, _ := parser.Parse("p ↑ q")
+	wrong, desc, ok := wrongDeMorgan(nand, 0)
+	if !ok || helper.Stringify(wrong) != "¬p ∧ ¬q" || desc != "¬(p ∧ q) rewritten as ¬p ∧ ¬q" {
+		t.Errorf("wrong De Morgan of p ↑ q = %s (%q, %v)", helper.Stringify(wrong), desc, ok)
+	}
+	xor, _ := parser.Parse("p ⊕ ⊤")
+	if _, _, ok := wrongDeMorgan(xor, 0); ok {
+		t.Error("expected no De Morgan site in p ⊕ ⊤")
+	}
+}
+
+func TestGenerateExtendedConnectives(t *testing.T) {
+	g := newGenerator(t)
+	v := validator.NewDefaultValidator()
+	extended := sampler.Profile{
+		Vars:       3,
+		MaxDepth:   3,
+		AllowedOps: []core.NodeKind{core.Not, core.And, core.Or, core.Xor, core.Nand, core.Nor, core.True, core.False},
+	}
+	plan := sampler.Plan{Difficulty: models.QuestionDifficultyHard, QType: models.QuestionTypeSingleChoice, Intent: "NF_CNF"}
+	for seed := uint64(0); seed < 16; seed++ {
+		pools, _, err := g.Generate(rand.New(rand.NewPCG(seed, 20)), extended, plan)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		nf := pools.NormalForm
+		for _, s := range nf.CNFPool {
+			node, err := parser.Parse(s)
+			if err != nil || !v.Equivalent(nf.Formula, node, nf.Vars) {
+				t.Errorf("F = %s: CNF candidate %s is not equivalent (err %v)", helper.Stringify(nf.Formula), s, err)
+			}
+		}
+	}
+}
+
+func TestIsNormalForm(t *testing.T) {
+	cases := []struct {
+		formula  string
+		cnf, dnf bool
+	}{
+		{"(p ∨ ¬q) ∧ r", true, false},
+		{"(p ∧ ¬q) ∨ r", false, true},
+		{"p ∨ q", true, true},
+		{"¬(p ∨ q)", false, false},
+		{"p → q", false, false},
+		{"p ⊕ q", false, false},
+		{"p ∧ ⊤", false, false},
+	}
+	for _, c := range cases {
+		node, err := parser.Parse(c.formula)
+		if err != nil {
+			t.Fatalf("parse %s: %v", c.formula, err)
+		}
+		if got := isNormalForm(node, core.And, core.Or); got != c.cnf {
+			t.Errorf("%s: CNF = %v, want %v", c.formula, got, c.cnf)
+		}
+		if got := isNormalForm(node, core.Or, core.And); got != c.dnf {
+			t.Errorf("%s: DNF = %v, want %v", c.formula, got, c.dnf)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package prf
+
+import (
+	"backend/generation/config"
+	"backend/generation/core"
+	"backend/generation/generator/inf"
+	"backend/generation/generator/shared"
+	"backend/generation/helper"
+	"backend/generation/proof"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// maxLines 证明的行数上限，包括前提
+const maxLines = 12
+
+type ProofGenerator struct {
+	templates inf.InferenceGenerator
+}
+
+// NewProofGenerator 复用推理题的模板对；模板实例化不做等价变换，保证能用推理规则逐行证明
+func NewProofGenerator(v validator.Validator, cfg config.InferenceConfig) ProofGenerator {
+	return ProofGenerator{templates: inf.NewInferenceGenerator(v, cfg)}
+}
+
+// Generate 生成证明步骤题：实例化一个推理模板，从前提证明其结论，再选一行推导行提问
+func (g ProofGenerator) Generate(rng *rand.Rand, prof sampler.Profile, plan sampler.Plan) (core.CandidatePools, map[string]any, error) {
+	if rng == nil {
+		return core.CandidatePools{}, nil, shared.ErrRngRequired
+	}
+	attempts := 0
+	for {
+		if attempts >= shared.MAX_ATTEMPTS {
+			return core.CandidatePools{}, nil, shared.ErrGenerationBudgetExceeded
+		}
+		attempts++
+
+		inst, err := g.templates.Instantiate(rng, prof.InfProfile)
+		if err != nil {
+			return core.CandidatePools{}, nil, err
+		}
+
+		// 1. 证明每个有效结论，取最长的证明；证明必须通过检查
+		p, ok := longestProof(inst)
+		if !ok || p.Check(inst.Premises) != nil {
+			continue
+		}
+
+		// 2. 随机选一行推导行提问
+		derived := make([]int, 0, len(p.Lines))
+		for i, l := range p.Lines {
+			if l.Rule != proof.Premise {
+				derived = append(derived, i)
+			}
+		}
+		idx := derived[rng.IntN(len(derived))]
+
+		// 3. 构造规则 / 公式的正确项与干扰项
+		prfPools := buildPools(p, idx, inst)
+		prfPools.TemplateName = inst.Name
+		prfPools.Listing = listing(p, idx, plan.Intent)
+
+		if !isPlanFeasible(plan, prfPools) {
+			continue
+		}
+
+		pools := core.CandidatePools{Proof: &prfPools}
+		hints := map[string]any{
+			"lines": len(p.Lines),
+			"line":  prfPools.Line,
+		}
+		return pools, hints, nil
+	}
+}
+
+// longestProof 对每个有效结论尝试证明，返回行数最多的证明；任一行过长时放弃该证明
+func longestProof(inst inf.Instance) (proof.Proof, bool) {
+	var best proof.Proof
+	found := false
+	for _, goal := range inst.Valid {
+		p, ok := proof.Prove(inst.Premises, goal, maxLines)
+		if !ok || !fitsLength(p) {
+			continue
+		}
+		if !found || len(p.Lines) > len(best.Lines) {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+func fitsLength(p proof.Proof) bool {
+	for _, l := range p.Lines {
+		if len(helper.Stringify(l.Formula)) > shared.MAX_EXPR_LENGTH {
+			return false
+		}
+	}
+	return true
+}
+
+// buildPools 把第 idx 行的规则或公式替换成候选，由检查器判定对错，并记录拒绝理由
+func buildPools(p proof.Proof, idx int, inst inf.Instance) core.ProofPools {
+	line := p.Lines[idx]
+	pools := core.ProofPools{
+		Premises:     helper.StringifyList(inst.Premises),
+		Line:         line.Number,
+		Rule:         string(line.Rule),
+		Formula:      helper.Stringify(line.Formula),
+		CorrectSteps: []string{line.String()},
+		Notes:        make(map[string]string),
+	}
+
+	// 规则干扰项：换成其余任一规则后检查不通过
+	for _, rule := range proof.Rules {
+		if rule == line.Rule {
+			continue
+		}
+		candidate := line
+		candidate.Rule = rule
+		if reason, wrong := rejects(p, idx, candidate); wrong {
+			pools.RuleDistractors = append(pools.RuleDistractors, string(rule))
+			pools.Notes[string(rule)] = reason
+			pools.WrongSteps = append(pools.WrongSteps, candidate.String())
+			pools.Notes[candidate.String()] = reason
+		}
+	}
+
+	// 公式干扰项：模板的无效结论、证明中的其他公式以及正确公式的常见变形
+	seen := map[string]bool{pools.Formula: true}
+	for _, formula := range formulaCandidates(p, idx, inst) {
+		s := helper.Stringify(formula)
+		if seen[s] || len(s) > shared.MAX_EXPR_LENGTH {
+			continue
+		}
+		seen[s] = true
+		candidate := line
+		candidate.Formula = formula
+		if reason, wrong := rejects(p, idx, candidate); wrong {
+			pools.FormulaDistractors = append(pools.FormulaDistractors, s)
+			pools.Notes[s] = reason
+			pools.WrongSteps = append(pools.WrongSteps, candidate.String())
+			pools.Notes[candidate.String()] = reason
+		}
+	}
+	return pools
+}
+
+// formulaCandidates 第 idx 行公式的干扰候选，按可信度排列
+func formulaCandidates(p proof.Proof, idx int, inst inf.Instance) []*core.Node {
+	correct := p.Lines[idx].Formula
+	out := make([]*core.Node, 0, len(inst.Invalid)+len(p.Lines)+3)
+	out = append(out, shared.Unary(core.Not, correct.Clone()))
+	switch correct.Kind {
+	case core.Not:
+		out = append(out, correct.Left.Clone())
+	case core.Impl, core.Iff, core.And, core.Or:
+		// 交换左右：对蕴含式是逆命题；其余联结词交换后仍等价，但不是规则给出的写法
+		out = append(out, shared.Binary(correct.Kind, correct.Right.Clone(), correct.Left.Clone()))
+	}
+	out = append(out, inst.Invalid...)
+	for i, l := range p.Lines {
+		if i != idx {
+			out = append(out, l.Formula)
+		}
+	}
+	return out
+}
+
+// rejects 用 candidate 替换第 idx 行后检查该行，返回检查器给出的理由
+func rejects(p proof.Proof, idx int, candidate proof.Line) (string, bool) {
+	lines := append([]proof.Line(nil), p.Lines...)
+	lines[idx] = candidate
+	err := proof.Proof{Lines: lines}.CheckLine(idx)
+	if err == nil {
+		return "", false
+	}
+	var checkErr *proof.CheckError
+	if errors.As(err, &checkErr) {
+		return checkErr.Reason, true
+	}
+	return err.Error(), true
+}
+
+// listing 渲染题干中的证明：规则题隐去规则，公式题隐去公式，判断题只列出被问行之前的行
+func listing(p proof.Proof, idx int, intent string) string {
+	lines := make([]string, 0, len(p.Lines))
+	for i, l := range p.Lines {
+		if i != idx {
+			if intent == "PRF_STEP_TF" && i > idx {
+				break
+			}
+			lines = append(lines, l.String())
+			continue
+		}
+		switch intent {
+		case "PRF_RULE":
+			masked := l
+			masked.Rule = "?"
+			lines = append(lines, masked.String())
+		case "PRF_FORMULA":
+			lines = append(lines, fmt.Sprintf("%d. ? (%s)", l.Number, l.Justification()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isPlanFeasible 检查是否满足计划要求
+func isPlanFeasible(plan sampler.Plan, pools core.ProofPools) bool {
+	switch plan.Intent {
+	case "PRF_STEP_TF":
+		return plan.QType == models.QuestionTypeTrueFalse && len(pools.WrongSteps) > 0
+	case "PRF_RULE":
+		return plan.QType == models.QuestionTypeSingleChoice && len(pools.RuleDistractors) >= 3
+	case "PRF_FORMULA":
+		return plan.QType == models.QuestionTypeSingleChoice && len(pools.FormulaDistractors) >= 3
+	default:
+		return false
+	}
+}
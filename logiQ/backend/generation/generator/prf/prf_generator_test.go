@@ -0,0 +1,168 @@
+package prf
+
+import (
+	"backend/generation/core"
+	"backend/generation/gentest"
+	"backend/generation/proof"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newGenerator(t *testing.T) ProofGenerator {
+	t.Helper()
+	return NewProofGenerator(validator.NewDefaultValidator(), gentest.Config(t).Inference)
+}
+
+func TestGenerateEveryIntent(t *testing.T) {
+	plans := []sampler.Plan{
+		{QType: models.QuestionTypeSingleChoice, Intent: "PRF_RULE"},
+		{QType: models.QuestionTypeSingleChoice, Intent: "PRF_FORMULA"},
+		{QType: models.QuestionTypeTrueFalse, Intent: "PRF_STEP_TF"},
+	}
+	gentest.Run(t, newGenerator(t), plans, func(c gentest.Case, pools core.CandidatePools) {
+		p := pools.Proof
+		if !isPlanFeasible(c.Plan, *p) {
+			t.Fatalf("%s: infeasible pools returned", c)
+		}
+		if !strings.Contains(p.Listing, "1. ") {
+			t.Errorf("%s: listing lacks line 1:\n%s", c, p.Listing)
+		}
+		for _, d := range append(append(p.RuleDistractors, p.FormulaDistractors...), p.WrongSteps...) {
+			if p.Notes[d] == "" {
+				t.Errorf("%s: distractor %q has no note", c, d)
+			}
+		}
+		checkSteps(t, c, *p)
+		switch c.Plan.Intent {
+		case "PRF_RULE":
+			if !strings.Contains(p.Listing, "(?") {
+				t.Errorf("%s: rule of the asked line is not masked:\n%s", c, p.Listing)
+			}
+		case "PRF_FORMULA":
+			if !strings.Contains(p.Listing, ". ? (") {
+				t.Errorf("%s: formula of the asked line is not masked:\n%s", c, p.Listing)
+			}
+		case "PRF_STEP_TF":
+			if got := len(strings.Split(p.Listing, "\n")); got != p.Line-1 {
+				t.Errorf("%s: step listing has %d lines, want %d", c, got, p.Line-1)
+			}
+		}
+	})
+}
+
+// checkSteps 从题干还原证明，补上被问行的正确规则与公式：检查器接受正确的一步，
+// 拒绝每个规则干扰项、公式干扰项和错误步骤
+func checkSteps(t *testing.T, c gentest.Case, p core.ProofPools) {
+	t.Helper()
+	var lines []proof.Line
+	if p.Listing != "" {
+		for _, text := range strings.Split(p.Listing, "\n") {
+			lines = append(lines, parseLine(t, text))
+		}
+	}
+	idx := p.Line - 1
+	if c.Plan.Intent == "PRF_STEP_TF" {
+		lines = append(lines, parseLine(t, p.CorrectSteps[0]))
+	}
+	if idx >= len(lines) {
+		t.Fatalf("%s: line %d is missing from the listing:\n%s", c, p.Line, p.Listing)
+	}
+	lines[idx].Rule = proof.Rule(p.Rule)
+	lines[idx].Formula = gentest.MustParse(t, p.Formula)
+	check := func(candidate proof.Line) error {
+		ls := append([]proof.Line(nil), lines...)
+		ls[idx] = candidate
+		return proof.Proof{Lines: ls}.CheckLine(idx)
+	}
+
+	if err := check(lines[idx]); err != nil {
+		t.Errorf("%s: correct step %s rejected: %v", c, lines[idx], err)
+	}
+	for _, step := range p.CorrectSteps {
+		if err := check(parseLine(t, step)); err != nil {
+			t.Errorf("%s: correct step %s rejected: %v", c, step, err)
+		}
+	}
+	for _, d := range p.RuleDistractors {
+		candidate := lines[idx]
+		candidate.Rule = proof.Rule(d)
+		if check(candidate) == nil {
+			t.Errorf("%s: rule distractor %s accepted for line %d", c, d, p.Line)
+		}
+	}
+	for _, d := range p.FormulaDistractors {
+		candidate := lines[idx]
+		candidate.Formula = gentest.MustParse(t, d)
+		if check(candidate) == nil {
+			t.Errorf("%s: formula distractor %s accepted for line %d", c, d, p.Line)
+		}
+	}
+	for _, step := range p.WrongSteps {
+		if check(parseLine(t, step)) == nil {
+			t.Errorf("%s: wrong step %s accepted", c, step)
+		}
+	}
+}
+
+// parseLine 读 proof.Line.String 的输出，如 "3. q (Modus Ponens, 1, 2)"；
+// 被隐去的公式或规则 "?" 留空，由调用方补上
+func parseLine(t *testing.T, text string) proof.Line {
+	t.Helper()
+	number, rest, ok := strings.Cut(text, ". ")
+	open := strings.LastIndex(rest, " (")
+	if !ok || open < 0 || !strings.HasSuffix(rest, ")") {
+		t.Fatalf("malformed proof line %q", text)
+	}
+	var line proof.Line
+	var err error
+	if line.Number, err = strconv.Atoi(number); err != nil {
+		t.Fatalf("proof line %q: %v", text, err)
+	}
+	if formula := rest[:open]; formula != "?" {
+		line.Formula = gentest.MustParse(t, formula)
+	}
+	// 规则名中没有逗号，形如 "Modus Ponens, 1, 2"
+	name, cites, _ := strings.Cut(rest[open+2:len(rest)-1], ", ")
+	if name != "?" {
+		if line.Rule, err = proof.ParseRule(name); err != nil {
+			t.Fatalf("proof line %q: %v", text, err)
+		}
+	}
+	if cites != "" {
+		for _, s := range strings.Split(cites, ", ") {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				t.Fatalf("proof line %q: %v", text, err)
+			}
+			line.Cites = append(line.Cites, n)
+		}
+	}
+	return line
+}
+
+func TestRuleDistractorsExcludeAnswer(t *testing.T) {
+	g := newGenerator(t)
+	plan := sampler.Plan{QType: models.QuestionTypeSingleChoice, Intent: "PRF_RULE"}
+	prof := sampler.Profile{InfProfile: sampler.InfProfile{ChainSteps: 2}}
+	for seed := uint64(0); seed < 16; seed++ {
+		pools, _, err := g.Generate(rand.New(rand.NewPCG(seed, 9)), prof, plan)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		for _, d := range pools.Proof.RuleDistractors {
+			if d == pools.Proof.Rule || d == "Premise" {
+				t.Errorf("seed %d: rule distractor %q", seed, d)
+			}
+		}
+		for _, d := range pools.Proof.FormulaDistractors {
+			if d == pools.Proof.Formula {
+				t.Errorf("seed %d: formula distractor equals the answer %q", seed, d)
+			}
+		}
+	}
+}
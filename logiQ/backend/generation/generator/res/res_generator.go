@@ -0,0 +1,269 @@
+package res
+
+import (
+	"backend/generation/builder/choice"
+	"backend/generation/config"
+	"backend/generation/core"
+	"backend/generation/generator/inf"
+	"backend/generation/generator/shared"
+	"backend/generation/helper"
+	"backend/generation/normalform"
+	"backend/generation/resolution"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// maxClauses 消解搜索最多保留的子句数，包括输入子句
+const maxClauses = 48
+
+// clauseLimits 单个公式转换成子句后的规模上限，保证子句集能写进题干和选项
+var clauseLimits = normalform.Limits{MaxClauses: 6, MaxLiterals: 16}
+
+type ResolutionGenerator struct {
+	templates inf.InferenceGenerator
+	validator validator.Validator
+}
+
+// NewResolutionGenerator 复用推理题的模板对：前提加结论的否定转成子句集，有效结论对应的子句集可以消解出空子句
+func NewResolutionGenerator(v validator.Validator, cfg config.InferenceConfig) ResolutionGenerator {
+	return ResolutionGenerator{templates: inf.NewInferenceGenerator(v, cfg), validator: v}
+}
+
+// clauseSet 前提与结论否定的子句集
+type clauseSet struct {
+	conclusion *core.Node
+	clauses    normalform.CNF
+	text       string
+}
+
+// Generate 生成消解题：实例化一个推理模板，对每个有效结论求消解反驳，取最长的一个，
+// 再从中选一步询问消解结果；无效结论对应的子句集用作可满足的干扰项
+func (g ResolutionGenerator) Generate(rng *rand.Rand, prof sampler.Profile, plan sampler.Plan) (core.CandidatePools, map[string]any, error) {
+	if rng == nil {
+		return core.CandidatePools{}, nil, shared.ErrRngRequired
+	}
+	attempts := 0
+	for {
+		if attempts >= shared.MAX_ATTEMPTS {
+			return core.CandidatePools{}, nil, shared.ErrGenerationBudgetExceeded
+		}
+		attempts++
+
+		inst, err := g.templates.Instantiate(rng, prof.InfProfile)
+		if err != nil {
+			return core.CandidatePools{}, nil, err
+		}
+
+		// 1. 有效结论的子句集求反驳，取步骤最多的一个
+		resPools := core.ResolutionPools{TemplateName: inst.Name, ResolventNotes: make(map[string]string), SetNotes: make(map[string]string)}
+		var best resolution.Refutation
+		var bestSet clauseSet
+		for _, conclusion := range inst.Valid {
+			set, ok := buildClauseSet(inst.Premises, conclusion)
+			if !ok {
+				continue
+			}
+			ref, ok := addUnsatisfiable(&resPools, set.clauses)
+			if ok && len(ref.Steps) > len(best.Steps) {
+				best, bestSet = ref, set
+			}
+		}
+		if len(best.Steps) == 0 {
+			continue
+		}
+		// 只保留反驳用到的子句、或加入一个中间消解结果，子句集仍不可满足
+		addUnsatisfiable(&resPools, usedClauses(best))
+		for _, s := range best.Steps[:len(best.Steps)-1] {
+			addUnsatisfiable(&resPools, best.Clauses.And(normalform.CNF{s.Resolvent}))
+		}
+
+		// 2. 无效结论、仅前提以及删去反驳所用某个子句得到的子句集，由验证器确认可满足
+		g.addSatisfiable(&resPools, inst, best)
+
+		// 3. 从反驳中随机选一步询问消解结果
+		step := best.Steps[rng.IntN(len(best.Steps))]
+		resPools.Premises = helper.StringifyList(inst.Premises)
+		resPools.Conclusion = helper.Stringify(bestSet.conclusion)
+		resPools.Clauses = bestSet.text
+		for _, s := range best.Steps {
+			resPools.Refutation = append(resPools.Refutation, s.String())
+		}
+		resPools.Left = resolution.FormatClause(step.Left)
+		resPools.Right = resolution.FormatClause(step.Right)
+		resPools.Pivot = step.Var
+		resPools.Resolvent = resolution.FormatClause(step.Resolvent)
+		addResolventDistractors(&resPools, step, best)
+
+		if !isPlanFeasible(plan, resPools) {
+			continue
+		}
+
+		pools := core.CandidatePools{Resolution: &resPools}
+		hints := map[string]any{
+			"clauses": len(best.Clauses),
+			"steps":   len(best.Steps),
+		}
+		return pools, hints, nil
+	}
+}
+
+// buildClauseSet 前提与结论否定的子句集；子句集为常量（没有子句或含空子句）或过长时无法出题
+func buildClauseSet(premises []*core.Node, conclusion *core.Node) (clauseSet, bool) {
+	clauses, err := resolution.ClauseSet(premises, conclusion, clauseLimits)
+	if err != nil || clauses.IsConstant() {
+		return clauseSet{}, false
+	}
+	text := resolution.FormatSet(clauses)
+	if len(text) > shared.MAX_EXPR_LENGTH {
+		return clauseSet{}, false
+	}
+	return clauseSet{conclusion: conclusion, clauses: clauses, text: text}, true
+}
+
+// addUnsatisfiable 对子句集求反驳，成功时记入 UnsatSets，解析为反驳的步骤
+func addUnsatisfiable(pools *core.ResolutionPools, clauses normalform.CNF) (resolution.Refutation, bool) {
+	text := resolution.FormatSet(clauses)
+	if clauses.IsConstant() || len(text) > shared.MAX_EXPR_LENGTH {
+		return resolution.Refutation{}, false
+	}
+	ref, ok := resolution.Refute(clauses, maxClauses)
+	if !ok {
+		return resolution.Refutation{}, false
+	}
+	if _, dup := pools.SetNotes[text]; !dup {
+		pools.UnsatSets = append(pools.UnsatSets, text)
+		pools.SetNotes[text] = describeRefutation(ref)
+	}
+	return ref, true
+}
+
+// usedClauses 反驳中实际用到的输入子句
+func usedClauses(ref resolution.Refutation) normalform.CNF {
+	used := make(map[string]bool)
+	for _, s := range ref.Steps {
+		used[resolution.FormatClause(s.Left)] = true
+		used[resolution.FormatClause(s.Right)] = true
+	}
+	out := make(normalform.CNF, 0, len(ref.Clauses))
+	for _, c := range ref.Clauses {
+		if used[resolution.FormatClause(c)] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// addSatisfiable 收集可满足的子句集，并记录一个满足它的赋值
+func (g ResolutionGenerator) addSatisfiable(pools *core.ResolutionPools, inst inf.Instance, best resolution.Refutation) {
+	candidates := make([]normalform.CNF, 0, len(inst.Invalid)+len(best.Clauses)+1)
+	for _, conclusion := range inst.Invalid {
+		if set, ok := buildClauseSet(inst.Premises, conclusion); ok {
+			candidates = append(candidates, set.clauses)
+		}
+	}
+	if set, ok := buildClauseSet(inst.Premises, nil); ok {
+		candidates = append(candidates, set.clauses)
+	}
+	// 删去一个子句后通常不再能消解出空子句，与正确项最相近
+	for i := range best.Clauses {
+		rest := append(append(normalform.CNF{}, best.Clauses[:i]...), best.Clauses[i+1:]...)
+		candidates = append(candidates, rest)
+	}
+
+	for _, clauses := range candidates {
+		text := resolution.FormatSet(clauses)
+		if _, dup := pools.SetNotes[text]; dup || clauses.IsConstant() || len(text) > shared.MAX_EXPR_LENGTH {
+			continue
+		}
+		vars := clauses.Vars()
+		assign, ok := g.validator.EquivalenceCounterexample(clauses.Node(), shared.Constant(false), vars)
+		if !ok {
+			continue
+		}
+		pools.SatSets = append(pools.SatSets, text)
+		pools.SetNotes[text] = fmt.Sprintf("every clause is true under %s", helper.AssignmentStringify(vars, assign))
+	}
+}
+
+// addResolventDistractors 由常见错误构造消解结果的干扰项：保留互补文字、只删去其中一个、
+// 多删或改写其他文字，以及反驳中其他步骤的结果
+func addResolventDistractors(pools *core.ResolutionPools, step resolution.Step, ref resolution.Refutation) {
+	pos := normalform.Literal{Var: step.Var}
+	neg := normalform.Literal{Var: step.Var, Negated: true}
+	pair := fmt.Sprintf("%s and %s", pos, neg)
+	seen := map[string]bool{pools.Resolvent: true}
+	for _, r := range resolution.Resolvents(step.Left, step.Right) {
+		seen[resolution.FormatClause(r.Resolvent)] = true
+	}
+	add := func(c normalform.Clause, note string) {
+		s := resolution.FormatClause(c.Sorted())
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		pools.ResolventDistractors = append(pools.ResolventDistractors, s)
+		pools.ResolventNotes[s] = note
+	}
+
+	union := append(append(normalform.Clause{}, step.Left...), step.Right...)
+	add(dedupe(union), fmt.Sprintf("the complementary literals %s must both be removed", pair))
+	add(dedupe(union.Without(pos)), fmt.Sprintf("only %s was removed; %s must be removed as well", pos, neg))
+	add(dedupe(union.Without(neg)), fmt.Sprintf("only %s was removed; %s must be removed as well", neg, pos))
+	for _, l := range step.Resolvent {
+		add(step.Resolvent.Without(l), fmt.Sprintf("%s is not part of the complementary pair and must be kept", l))
+	}
+	for i, l := range step.Resolvent {
+		flipped := append(normalform.Clause{}, step.Resolvent...)
+		flipped[i] = normalform.Literal{Var: l.Var, Negated: !l.Negated}
+		add(flipped, fmt.Sprintf("the remaining literals are copied unchanged; %s must not become %s", l, flipped[i]))
+	}
+	for _, other := range ref.Steps {
+		if len(other.Resolvent) > 0 {
+			add(other.Resolvent, fmt.Sprintf("this is the resolvent of %s and %s", resolution.FormatClause(other.Left), resolution.FormatClause(other.Right)))
+		}
+	}
+}
+
+// describeRefutation 把反驳写成一句话，用作不可满足子句集的解析
+func describeRefutation(ref resolution.Refutation) string {
+	steps := make([]string, len(ref.Steps))
+	for i, s := range ref.Steps {
+		steps[i] = s.String()
+	}
+	return "resolution derives the empty clause: " + strings.Join(steps, "; ")
+}
+
+func dedupe(c normalform.Clause) normalform.Clause {
+	seen := make(map[normalform.Literal]bool, len(c))
+	out := make(normalform.Clause, 0, len(c))
+	for _, l := range c {
+		if !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// isPlanFeasible 检查是否满足计划要求
+func isPlanFeasible(plan sampler.Plan, pools core.ResolutionPools) bool {
+	switch plan.Intent {
+	case "RES_RESOLVENT":
+		return plan.QType == models.QuestionTypeSingleChoice && len(pools.ResolventDistractors) >= 3
+	case "RES_RESOLVENT_TF":
+		return plan.QType == models.QuestionTypeTrueFalse && len(pools.ResolventDistractors) > 0
+	case "RES_UNSAT":
+		switch plan.QType {
+		case models.QuestionTypeSingleChoice:
+			return len(pools.UnsatSets) >= 1 && len(pools.SatSets) >= 3
+		case models.QuestionTypeMultipleChoice:
+			k, rest := choice.OptionCounts(plan)
+			return len(pools.UnsatSets) >= k && len(pools.SatSets) >= rest
+		}
+	}
+	return false
+}
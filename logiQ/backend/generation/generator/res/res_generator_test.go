@@ -0,0 +1,139 @@
+package res
+
+import (
+	"backend/generation/core"
+	"backend/generation/gentest"
+	"backend/generation/normalform"
+	"backend/generation/resolution"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newGenerator(t *testing.T) ResolutionGenerator {
+	t.Helper()
+	return NewResolutionGenerator(validator.NewDefaultValidator(), gentest.Config(t).Inference)
+}
+
+// TestResolventQuestions 正确项是 Left 与 Right 的消解结果，干扰项都不是；
+// 这一步出自反驳，题干中的子句集与前提和结论否定的合取等价
+func TestResolventQuestions(t *testing.T) {
+	v := validator.NewDefaultValidator()
+	plans := []sampler.Plan{
+		{QType: models.QuestionTypeSingleChoice, Intent: "RES_RESOLVENT"},
+		{QType: models.QuestionTypeTrueFalse, Intent: "RES_RESOLVENT_TF"},
+	}
+	gentest.Run(t, newGenerator(t), plans, func(c gentest.Case, pools core.CandidatePools) {
+		r := pools.Resolution
+		resolvents := make(map[string]bool)
+		for _, s := range resolution.Resolvents(parseClause(t, r.Left), parseClause(t, r.Right)) {
+			resolvents[resolution.FormatClause(s.Resolvent)] = true
+		}
+		if !resolvents[r.Resolvent] {
+			t.Errorf("%s: %s is not a resolvent of %s and %s", c, r.Resolvent, r.Left, r.Right)
+		}
+		for _, d := range r.ResolventDistractors {
+			if resolvents[d] {
+				t.Errorf("%s: distractor %s is a resolvent of %s and %s", c, d, r.Left, r.Right)
+			}
+			if r.ResolventNotes[d] == "" {
+				t.Errorf("%s: distractor %q has no note", c, d)
+			}
+		}
+		step := fmt.Sprintf("%s, %s ⊢ %s", r.Left, r.Right, r.Resolvent)
+		if !slices.Contains(r.Refutation, step) {
+			t.Errorf("%s: step %s is not part of the refutation %v", c, step, r.Refutation)
+		}
+		if last := r.Refutation[len(r.Refutation)-1]; !strings.HasSuffix(last, "⊢ "+resolution.Empty) {
+			t.Errorf("%s: refutation ends with %q", c, last)
+		}
+
+		root := &core.Node{Kind: core.Not, Left: gentest.MustParse(t, r.Conclusion)}
+		for _, p := range gentest.MustParseList(t, r.Premises) {
+			root = &core.Node{Kind: core.And, Left: p, Right: root}
+		}
+		if !v.Equivalent(parseClauses(t, r.Clauses).Node(), root, core.Vars(root)) {
+			t.Errorf("%s: clauses %s do not match premises %s and conclusion %s", c, r.Clauses, r.Premises, r.Conclusion)
+		}
+	})
+}
+
+// TestSetsArePartitioned 由验证器确认 UnsatSets 都不可满足、SatSets 都可满足
+func TestSetsArePartitioned(t *testing.T) {
+	v := validator.NewDefaultValidator()
+	plans := []sampler.Plan{
+		{QType: models.QuestionTypeSingleChoice, Intent: "RES_UNSAT"},
+		{QType: models.QuestionTypeMultipleChoice, Intent: "RES_UNSAT", MCCorrectCount: 2},
+		{QType: models.QuestionTypeMultipleChoice, Intent: "RES_UNSAT", MCCorrectCount: 3},
+	}
+	satisfiable := func(set string) bool {
+		clauses := parseClauses(t, set)
+		_, ok := v.EquivalenceCounterexample(clauses.Node(), &core.Node{Kind: core.False}, clauses.Vars())
+		return ok
+	}
+	gentest.Run(t, newGenerator(t), plans, func(c gentest.Case, pools core.CandidatePools) {
+		r := pools.Resolution
+		for _, set := range r.UnsatSets {
+			if satisfiable(set) {
+				t.Errorf("%s: %s is listed as unsatisfiable", c, set)
+			}
+			if !strings.HasPrefix(r.SetNotes[set], "resolution derives the empty clause") {
+				t.Errorf("%s: unsatisfiable set %s explained as %q", c, set, r.SetNotes[set])
+			}
+		}
+		for _, set := range r.SatSets {
+			if !satisfiable(set) {
+				t.Errorf("%s: %s is listed as satisfiable", c, set)
+			}
+			if !strings.HasPrefix(r.SetNotes[set], "every clause is true under") {
+				t.Errorf("%s: satisfiable set %s explained as %q", c, set, r.SetNotes[set])
+			}
+		}
+		if !slices.Contains(r.UnsatSets, r.Clauses) {
+			t.Errorf("%s: refuted clause set %s missing from UnsatSets", c, r.Clauses)
+		}
+	})
+}
+
+// parseClause 读 resolution.FormatClause 的输出，如 "{p, ¬q}" 和 "□"
+func parseClause(t *testing.T, s string) normalform.Clause {
+	t.Helper()
+	if s == resolution.Empty {
+		return normalform.Clause{}
+	}
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		t.Fatalf("malformed clause %q", s)
+	}
+	var clause normalform.Clause
+	for _, n := range gentest.MustParseList(t, s[1:len(s)-1]) {
+		switch {
+		case n.Kind == core.Var:
+			clause = append(clause, normalform.Literal{Var: n.Name})
+		case n.Kind == core.Not && n.Left.Kind == core.Var:
+			clause = append(clause, normalform.Literal{Var: n.Left.Name, Negated: true})
+		default:
+			t.Fatalf("clause %q contains %v, not a literal", s, n)
+		}
+	}
+	return clause
+}
+
+// parseClauses 读 resolution.FormatSet 的输出，如 "{p, q}, {¬p}"
+func parseClauses(t *testing.T, s string) normalform.CNF {
+	t.Helper()
+	var clauses normalform.CNF
+	for i, part := range strings.Split(s, "}, {") {
+		if i > 0 {
+			part = "{" + part
+		}
+		if !strings.HasSuffix(part, "}") {
+			part += "}"
+		}
+		clauses = append(clauses, parseClause(t, part))
+	}
+	return clauses
+}
@@ -6,9 +6,14 @@ import (
 	"math/rand/v2"
 )
 
-// CanonicalVars returns a deterministic prefix of [p,q,r,s,t] sized to `count` (>=1).
+// MaxCanonicalVars is the largest variable count CanonicalVars supports.
+const MaxCanonicalVars = len(core.CanonicalVarNames)
+
+// CanonicalVars returns a deterministic prefix of core.CanonicalVarNames
+// ([p,q,...,z,a,...,o]) sized to `count` (1..MaxCanonicalVars). The first
+// five match the original [p,q,r,s,t].
 func CanonicalVars(count int) []string {
-	base := []string{"p", "q", "r", "s", "t"}
+	base := core.CanonicalVarNames[:]
 	if count <= 0 {
 		count = 1
 	}
@@ -28,11 +33,19 @@ func Unary(kind core.NodeKind, child *core.Node) *core.Node {
 	return &core.Node{Kind: kind, Left: child}
 }
 
-// Binary builds a binary node (AND / OR / IMP / IFF).
+// Binary builds a binary node (AND / OR / IMP / IFF / XOR / NAND / NOR).
 func Binary(kind core.NodeKind, left, right *core.Node) *core.Node {
 	return &core.Node{Kind: kind, Left: left, Right: right}
 }
 
+// Constant returns ⊤ for true and ⊥ for false.
+func Constant(value bool) *core.Node {
+	if value {
+		return &core.Node{Kind: core.True}
+	}
+	return &core.Node{Kind: core.False}
+}
+
 // TautologyFromVar returns a formula akin to P ∨ ¬P using the given symbol.
 func TautologyFromVar(name string) *core.Node {
 	varNode := NewVar(name)
@@ -67,6 +80,10 @@ func RandomFormula(rng *rand.Rand, prof sampler.Profile) *core.Node {
 	if needDistinct {
 		minVarCount = 2
 	}
+	// 变量较多时按配置要求公式用到更多变量，否则随机树往往只用到其中几个
+	if prof.MinVars > minVarCount {
+		minVarCount = min(prof.MinVars, len(vars))
+	}
 
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		root := generateNode(rng, allowed, prof.MaxDepth, vars)
@@ -101,8 +118,9 @@ func FilterVars(all []string, formula *core.Node) []string {
 }
 
 // generateNode recursively builds an AST using the provided operator set.
-// Leaf nodes are variables; inner nodes are chosen uniformly from the allowed
-// operators while respecting the remaining depth budget.
+// Leaf nodes are variables, or a constant when TRUE / FALSE is drawn; inner
+// nodes are chosen uniformly from the allowed operators while respecting the
+// remaining depth budget.
 func generateNode(rng *rand.Rand, ops []core.NodeKind, maxDepth int, vars []string) *core.Node {
 	if rng == nil {
 		return nil
@@ -118,10 +136,12 @@ func generateNode(rng *rand.Rand, ops []core.NodeKind, maxDepth int, vars []stri
 	switch op {
 	case core.Not:
 		return &core.Node{Kind: core.Not, Left: generateNode(rng, ops, maxDepth-1, vars)}
-	case core.And, core.Or, core.Impl, core.Iff:
+	case core.And, core.Or, core.Impl, core.Iff, core.Xor, core.Nand, core.Nor:
 		left := generateNode(rng, ops, maxDepth-1, vars)
 		right := generateNode(rng, ops, maxDepth-1, vars)
 		return &core.Node{Kind: op, Left: left, Right: right}
+	case core.True, core.False:
+		return &core.Node{Kind: op}
 	default:
 		return randomVarNode(rng, vars)
 	}
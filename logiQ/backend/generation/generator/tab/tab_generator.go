@@ -0,0 +1,209 @@
+package tab
+
+import (
+	"backend/generation/builder/choice"
+	"backend/generation/config"
+	"backend/generation/core"
+	"backend/generation/generator/inf"
+	"backend/generation/generator/shared"
+	"backend/generation/helper"
+	"backend/generation/normalform"
+	"backend/generation/sampler"
+	"backend/generation/tableau"
+	"backend/generation/validator"
+	"backend/models"
+	"fmt"
+	"math/rand/v2"
+)
+
+// maxBranches 表格的分支数上限，超过时换一个模板实例
+const maxBranches = 32
+
+type TableauGenerator struct {
+	templates inf.InferenceGenerator
+	validator validator.Validator
+}
+
+// NewTableauGenerator 复用推理题的模板对：前提加结论的否定作为表格的根，有效结论的表格全部关闭，
+// 无效结论的表格至少有一条开放分支
+func NewTableauGenerator(v validator.Validator, cfg config.InferenceConfig) TableauGenerator {
+	return TableauGenerator{templates: inf.NewInferenceGenerator(v, cfg), validator: v}
+}
+
+// Generate 生成表格题：对每个结论构造前提与结论否定的表格，记录是否关闭；
+// 再随机选一个无效结论，询问其表格中哪些分支开放
+func (g TableauGenerator) Generate(rng *rand.Rand, prof sampler.Profile, plan sampler.Plan) (core.CandidatePools, map[string]any, error) {
+	if rng == nil {
+		return core.CandidatePools{}, nil, shared.ErrRngRequired
+	}
+	attempts := 0
+	for {
+		if attempts >= shared.MAX_ATTEMPTS {
+			return core.CandidatePools{}, nil, shared.ErrGenerationBudgetExceeded
+		}
+		attempts++
+
+		inst, err := g.templates.Instantiate(rng, prof.InfProfile)
+		if err != nil {
+			return core.CandidatePools{}, nil, err
+		}
+		vars := usedVars(inst)
+		tabPools := core.TableauPools{
+			TemplateName:    inst.Name,
+			Premises:        helper.StringifyList(inst.Premises),
+			BranchNotes:     make(map[string]string),
+			ConclusionNotes: make(map[string]string),
+		}
+
+		// 1. 有效结论的表格应全部关闭，无效结论的表格应有开放分支；与预期不符的结论丢弃
+		for _, conclusion := range inst.Valid {
+			tab, err := tableau.Build(rootFormulas(inst.Premises, conclusion), maxBranches)
+			if err != nil || !tab.Closed() {
+				continue
+			}
+			s := helper.Stringify(conclusion)
+			tabPools.ClosingConclusions = append(tabPools.ClosingConclusions, s)
+			tabPools.ConclusionNotes[s] = fmt.Sprintf("all %d branches close, so %s follows from the premises", len(tab.Branches), s)
+		}
+		opened := make([]tableau.Tableau, 0, len(inst.Invalid))
+		openedConclusions := make([]*core.Node, 0, len(inst.Invalid))
+		for _, conclusion := range inst.Invalid {
+			tab, err := tableau.Build(rootFormulas(inst.Premises, conclusion), maxBranches)
+			if err != nil || tab.Closed() {
+				continue
+			}
+			s := helper.Stringify(conclusion)
+			open := tab.Open()[0]
+			tabPools.OpenConclusions = append(tabPools.OpenConclusions, s)
+			tabPools.ConclusionNotes[s] = fmt.Sprintf("the branch %s stays open, so under %s every premise is true and %s is false",
+				open, helper.AssignmentStringify(vars, open.Assignment(vars)), s)
+			opened = append(opened, tab)
+			openedConclusions = append(openedConclusions, conclusion)
+		}
+		if len(opened) == 0 {
+			continue
+		}
+
+		// 2. 随机选一个开放的表格询问开放分支
+		idx := rng.IntN(len(opened))
+		tabPools.Conclusion = helper.Stringify(openedConclusions[idx])
+		tabPools.Formulas = helper.StringifyList(opened[idx].Formulas)
+		tabPools.Branches = len(opened[idx].Branches)
+		g.addBranches(&tabPools, opened[idx], vars)
+
+		if !isPlanFeasible(plan, tabPools) {
+			continue
+		}
+
+		pools := core.CandidatePools{Tableau: &tabPools}
+		hints := map[string]any{
+			"branches": tabPools.Branches,
+			"open":     len(tabPools.OpenBranches),
+		}
+		return pools, hints, nil
+	}
+}
+
+// addBranches 开放分支为正确项；关闭的分支和把开放分支中某个文字取反得到的文字集为干扰项，
+// 后者必须使某个根公式为假，因此一定不是开放分支
+func (g TableauGenerator) addBranches(pools *core.TableauPools, tab tableau.Tableau, vars []string) {
+	seen := make(map[string]bool)
+	open := tab.Open()
+	for _, b := range open {
+		s := b.String()
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		pools.OpenBranches = append(pools.OpenBranches, s)
+		pools.BranchNotes[s] = fmt.Sprintf("no pair of complementary literals occurs on it, and every formula is true under %s",
+			helper.AssignmentStringify(vars, b.Assignment(vars)))
+	}
+	for _, b := range tab.Branches {
+		s := b.String()
+		if !b.Closed || seen[s] {
+			continue
+		}
+		seen[s] = true
+		pools.BranchDistractors = append(pools.BranchDistractors, s)
+		if b.Clash == "⊥" {
+			pools.BranchNotes[s] = "the branch closes because it contains ⊥"
+		} else {
+			pools.BranchNotes[s] = fmt.Sprintf("the branch closes because it contains %s and ¬%s", b.Clash, b.Clash)
+		}
+	}
+	for _, b := range open {
+		for i, l := range b.Literals {
+			fake := tableau.Branch{Literals: append([]normalform.Literal(nil), b.Literals...)}
+			fake.Literals[i] = normalform.Literal{Var: l.Var, Negated: !l.Negated}
+			s := fake.String()
+			if seen[s] {
+				continue
+			}
+			falsified := g.falsified(tab.Formulas, fake, vars)
+			if falsified == nil {
+				continue
+			}
+			seen[s] = true
+			pools.BranchDistractors = append(pools.BranchDistractors, s)
+			pools.BranchNotes[s] = fmt.Sprintf("no branch of the tableau has these literals, since they make %s false", helper.Stringify(falsified))
+		}
+	}
+}
+
+// falsified 返回在文字集的任意扩展下都为假的根公式，没有时返回 nil
+func (g TableauGenerator) falsified(formulas []*core.Node, b tableau.Branch, vars []string) *core.Node {
+	lits := make([]*core.Node, len(b.Literals))
+	for i, l := range b.Literals {
+		lits[i] = l.Node()
+	}
+	for _, f := range formulas {
+		if g.validator.Derivable(lits, shared.Unary(core.Not, f), vars) {
+			return f
+		}
+	}
+	return nil
+}
+
+// rootFormulas 表格的根：前提和结论的否定
+func rootFormulas(premises []*core.Node, conclusion *core.Node) []*core.Node {
+	out := append([]*core.Node(nil), premises...)
+	return append(out, shared.Unary(core.Not, conclusion))
+}
+
+// usedVars 模板实例中出现的变量，按字母顺序
+func usedVars(inst inf.Instance) []string {
+	used := make(map[string]bool)
+	for _, group := range [][]*core.Node{inst.Premises, inst.Valid, inst.Invalid} {
+		for _, n := range group {
+			for _, v := range shared.FilterVars(inf.AllVars, n) {
+				used[v] = true
+			}
+		}
+	}
+	out := make([]string, 0, len(used))
+	for _, v := range inf.AllVars {
+		if used[v] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// isPlanFeasible 检查是否满足计划要求
+func isPlanFeasible(plan sampler.Plan, pools core.TableauPools) bool {
+	switch plan.Intent {
+	case "TAB_CLOSES_TF":
+		return plan.QType == models.QuestionTypeTrueFalse && len(pools.ClosingConclusions) > 0 && len(pools.OpenConclusions) > 0
+	case "TAB_OPEN_BRANCH":
+		open, distractors := len(pools.OpenBranches), len(pools.BranchDistractors)
+		switch plan.QType {
+		case models.QuestionTypeSingleChoice:
+			return open >= 1 && distractors >= 3
+		case models.QuestionTypeMultipleChoice:
+			k, rest := choice.OptionCounts(plan)
+			return open >= k && distractors >= rest
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package tab
+
+import (
+	"backend/generation/core"
+	"backend/generation/gentest"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"backend/models"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func newGenerator(t *testing.T) TableauGenerator {
+	t.Helper()
+	return NewTableauGenerator(validator.NewDefaultValidator(), gentest.Config(t).Inference)
+}
+
+func TestGenerateEveryIntent(t *testing.T) {
+	v := validator.NewDefaultValidator()
+	plans := []sampler.Plan{
+		{QType: models.QuestionTypeSingleChoice, Intent: "TAB_OPEN_BRANCH"},
+		{QType: models.QuestionTypeMultipleChoice, Intent: "TAB_OPEN_BRANCH", MCCorrectCount: 2},
+		{QType: models.QuestionTypeTrueFalse, Intent: "TAB_CLOSES_TF"},
+	}
+	gentest.Run(t, newGenerator(t), plans, func(c gentest.Case, pools core.CandidatePools) {
+		tp := pools.Tableau
+		if !isPlanFeasible(c.Plan, *tp) {
+			t.Fatalf("%s: infeasible pools returned", c)
+		}
+		if !strings.HasPrefix(tp.Formulas, tp.Premises) {
+			t.Errorf("%s: root formulas %q do not start with the premises %q", c, tp.Formulas, tp.Premises)
+		}
+		for _, b := range append(append([]string(nil), tp.OpenBranches...), tp.BranchDistractors...) {
+			if tp.BranchNotes[b] == "" {
+				t.Errorf("%s: branch %q has no note", c, b)
+			}
+		}
+		// 树封闭当且仅当结论可由前提推出
+		premises := gentest.MustParseList(t, tp.Premises)
+		derivable := func(s string) bool {
+			conclusion := gentest.MustParse(t, s)
+			vars := core.Vars(&core.Node{Kind: core.And, Left: conjoin(premises), Right: conclusion})
+			return v.Derivable(premises, conclusion, vars)
+		}
+		for _, s := range tp.ClosingConclusions {
+			if !derivable(s) {
+				t.Errorf("%s: %s does not follow from %s but is listed as closing", c, s, tp.Premises)
+			}
+		}
+		for _, s := range tp.OpenConclusions {
+			if derivable(s) {
+				t.Errorf("%s: %s follows from %s but is listed as open", c, s, tp.Premises)
+			}
+		}
+		for _, s := range append(append([]string(nil), tp.ClosingConclusions...), tp.OpenConclusions...) {
+			if tp.ConclusionNotes[s] == "" {
+				t.Errorf("%s: conclusion %q has no note", c, s)
+			}
+		}
+	})
+}
+
+// TestOpenBranchesAreConsistent 由验证器确认每条开放分支给出的赋值满足
+// 前提的合取与结论的否定，即这组赋值正是推理的反例
+func TestOpenBranchesAreConsistent(t *testing.T) {
+	g := newGenerator(t)
+	v := validator.NewDefaultValidator()
+	plan := sampler.Plan{QType: models.QuestionTypeSingleChoice, Intent: "TAB_OPEN_BRANCH"}
+	prof := sampler.Profile{InfProfile: sampler.InfProfile{ChainSteps: 2}}
+	for seed := uint64(0); seed < 16; seed++ {
+		pools, _, err := g.Generate(rand.New(rand.NewPCG(seed, 5)), prof, plan)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		tp := pools.Tableau
+		root := &core.Node{Kind: core.And, Left: conjoin(gentest.MustParseList(t, tp.Premises)), Right: &core.Node{Kind: core.Not, Left: gentest.MustParse(t, tp.Conclusion)}}
+		open := make(map[string]bool)
+		for _, b := range tp.OpenBranches {
+			open[b] = true
+			assign, ok := branchAssignment(t, b, core.Vars(root))
+			if !ok {
+				t.Errorf("seed %d: open branch %s contains a complementary pair", seed, b)
+				continue
+			}
+			if !v.Eval(root, assign) {
+				t.Errorf("seed %d: open branch %s does not satisfy %s and ¬(%s)", seed, b, tp.Premises, tp.Conclusion)
+			}
+		}
+		for _, d := range tp.BranchDistractors {
+			if open[d] {
+				t.Errorf("seed %d: %s is both open and a distractor", seed, d)
+			}
+		}
+	}
+}
+
+func conjoin(nodes []*core.Node) *core.Node {
+	acc := nodes[0]
+	for _, n := range nodes[1:] {
+		acc = &core.Node{Kind: core.And, Left: acc, Right: n}
+	}
+	return acc
+}
+
+// branchAssignment 读 tableau.Branch.String 的输出，与 Branch.Assignment 一样
+// 把分支上没有出现的变量赋为假；分支含互补文字时 ok 为 false
+func branchAssignment(t *testing.T, b string, vars []string) (map[string]bool, bool) {
+	t.Helper()
+	assign := make(map[string]bool, len(vars))
+	for _, name := range vars {
+		assign[name] = false
+	}
+	if b == "∅" {
+		return assign, true
+	}
+	seen := make(map[string]bool)
+	for _, n := range gentest.MustParseList(t, b) {
+		name, value := "", true
+		switch {
+		case n.Kind == core.Var:
+			name = n.Name
+		case n.Kind == core.Not && n.Left.Kind == core.Var:
+			name, value = n.Left.Name, false
+		default:
+			t.Fatalf("branch %q contains %v, not a literal", b, n)
+		}
+		if seen[name] && assign[name] != value {
+			return nil, false
+		}
+		seen[name], assign[name] = true, value
+	}
+	return assign, true
+}
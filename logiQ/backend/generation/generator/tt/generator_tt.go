@@ -1,6 +1,7 @@
 package tt
 
 import (
+	"backend/generation/builder/choice"
 	"backend/generation/core"
 	"backend/generation/generator/shared"
 	"backend/generation/helper"
@@ -36,7 +37,7 @@ func (g TruthTableGenerator) Generate(rng *rand.Rand, prof sampler.Profile, plan
 		usedVars := shared.FilterVars(vars, formula)
 		// 构建TT池
 		ttPools := g.buildTruthTable(formula, usedVars)
-		// 保证非平凡
+		// 保证非平凡；恒真 / 恒假公式由 classification 类别专门出题
 		if len(ttPools.TrueSet) == 0 || len(ttPools.FalseSet) == 0 {
 			continue // tautology or contradiction; regenerate
 		}
@@ -95,11 +96,7 @@ func (TruthTableGenerator) isPlanFeasible(plan sampler.Plan, pools core.TruthTab
 			return false
 		}
 	case models.QuestionTypeMultipleChoice:
-		correct := plan.MCCorrectCount
-		if correct <= 0 || correct > 4 {
-			correct = 2
-		}
-		neededDistractor := 4 - correct
+		correct, neededDistractor := choice.OptionCounts(plan)
 		switch plan.Intent {
 		case "TT_TRUE_ASSIGNMENTS":
 			return len(pools.TrueSet) >= correct && len(pools.FalseSet) >= neededDistractor
@@ -0,0 +1,77 @@
+// Package gentest holds helpers shared by the generation tests: parsing
+// fixtures, loading the application config and running a generator over the
+// usual grid of chain lengths and seeds. It is imported only from _test.go
+// files.
+package gentest
+
+import (
+	"backend/generation/config"
+	"backend/generation/core"
+	"backend/generation/generator"
+	"backend/generation/parser"
+	"backend/generation/sampler"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+// MustParse parses a single formula and fails the test if it is malformed.
+func MustParse(t testing.TB, s string) *core.Node {
+	t.Helper()
+	node, err := parser.Parse(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return node
+}
+
+// MustParseList parses a comma-separated list of formulas and fails the test
+// if any of them is malformed.
+func MustParseList(t testing.TB, s string) []*core.Node {
+	t.Helper()
+	nodes, err := parser.ParseList(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return nodes
+}
+
+// Config loads the application config the generators are built from.
+func Config(t testing.TB) config.AppConfig {
+	t.Helper()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return cfg
+}
+
+// Case identifies one generation in Run.
+type Case struct {
+	Plan  sampler.Plan
+	Steps int
+	Seed  uint64
+}
+
+func (c Case) String() string {
+	return fmt.Sprintf("%s %s, %d steps, seed %d", c.Plan.Intent, c.Plan.QType, c.Steps, c.Seed)
+}
+
+// Run generates pools for every plan with chain lengths 1 to 3 and seeds 0
+// to 7, and hands each result to check. A generation error fails the test.
+func Run(t *testing.T, g generator.Generator, plans []sampler.Plan, check func(c Case, pools core.CandidatePools)) {
+	t.Helper()
+	for _, plan := range plans {
+		for steps := 1; steps <= 3; steps++ {
+			prof := sampler.Profile{InfProfile: sampler.InfProfile{ChainSteps: steps}}
+			for seed := uint64(0); seed < 8; seed++ {
+				c := Case{Plan: plan, Steps: steps, Seed: seed}
+				pools, _, err := g.Generate(rand.New(rand.NewPCG(seed, uint64(steps))), prof, plan)
+				if err != nil {
+					t.Fatalf("%s: %v", c, err)
+				}
+				check(c, pools)
+			}
+		}
+	}
+}
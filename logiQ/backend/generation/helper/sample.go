@@ -1,9 +1,15 @@
 package helper
 
-import "math/rand/v2"
+import (
+	"cmp"
+	"maps"
+	"math/rand/v2"
+	"slices"
+)
 
 // SampleWeighted 辅助函数 从一个权重分布中随机抽样一个值。
-func SampleWeighted[T comparable](dist map[T]float64, rng *rand.Rand) T {
+// 按键排序后累加权重，保证同一个 rng 状态总是抽到同一个值
+func SampleWeighted[T cmp.Ordered](dist map[T]float64, rng *rand.Rand) T {
 	var zero T
 	if len(dist) == 0 {
 		return zero
@@ -31,7 +37,8 @@ func SampleWeighted[T comparable](dist map[T]float64, rng *rand.Rand) T {
 		fallback    T
 		hasFallback bool
 	)
-	for val, w := range dist {
+	for _, val := range slices.Sorted(maps.Keys(dist)) {
+		w := dist[val]
 		if w <= 0 {
 			continue
 		}
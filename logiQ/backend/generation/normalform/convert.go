@@ -0,0 +1,177 @@
+package normalform
+
+import (
+	"backend/generation/core"
+	"backend/generation/validator"
+)
+
+// NNF rewrites node so that → ↔ ⊕ ↑ ↓ are eliminated and ¬ only applies to
+// variables; a negated constant becomes the other constant. A ↔ B becomes
+// (¬A ∨ B) ∧ (A ∨ ¬B), which keeps the later CNF distribution small.
+func NNF(node *core.Node) *core.Node {
+	return nnf(node, false)
+}
+
+func nnf(node *core.Node, negate bool) *core.Node {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case core.Var:
+		return Literal{Var: node.Name, Negated: negate}.Node()
+	case core.True, core.False:
+		return constant((node.Kind == core.True) != negate)
+	case core.Not:
+		return nnf(node.Left, !negate)
+	case core.Xor:
+		// A ⊕ B ≡ ¬(A ↔ B)
+		return nnf(&core.Node{Kind: core.Iff, Left: node.Left, Right: node.Right}, !negate)
+	case core.Nand:
+		// A ↑ B ≡ ¬(A ∧ B)
+		return nnf(&core.Node{Kind: core.And, Left: node.Left, Right: node.Right}, !negate)
+	case core.Nor:
+		// A ↓ B ≡ ¬(A ∨ B)
+		return nnf(&core.Node{Kind: core.Or, Left: node.Left, Right: node.Right}, !negate)
+	case core.And, core.Or:
+		kind := node.Kind
+		if negate {
+			// 德摩根律
+			kind = dual(kind)
+		}
+		return &core.Node{Kind: kind, Left: nnf(node.Left, negate), Right: nnf(node.Right, negate)}
+	case core.Impl:
+		// A → B ≡ ¬A ∨ B，¬(A → B) ≡ A ∧ ¬B
+		if negate {
+			return &core.Node{Kind: core.And, Left: nnf(node.Left, false), Right: nnf(node.Right, true)}
+		}
+		return &core.Node{Kind: core.Or, Left: nnf(node.Left, true), Right: nnf(node.Right, false)}
+	case core.Iff:
+		// A ↔ B ≡ (¬A ∨ B) ∧ (A ∨ ¬B)，¬(A ↔ B) ≡ (A ∨ B) ∧ (¬A ∨ ¬B)
+		return &core.Node{
+			Kind:  core.And,
+			Left:  &core.Node{Kind: core.Or, Left: nnf(node.Left, !negate), Right: nnf(node.Right, false)},
+			Right: &core.Node{Kind: core.Or, Left: nnf(node.Left, negate), Right: nnf(node.Right, true)},
+		}
+	default:
+		return node.Clone()
+	}
+}
+
+func constant(value bool) *core.Node {
+	if value {
+		return &core.Node{Kind: core.True}
+	}
+	return &core.Node{Kind: core.False}
+}
+
+func dual(kind core.NodeKind) core.NodeKind {
+	if kind == core.And {
+		return core.Or
+	}
+	return core.And
+}
+
+// ToCNF converts node to CNF by distributing ∨ over ∧. It fails with
+// ErrTooLarge as soon as an intermediate result exceeds limits.
+func ToCNF(node *core.Node, limits Limits) (CNF, error) {
+	clauses, err := distribute(NNF(node), core.And, limits)
+	return CNF(clauses), err
+}
+
+// ToDNF converts node to DNF by distributing ∧ over ∨.
+func ToDNF(node *core.Node, limits Limits) (DNF, error) {
+	clauses, err := distribute(NNF(node), core.Or, limits)
+	return DNF(clauses), err
+}
+
+// distribute 把 NNF 公式展开成子句集合；outer 为外层联结词（CNF 为 ∧，DNF 为 ∨）
+// 外层联结词只需合并子句，内层联结词需要两两组合（分配律）
+func distribute(node *core.Node, outer core.NodeKind, limits Limits) ([]Clause, error) {
+	if node == nil {
+		return nil, nil
+	}
+	switch node.Kind {
+	case core.Var:
+		return []Clause{{{Var: node.Name}}}, nil
+	case core.Not:
+		// NNF 中 ¬ 只作用于变量
+		return []Clause{{{Var: node.Left.Name, Negated: true}}}, nil
+	case core.True, core.False:
+		// 与外层联结词的单位元相同的常量没有子句（CNF 中的 ⊤、DNF 中的 ⊥），另一个常量是一个空子句
+		if (node.Kind == core.True) == (outer == core.And) {
+			return []Clause{}, nil
+		}
+		return []Clause{{}}, nil
+	}
+
+	left, err := distribute(node.Left, outer, limits)
+	if err != nil {
+		return nil, err
+	}
+	right, err := distribute(node.Right, outer, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []Clause
+	if node.Kind == outer {
+		merged = append(append(merged, left...), right...)
+	} else {
+		if limits.MaxClauses > 0 && len(left)*len(right) > limits.MaxClauses*limits.MaxClauses {
+			// 组合数远超上限，吸收律也不可能压回限制之内
+			return nil, ErrTooLarge
+		}
+		merged = make([]Clause, 0, len(left)*len(right))
+		for _, a := range left {
+			for _, b := range right {
+				merged = append(merged, append(append(Clause(nil), a...), b...))
+			}
+		}
+	}
+
+	result := normalize(merged)
+	if err := limits.check(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CNFFromTruthTable builds the canonical CNF of node over vars: one maxterm
+// for every row where node is false.
+func CNFFromTruthTable(node *core.Node, vars []string, limits Limits) (CNF, error) {
+	clauses, err := fromTruthTable(node, vars, false, limits)
+	return CNF(clauses), err
+}
+
+// DNFFromTruthTable builds the canonical DNF of node over vars: one minterm
+// for every row where node is true.
+func DNFFromTruthTable(node *core.Node, vars []string, limits Limits) (DNF, error) {
+	clauses, err := fromTruthTable(node, vars, true, limits)
+	return DNF(clauses), err
+}
+
+// fromTruthTable 收集取值为 want 的行；minterm 中文字与该行取值一致，maxterm 中文字与该行取值相反
+func fromTruthTable(node *core.Node, vars []string, want bool, limits Limits) ([]Clause, error) {
+	if limits.MaxVars > 0 && len(vars) > limits.MaxVars {
+		return nil, ErrTooLarge
+	}
+	table := validator.Compile(node, vars).Table()
+	clauses := make([]Clause, 0)
+	for row := 0; row < table.Rows(); row++ {
+		if table.Row(row) != want {
+			continue
+		}
+		c := make(Clause, len(vars))
+		for i, name := range vars {
+			value := (row>>i)&1 == 1
+			c[i] = Literal{Var: name, Negated: value != want}
+		}
+		clauses = append(clauses, c)
+	}
+
+	result := normalize(clauses)
+	if err := limits.check(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
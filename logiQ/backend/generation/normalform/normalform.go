@@ -0,0 +1,295 @@
+// Package normalform converts core.Node formulas to negation normal form and
+// to conjunctive / disjunctive normal form, either syntactically by
+// distribution or semantically from the truth table (maxterms / minterms).
+// Results are canonical: literals inside a clause and the clauses themselves
+// are sorted, duplicates and subsumed clauses are removed, so two formulas
+// with the same normal form render to the same string.
+package normalform
+
+import (
+	"backend/generation/core"
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrTooLarge = errors.New("normalform: result exceeds size limit")
+
+// Limits bounds the work a conversion may do. Zero fields are unlimited.
+type Limits struct {
+	MaxClauses  int // 结果（以及分配律展开的中间结果）最多包含的子句数
+	MaxLiterals int // 结果中文字的总数
+	MaxVars     int // 真值表方法允许的变量数，2^n 行
+}
+
+// DefaultLimits keeps results small enough to be shown as question options.
+var DefaultLimits = Limits{MaxClauses: 16, MaxLiterals: 48, MaxVars: 10}
+
+// Literal is a variable or its negation.
+type Literal struct {
+	Var     string
+	Negated bool
+}
+
+func (l Literal) String() string {
+	if l.Negated {
+		return "¬" + l.Var
+	}
+	return l.Var
+}
+
+func (l Literal) Node() *core.Node {
+	v := &core.Node{Kind: core.Var, Name: l.Var}
+	if l.Negated {
+		return &core.Node{Kind: core.Not, Left: v}
+	}
+	return v
+}
+
+// LessLiteral is the canonical literal order: by variable name, with the
+// positive literal before the negative one.
+func LessLiteral(a, b Literal) bool {
+	if a.Var != b.Var {
+		return a.Var < b.Var
+	}
+	return !a.Negated && b.Negated
+}
+
+// Clause is a set of literals: a disjunction inside a CNF, a conjunction
+// (term) inside a DNF.
+type Clause []Literal
+
+// Sorted returns a copy of c in the LessLiteral order. Unlike the normal
+// forms it keeps duplicate and complementary literals.
+func (c Clause) Sorted() Clause {
+	out := append(Clause(nil), c...)
+	sort.Slice(out, func(i, j int) bool { return LessLiteral(out[i], out[j]) })
+	return out
+}
+
+// Without returns a copy of c with every occurrence of l removed.
+func (c Clause) Without(l Literal) Clause {
+	out := make(Clause, 0, len(c))
+	for _, x := range c {
+		if x != l {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// CNF is a conjunction of disjunctive clauses. The empty CNF is true; a CNF
+// containing an empty clause is false.
+type CNF []Clause
+
+// DNF is a disjunction of conjunctive terms. The empty DNF is false; a DNF
+// containing an empty term is true.
+type DNF []Clause
+
+// Size returns the number of literal occurrences.
+func (f CNF) Size() int { return size(f) }
+
+// Size returns the number of literal occurrences.
+func (f DNF) Size() int { return size(f) }
+
+// Vars returns the sorted variables occurring in the formula.
+func (f CNF) Vars() []string { return vars(f) }
+
+// Vars returns the sorted variables occurring in the formula.
+func (f DNF) Vars() []string { return vars(f) }
+
+// IsConstant reports whether the formula is true or false regardless of the
+// assignment.
+func (f CNF) IsConstant() bool { return isConstant(f) }
+
+// IsConstant reports whether the formula is true or false regardless of the
+// assignment.
+func (f DNF) IsConstant() bool { return isConstant(f) }
+
+// Node builds the formula as a left-nested tree; constants become ⊤ / ⊥.
+func (f CNF) Node() *core.Node { return build(f, core.Or, core.And) }
+
+// Node builds the formula as a left-nested tree; constants become ⊤ / ⊥.
+func (f DNF) Node() *core.Node { return build(f, core.And, core.Or) }
+
+// String renders the formula flat, e.g. "(p ∨ ¬q) ∧ r". Unlike
+// helper.Stringify it omits parentheses around the associative outer
+// connective; the parser reads it back to the same formula.
+func (f CNF) String() string { return render(f, "∨", "∧", "⊤", "⊥") }
+
+// String renders the formula flat, e.g. "(p ∧ ¬q) ∨ r".
+func (f DNF) String() string { return render(f, "∧", "∨", "⊥", "⊤") }
+
+// And returns the conjunction of f and g in canonical form.
+func (f CNF) And(g CNF) CNF {
+	merged := make([]Clause, 0, len(f)+len(g))
+	merged = append(append(merged, f...), g...)
+	return CNF(normalize(merged))
+}
+
+// NormalizeClause sorts the literals of c and removes duplicates. ok is false
+// when c contains a literal together with its negation.
+func NormalizeClause(c Clause) (Clause, bool) { return normalizeClause(c) }
+
+func size(clauses []Clause) int {
+	n := 0
+	for _, c := range clauses {
+		n += len(c)
+	}
+	return n
+}
+
+func vars(clauses []Clause) []string {
+	seen := make(map[string]struct{})
+	out := make([]string, 0)
+	for _, c := range clauses {
+		for _, l := range c {
+			if _, ok := seen[l.Var]; !ok {
+				seen[l.Var] = struct{}{}
+				out = append(out, l.Var)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func isConstant(clauses []Clause) bool {
+	if len(clauses) == 0 {
+		return true
+	}
+	for _, c := range clauses {
+		if len(c) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// build 空公式为外层联结词的单位元（CNF 为 ⊤，DNF 为 ⊥），含空子句时为另一个常量
+func build(clauses []Clause, inner, outer core.NodeKind) *core.Node {
+	if len(clauses) == 0 {
+		return constant(outer == core.And)
+	}
+	if isConstant(clauses) {
+		return constant(outer != core.And)
+	}
+	var root *core.Node
+	for _, c := range clauses {
+		var node *core.Node
+		for _, l := range c {
+			if node == nil {
+				node = l.Node()
+			} else {
+				node = &core.Node{Kind: inner, Left: node, Right: l.Node()}
+			}
+		}
+		if root == nil {
+			root = node
+		} else {
+			root = &core.Node{Kind: outer, Left: root, Right: node}
+		}
+	}
+	return root
+}
+
+// render 把子句渲染为扁平字符串；empty 为空公式的常量，hasEmpty 为含空子句时的常量
+func render(clauses []Clause, innerOp, outerOp, empty, hasEmpty string) string {
+	if len(clauses) == 0 {
+		return empty
+	}
+	parts := make([]string, len(clauses))
+	for i, c := range clauses {
+		if len(c) == 0 {
+			return hasEmpty
+		}
+		lits := make([]string, len(c))
+		for j, l := range c {
+			lits[j] = l.String()
+		}
+		parts[i] = strings.Join(lits, " "+innerOp+" ")
+		if len(c) > 1 && len(clauses) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " "+outerOp+" ")
+}
+
+// normalize 把子句集合整理成规范形式：
+//   - 子句内文字排序去重，同时含 p 与 ¬p 的子句整体删除（CNF 中恒真，DNF 中恒假）
+//   - 删除被其他子句包含的子句（吸收律）
+//   - 子句按长度、再按文字字典序排序并去重
+func normalize(clauses []Clause) []Clause {
+	out := make([]Clause, 0, len(clauses))
+	for _, c := range clauses {
+		if nc, ok := normalizeClause(c); ok {
+			out = append(out, nc)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return lessClause(out[i], out[j]) })
+
+	// 排序后较短的子句在前，只需检查之前保留的子句是否为其子集
+	kept := out[:0]
+	for _, c := range out {
+		absorbed := false
+		for _, k := range kept {
+			if subset(k, c) {
+				absorbed = true
+				break
+			}
+		}
+		if !absorbed {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func normalizeClause(c Clause) (Clause, bool) {
+	sorted := c.Sorted()
+	out := sorted[:0]
+	for i, l := range sorted {
+		if i > 0 && sorted[i-1] == l {
+			continue
+		}
+		if i > 0 && sorted[i-1].Var == l.Var {
+			// 同一变量的正负文字相邻
+			return nil, false
+		}
+		out = append(out, l)
+	}
+	return out, true
+}
+
+func lessClause(a, b Clause) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return LessLiteral(a[i], b[i])
+		}
+	}
+	return false
+}
+
+// subset 判断有序子句 a 是否为 b 的子集
+func subset(a, b Clause) bool {
+	i := 0
+	for _, l := range b {
+		if i < len(a) && a[i] == l {
+			i++
+		}
+	}
+	return i == len(a)
+}
+
+func (l Limits) check(clauses []Clause) error {
+	if l.MaxClauses > 0 && len(clauses) > l.MaxClauses {
+		return ErrTooLarge
+	}
+	if l.MaxLiterals > 0 && size(clauses) > l.MaxLiterals {
+		return ErrTooLarge
+	}
+	return nil
+}
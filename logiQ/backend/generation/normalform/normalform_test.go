@@ -0,0 +1,167 @@
+package normalform
+
+import (
+	"backend/generation/core"
+	"backend/generation/generator/shared"
+	"backend/generation/helper"
+	"backend/generation/parser"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+var testProfile = sampler.Profile{
+	Vars:       4,
+	MaxDepth:   4,
+	AllowedOps: []core.NodeKind{core.Not, core.And, core.Or, core.Impl, core.Iff, core.Xor, core.Nand, core.Nor, core.True, core.False},
+}
+
+// isNNF 检查公式只含 ∧ / ∨ 和常量，且 ¬ 只作用于变量
+func isNNF(node *core.Node) bool {
+	switch node.Kind {
+	case core.Var, core.True, core.False:
+		return true
+	case core.Not:
+		return node.Left.Kind == core.Var
+	case core.And, core.Or:
+		return isNNF(node.Left) && isNNF(node.Right)
+	default:
+		return false
+	}
+}
+
+func TestConversionsPreserveEquivalence(t *testing.T) {
+	rng := rand.New(rand.NewPCG(6, 60))
+	v := validator.NewBitsetValidator()
+	unlimited := Limits{}
+	for i := 0; i < 200; i++ {
+		formula := shared.RandomFormula(rng, testProfile)
+		vars := shared.CanonicalVars(testProfile.Vars)
+		name := helper.Stringify(formula)
+
+		nnf := NNF(formula)
+		if !isNNF(nnf) || !v.Equivalent(formula, nnf, vars) {
+			t.Fatalf("NNF(%s) = %s", name, helper.Stringify(nnf))
+		}
+
+		forms := map[string]interface{ Node() *core.Node }{}
+		cnf, err := ToCNF(formula, unlimited)
+		if err != nil {
+			t.Fatalf("ToCNF(%s): %v", name, err)
+		}
+		forms["cnf"] = cnf
+		dnf, err := ToDNF(formula, unlimited)
+		if err != nil {
+			t.Fatalf("ToDNF(%s): %v", name, err)
+		}
+		forms["dnf"] = dnf
+		ttCNF, err := CNFFromTruthTable(formula, vars, unlimited)
+		if err != nil {
+			t.Fatalf("CNFFromTruthTable(%s): %v", name, err)
+		}
+		forms["maxterms"] = ttCNF
+		ttDNF, err := DNFFromTruthTable(formula, vars, unlimited)
+		if err != nil {
+			t.Fatalf("DNFFromTruthTable(%s): %v", name, err)
+		}
+		forms["minterms"] = ttDNF
+
+		for kind, form := range forms {
+			if !v.Equivalent(formula, form.Node(), vars) {
+				t.Fatalf("%s of %s = %s is not equivalent", kind, name, helper.Stringify(form.Node()))
+			}
+		}
+	}
+}
+
+func TestCanonicalOrdering(t *testing.T) {
+	a, _ := parser.Parse("(q ∨ ¬p) ∧ (r → p) ∧ q")
+	b, _ := parser.Parse("q ∧ (p ∨ ¬r) ∧ (¬p ∨ q ∨ q)")
+	ca, err := ToCNF(a, DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := ToCNF(b, DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ¬p ∨ q 被单子句 q 吸收
+	if want := "q ∧ (p ∨ ¬r)"; ca.String() != want || cb.String() != want {
+		t.Errorf("CNF strings = %q, %q, want %q", ca.String(), cb.String(), want)
+	}
+
+	dnf, err := DNFFromTruthTable(a, []string{"p", "q", "r"}, DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(p ∧ q ∧ r) ∨ (p ∧ q ∧ ¬r) ∨ (¬p ∧ q ∧ ¬r)"; dnf.String() != want {
+		t.Errorf("minterm DNF = %q, want %q", dnf.String(), want)
+	}
+	parsed, err := parser.Parse(dnf.String())
+	if err != nil {
+		t.Fatalf("rendered DNF does not parse: %v", err)
+	}
+	if helper.Stringify(parsed) != helper.Stringify(dnf.Node()) {
+		t.Errorf("rendered DNF parses to %s, want %s", helper.Stringify(parsed), helper.Stringify(dnf.Node()))
+	}
+}
+
+func TestConstants(t *testing.T) {
+	taut, _ := parser.Parse("p ∨ ¬p")
+	cnf, err := ToCNF(taut, DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cnf.IsConstant() || cnf.String() != "⊤" || cnf.Node().Kind != core.True {
+		t.Errorf("CNF of tautology = %q", cnf.String())
+	}
+	contra, _ := parser.Parse("p ∧ ¬p")
+	dnf, err := ToDNF(contra, DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dnf.IsConstant() || dnf.String() != "⊥" || dnf.Node().Kind != core.False {
+		t.Errorf("DNF of contradiction = %q", dnf.String())
+	}
+
+	// 常量在转换中按单位元 / 零元化简
+	cases := map[string]string{
+		"p ∧ ⊤":        "p",
+		"p ∨ ⊤":        "⊤",
+		"(p ∨ ⊥) ∧ q":  "p ∧ q",
+		"¬⊥ → (p ∧ ⊥)": "⊥",
+		"p ⊕ q":        "(p ∨ q) ∧ (¬p ∨ ¬q)",
+		"p ↑ q":        "¬p ∨ ¬q",
+		"¬(p ↓ q)":     "p ∨ q",
+	}
+	for input, want := range cases {
+		f, _ := parser.Parse(input)
+		cnf, err := ToCNF(f, DefaultLimits)
+		if err != nil {
+			t.Fatalf("ToCNF(%s): %v", input, err)
+		}
+		if cnf.String() != want {
+			t.Errorf("ToCNF(%s) = %q, want %q", input, cnf.String(), want)
+		}
+	}
+}
+
+func TestSizeLimits(t *testing.T) {
+	// (a1 ∨ b1) ∧ ... ∧ (a6 ∨ b6) 展开成 DNF 有 2^6 = 64 项
+	f, _ := parser.Parse("(a1 ∨ b1) ∧ (a2 ∨ b2) ∧ (a3 ∨ b3) ∧ (a4 ∨ b4) ∧ (a5 ∨ b5) ∧ (a6 ∨ b6)")
+	if _, err := ToDNF(f, DefaultLimits); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("ToDNF error = %v, want ErrTooLarge", err)
+	}
+	if _, err := ToCNF(f, DefaultLimits); err != nil {
+		t.Errorf("ToCNF of a CNF should stay small: %v", err)
+	}
+	if dnf, err := ToDNF(f, Limits{}); err != nil || len(dnf) != 64 {
+		t.Errorf("unlimited ToDNF = %d terms, %v", len(dnf), err)
+	}
+	vars := []string{"a1", "b1", "a2", "b2", "a3", "b3", "a4", "b4", "a5", "b5", "a6", "b6"}
+	if _, err := CNFFromTruthTable(f, vars, DefaultLimits); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("CNFFromTruthTable over 12 vars error = %v, want ErrTooLarge", err)
+	}
+}
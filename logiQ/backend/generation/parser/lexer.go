@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNot
+	tokAnd
+	tokOr
+	tokImpl
+	tokIff
+	tokXor
+	tokNand
+	tokNor
+	tokTrue
+	tokFalse
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+// token is a lexical unit together with its 1-based column in the input.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// symbolTokens maps every accepted operator spelling to its token kind.
+// The Unicode forms are the ones emitted by helper.Stringify; the ASCII
+// aliases are meant for hand-written input and match render.ASCII.
+var symbolTokens = []struct {
+	text string
+	kind tokenKind
+}{
+	// 长的写法放在前面，保证 "<->" 不会被拆成 "<" + "->"，"!&" 不会被拆成 "!" + "&"
+	{"<->", tokIff},
+	{"->", tokImpl},
+	{"!&", tokNand},
+	{"!|", tokNor},
+	{"↔", tokIff},
+	{"→", tokImpl},
+	{"¬", tokNot},
+	{"~", tokNot},
+	{"∧", tokAnd},
+	{"&", tokAnd},
+	{"∨", tokOr},
+	{"|", tokOr},
+	{"⊕", tokXor},
+	{"⊻", tokXor},
+	{"^", tokXor},
+	{"↑", tokNand},
+	{"↓", tokNor},
+	{"⊤", tokTrue},
+	{"⊥", tokFalse},
+	{"1", tokTrue},
+	{"0", tokFalse},
+	{"(", tokLParen},
+	{")", tokRParen},
+	{",", tokComma},
+}
+
+// tokenize splits the input into tokens, reporting the first unknown symbol.
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	tokens := make([]token, 0, len(runes))
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+
+		if isIdentStart(r) {
+			start := i
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: string(runes[start:i]), pos: start + 1})
+			continue
+		}
+
+		matched := false
+		for _, sym := range symbolTokens {
+			symRunes := []rune(sym.text)
+			if hasRunePrefix(runes[i:], symRunes) {
+				tokens = append(tokens, token{kind: sym.kind, text: sym.text, pos: i + 1})
+				i += len(symRunes)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, newError(i+1, "unexpected character %q", r)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokEOF, pos: len(runes) + 1})
+	return tokens, nil
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of input"
+	case tokIdent:
+		return "variable"
+	case tokNot:
+		return "'¬'"
+	case tokAnd:
+		return "'∧'"
+	case tokOr:
+		return "'∨'"
+	case tokImpl:
+		return "'→'"
+	case tokIff:
+		return "'↔'"
+	case tokXor:
+		return "'⊕'"
+	case tokNand:
+		return "'↑'"
+	case tokNor:
+		return "'↓'"
+	case tokTrue:
+		return "'⊤'"
+	case tokFalse:
+		return "'⊥'"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	case tokComma:
+		return "','"
+	default:
+		return "unknown token"
+	}
+}
@@ -0,0 +1,221 @@
+package parser
+
+import (
+	"backend/generation/core"
+	"fmt"
+)
+
+// Error describes a syntax error together with the 1-based column (counted in
+// runes) where it was detected.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("parser: %s at column %d", e.Msg, e.Pos)
+}
+
+func newError(pos int, format string, args ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parse turns a formula string into an AST.
+//
+// It accepts the Unicode notation produced by helper.Stringify (¬ ∧ ∨ → ↔,
+// ⊕ ↑ ↓ and the constants ⊤ ⊥) as well as the ASCII aliases ~ & | -> <->,
+// ^ !& !| and the constants 1 0.
+// Precedence from tightest to loosest is ¬, ∧ / ↑, ∨ / ⊕ / ↓, →, ↔. The
+// operators sharing a level associate to the left, → and ↔ to the right, so
+// "p → q → r" reads as "p → (q → r)" and "p ∨ q ⊕ r" as "(p ∨ q) ⊕ r".
+func Parse(input string) (*core.Node, error) {
+	p, err := newParser(input)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokEOF {
+		return nil, newError(p.peek().pos, "empty formula")
+	}
+	node, err := p.parseIff()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, newError(tok.pos, "unexpected %s", tok.kind)
+	}
+	return node, nil
+}
+
+// ParseList parses a comma separated list of formulas, e.g. the premise string
+// stored in core.InferencePools.Premises.
+func ParseList(input string) ([]*core.Node, error) {
+	p, err := newParser(input)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokEOF {
+		return nil, newError(p.peek().pos, "empty formula list")
+	}
+
+	nodes := make([]*core.Node, 0)
+	for {
+		node, err := p.parseIff()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		tok := p.next()
+		switch tok.kind {
+		case tokEOF:
+			return nodes, nil
+		case tokComma:
+			continue
+		default:
+			return nil, newError(tok.pos, "unexpected %s, expected ',' or end of input", tok.kind)
+		}
+	}
+}
+
+type parser struct {
+	tokens []token
+	idx    int
+}
+
+func newParser(input string) (*parser, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{tokens: tokens}, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.idx]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.idx]
+	if tok.kind != tokEOF {
+		p.idx++
+	}
+	return tok
+}
+
+// parseIff: iff := impl ( '↔' iff )?
+func (p *parser) parseIff() (*core.Node, error) {
+	left, err := p.parseImpl()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokIff {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseIff()
+	if err != nil {
+		return nil, err
+	}
+	return &core.Node{Kind: core.Iff, Left: left, Right: right}, nil
+}
+
+// parseImpl: impl := or ( '→' impl )?
+func (p *parser) parseImpl() (*core.Node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokImpl {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseImpl()
+	if err != nil {
+		return nil, err
+	}
+	return &core.Node{Kind: core.Impl, Left: left, Right: right}, nil
+}
+
+// orLevel / andLevel 同一优先级的联结词，左结合
+var (
+	orLevel  = map[tokenKind]core.NodeKind{tokOr: core.Or, tokXor: core.Xor, tokNor: core.Nor}
+	andLevel = map[tokenKind]core.NodeKind{tokAnd: core.And, tokNand: core.Nand}
+)
+
+// parseOr: or := and ( ('∨' | '⊕' | '↓') and )*
+func (p *parser) parseOr() (*core.Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		kind, ok := orLevel[p.peek().kind]
+		if !ok {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &core.Node{Kind: kind, Left: left, Right: right}
+	}
+}
+
+// parseAnd: and := unary ( ('∧' | '↑') unary )*
+func (p *parser) parseAnd() (*core.Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		kind, ok := andLevel[p.peek().kind]
+		if !ok {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &core.Node{Kind: kind, Left: left, Right: right}
+	}
+}
+
+// parseUnary: unary := '¬' unary | primary
+func (p *parser) parseUnary() (*core.Node, error) {
+	if p.peek().kind != tokNot {
+		return p.parsePrimary()
+	}
+	p.next()
+	inner, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &core.Node{Kind: core.Not, Left: inner}, nil
+}
+
+// parsePrimary: primary := variable | '⊤' | '⊥' | '(' iff ')'
+func (p *parser) parsePrimary() (*core.Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokIdent:
+		return &core.Node{Kind: core.Var, Name: tok.text}, nil
+	case tokTrue:
+		return &core.Node{Kind: core.True}, nil
+	case tokFalse:
+		return &core.Node{Kind: core.False}, nil
+	case tokLParen:
+		inner, err := p.parseIff()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.next()
+		if closing.kind != tokRParen {
+			return nil, newError(closing.pos, "unexpected %s, expected ')' to close '(' at column %d", closing.kind, tok.pos)
+		}
+		return inner, nil
+	default:
+		return nil, newError(tok.pos, "unexpected %s, expected a variable, a constant or '('", tok.kind)
+	}
+}
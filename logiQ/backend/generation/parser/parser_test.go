@@ -0,0 +1,96 @@
+package parser_test
+
+import (
+	"backend/generation/core"
+	"backend/generation/generator/shared"
+	"backend/generation/helper"
+	"backend/generation/parser"
+	"backend/generation/sampler"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 7))
+	prof := sampler.Profile{
+		Vars:       4,
+		MaxDepth:   5,
+		AllowedOps: []core.NodeKind{core.Not, core.And, core.Or, core.Impl, core.Iff, core.Xor, core.Nand, core.Nor, core.True, core.False},
+	}
+	for i := 0; i < 200; i++ {
+		formula := shared.RandomFormula(rng, prof)
+		text := helper.Stringify(formula)
+		parsed, err := parser.Parse(text)
+		if err != nil {
+			t.Fatalf("parse %q: %v", text, err)
+		}
+		if got := helper.Stringify(parsed); got != text {
+			t.Fatalf("round trip mismatch: %q -> %q", text, got)
+		}
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	cases := map[string]string{
+		"~p & q | r -> s <-> t": "(((¬p ∧ q) ∨ r) → s) ↔ t",
+		"p -> q -> r":           "p → (q → r)",
+		"p <-> q <-> r":         "p ↔ (q ↔ r)",
+		"p & q & r":             "(p ∧ q) ∧ r",
+		"p | q & r":             "p ∨ (q ∧ r)",
+		"¬¬(p ∨ q)":             "¬¬(p ∨ q)",
+		"~(p -> q)":             "¬(p → q)",
+		"p ∨ q ⊕ r ↓ s":         "((p ∨ q) ⊕ r) ↓ s",
+		"p ↑ q ∧ r ⊕ s":         "((p ↑ q) ∧ r) ⊕ s",
+		"¬⊤ ∨ p ⊻ ⊥":            "(¬⊤ ∨ p) ⊕ ⊥",
+		"p !& q ^ r !| 1 -> ~0": "(((p ↑ q) ⊕ r) ↓ ⊤) → ¬⊥",
+	}
+	for input, want := range cases {
+		node, err := parser.Parse(input)
+		if err != nil {
+			t.Fatalf("parse %q: %v", input, err)
+		}
+		if got := helper.Stringify(node); got != want {
+			t.Errorf("parse %q = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	nodes, err := parser.ParseList("p → q, ¬q, (r ∧ s)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"p → q", "¬q", "r ∧ s"}
+	if len(nodes) != len(want) {
+		t.Fatalf("got %d formulas, want %d", len(nodes), len(want))
+	}
+	for i, node := range nodes {
+		if got := helper.Stringify(node); got != want[i] {
+			t.Errorf("formula %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]int{
+		"":          1,
+		"p ∧":       4,
+		"(p ∨ q":    7,
+		"p # q":     3,
+		"p q":       3,
+		"→ p":       1,
+		"p, q":      2,
+		"¬(p → q))": 9,
+	}
+	for input, wantPos := range cases {
+		_, err := parser.Parse(input)
+		var perr *parser.Error
+		if !errors.As(err, &perr) {
+			t.Fatalf("parse %q: expected *parser.Error, got %v", input, err)
+		}
+		if perr.Pos != wantPos {
+			t.Errorf("parse %q: error at column %d, want %d (%v)", input, perr.Pos, wantPos, perr)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package proof
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+	"fmt"
+)
+
+// CheckError reports a line of a proof that is not justified.
+type CheckError struct {
+	Line   int
+	Reason string
+}
+
+func (e *CheckError) Error() string {
+	return fmt.Sprintf("proof: line %d: %s", e.Line, e.Reason)
+}
+
+// Schema returns the rule's pattern, e.g. "A → B, A ⊢ B".
+func (r Rule) Schema() string {
+	switch r {
+	case ModusPonens:
+		return "A → B, A ⊢ B"
+	case ModusTollens:
+		return "A → B, ¬B ⊢ ¬A"
+	case HypotheticalSyllogism:
+		return "A → B, B → C ⊢ A → C"
+	case DisjunctiveSyllogism:
+		return "A ∨ B, ¬A ⊢ B"
+	case Simplification:
+		return "A ∧ B ⊢ A"
+	case Conjunction:
+		return "A, B ⊢ A ∧ B"
+	case Addition:
+		return "A ⊢ A ∨ B"
+	case BiconditionalElimination:
+		return "A ↔ B ⊢ A → B"
+	case BiconditionalIntroduction:
+		return "A → B, B → A ⊢ A ↔ B"
+	default:
+		return ""
+	}
+}
+
+// arity 规则需要引用的行数
+func (r Rule) arity() int {
+	switch r {
+	case Simplification, Addition, BiconditionalElimination:
+		return 1
+	case Premise:
+		return 0
+	default:
+		return 2
+	}
+}
+
+// Check verifies every line: numbering is consecutive from 1, premise lines
+// restate one of premises, and every other line follows from the lines it
+// cites by its rule.
+func (p Proof) Check(premises []*core.Node) error {
+	for i := range p.Lines {
+		if err := p.checkAt(i, premises); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAll checks every line like Check but does not stop at the first
+// error: each line is judged on its own against the lines it cites, as
+// written. Lines without a formula or rule (e.g. ones that failed to parse)
+// are skipped; lines citing them are reported.
+func (p Proof) CheckAll(premises []*core.Node) []*CheckError {
+	var errs []*CheckError
+	for i, l := range p.Lines {
+		if l.Formula == nil || l.Rule == "" {
+			continue
+		}
+		if err := p.checkAt(i, premises); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// checkAt 检查第 i 行：编号、前提行是否属于 premises、推导行是否由引用的行得到
+func (p Proof) checkAt(i int, premises []*core.Node) *CheckError {
+	l := p.Lines[i]
+	if l.Number != i+1 {
+		return &CheckError{Line: l.Number, Reason: fmt.Sprintf("expected line number %d", i+1)}
+	}
+	if l.Rule != Premise {
+		return p.checkLine(i)
+	}
+	if len(l.Cites) != 0 {
+		return &CheckError{Line: l.Number, Reason: "a premise cites no lines"}
+	}
+	for _, prem := range premises {
+		if equal(prem, l.Formula) {
+			return nil
+		}
+	}
+	return &CheckError{Line: l.Number, Reason: fmt.Sprintf("%s is not a premise", helper.Stringify(l.Formula))}
+}
+
+// CheckLine verifies the derived line at index i against the lines it cites.
+// Premise lines are accepted; Check verifies them against the premises.
+func (p Proof) CheckLine(i int) error {
+	if err := p.checkLine(i); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Proof) checkLine(i int) *CheckError {
+	l := p.Lines[i]
+	if l.Rule == Premise {
+		return nil
+	}
+	if len(l.Cites) != l.Rule.arity() {
+		return &CheckError{Line: l.Number, Reason: fmt.Sprintf("%s cites %d line(s), got %d", l.Rule, l.Rule.arity(), len(l.Cites))}
+	}
+	cited := make([]*core.Node, len(l.Cites))
+	for j, c := range l.Cites {
+		if c < 1 || c >= l.Number || c > len(p.Lines) {
+			return &CheckError{Line: l.Number, Reason: fmt.Sprintf("line %d is not an earlier line", c)}
+		}
+		if p.Lines[c-1].Formula == nil {
+			return &CheckError{Line: l.Number, Reason: fmt.Sprintf("line %d has no valid formula", c)}
+		}
+		cited[j] = p.Lines[c-1].Formula
+	}
+	if follows(l.Rule, cited, l.Formula) {
+		return nil
+	}
+	return &CheckError{Line: l.Number, Reason: fmt.Sprintf("%s does not follow from line(s) %s by %s (%s)", helper.Stringify(l.Formula), citeList(l.Cites), l.Rule, l.Rule.Schema())}
+}
+
+// follows 判断 formula 能否由 cited 按规则得到
+// Addition 的另一个析取支可以任意选取，无法枚举，单独判断
+func follows(rule Rule, cited []*core.Node, formula *core.Node) bool {
+	if rule == Addition {
+		return len(cited) == 1 && formula.Kind == core.Or && (equal(formula.Left, cited[0]) || equal(formula.Right, cited[0]))
+	}
+	for _, result := range consequences(rule, cited) {
+		if equal(result, formula) {
+			return true
+		}
+	}
+	return false
+}
+
+// consequences 返回把规则应用于 cited 能得到的全部公式；两行的规则两种顺序都尝试
+// Addition 的结果无法枚举，返回空，因此证明搜索不会使用它
+func consequences(rule Rule, cited []*core.Node) []*core.Node {
+	if len(cited) != rule.arity() {
+		return nil
+	}
+	out := make([]*core.Node, 0, 2)
+	switch rule {
+	case Simplification:
+		if a := cited[0]; a.Kind == core.And {
+			out = append(out, a.Left.Clone(), a.Right.Clone())
+		}
+		return out
+	case BiconditionalElimination:
+		if a := cited[0]; a.Kind == core.Iff {
+			out = append(out,
+				&core.Node{Kind: core.Impl, Left: a.Left.Clone(), Right: a.Right.Clone()},
+				&core.Node{Kind: core.Impl, Left: a.Right.Clone(), Right: a.Left.Clone()},
+			)
+		}
+		return out
+	}
+
+	for _, pair := range [2][2]*core.Node{{cited[0], cited[1]}, {cited[1], cited[0]}} {
+		if result := applyBinary(rule, pair[0], pair[1]); result != nil {
+			out = append(out, result)
+		}
+	}
+	return out
+}
+
+// applyBinary 按固定顺序应用两行规则，a 为主前提（蕴含式、析取式等）
+func applyBinary(rule Rule, a, b *core.Node) *core.Node {
+	switch rule {
+	case ModusPonens:
+		if a.Kind == core.Impl && equal(a.Left, b) {
+			return a.Right.Clone()
+		}
+	case ModusTollens:
+		if a.Kind == core.Impl && b.Kind == core.Not && equal(b.Left, a.Right) {
+			return &core.Node{Kind: core.Not, Left: a.Left.Clone()}
+		}
+	case HypotheticalSyllogism:
+		if a.Kind == core.Impl && b.Kind == core.Impl && equal(a.Right, b.Left) {
+			return &core.Node{Kind: core.Impl, Left: a.Left.Clone(), Right: b.Right.Clone()}
+		}
+	case DisjunctiveSyllogism:
+		if a.Kind == core.Or && b.Kind == core.Not {
+			if equal(b.Left, a.Left) {
+				return a.Right.Clone()
+			}
+			if equal(b.Left, a.Right) {
+				return a.Left.Clone()
+			}
+		}
+	case Conjunction:
+		return &core.Node{Kind: core.And, Left: a.Clone(), Right: b.Clone()}
+	case BiconditionalIntroduction:
+		if a.Kind == core.Impl && b.Kind == core.Impl && equal(a.Left, b.Right) && equal(a.Right, b.Left) {
+			return &core.Node{Kind: core.Iff, Left: a.Left.Clone(), Right: a.Right.Clone()}
+		}
+	}
+	return nil
+}
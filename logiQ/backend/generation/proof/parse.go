@@ -0,0 +1,89 @@
+package proof
+
+import (
+	"backend/generation/parser"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ruleAliases 规则的各种写法，键为去掉空白、下划线并转小写后的形式
+// 包括显示名、常见缩写、自然演绎记号（∧E、→E 等）以及 inference.yaml 中的模板名
+var ruleAliases = map[string]Rule{
+	"premise": Premise, "pr": Premise, "prem": Premise, "assumption": Premise,
+
+	"modusponens": ModusPonens, "mp": ModusPonens, "→e": ModusPonens, "->e": ModusPonens, "impliese": ModusPonens,
+	"modustollens": ModusTollens, "mt": ModusTollens,
+	"hypotheticalsyllogism": HypotheticalSyllogism, "hs": HypotheticalSyllogism,
+	"disjunctivesyllogism": DisjunctiveSyllogism, "ds": DisjunctiveSyllogism,
+	"simplification": Simplification, "simp": Simplification, "∧e": Simplification, "&e": Simplification,
+	"conjunctionelimination": Simplification, "conjunctioneliminationleft": Simplification, "conjunctioneliminationright": Simplification,
+	"conjunction": Conjunction, "conj": Conjunction, "∧i": Conjunction, "&i": Conjunction, "conjunctionintroduction": Conjunction,
+	"addition": Addition, "add": Addition, "∨i": Addition, "|i": Addition, "disjunctionintroduction": Addition,
+	"biconditionalelimination": BiconditionalElimination, "↔e": BiconditionalElimination, "<->e": BiconditionalElimination,
+	"biconditionalintroduction": BiconditionalIntroduction, "↔i": BiconditionalIntroduction, "<->i": BiconditionalIntroduction,
+}
+
+// ParseRule resolves a rule name such as "Modus Ponens", "MP", "∧E" or
+// "modus_ponens", ignoring case, spaces and underscores.
+func ParseRule(name string) (Rule, error) {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '_' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+	rule, ok := ruleAliases[key]
+	if !ok {
+		return "", fmt.Errorf("proof: unknown rule %q", name)
+	}
+	return rule, nil
+}
+
+// ParseJustification splits a justification such as "MP 1, 2",
+// "Modus Ponens, 1, 2" or "premise" into the rule and the cited line numbers.
+// The rule name comes first; the numbers may be separated by commas or spaces.
+func ParseJustification(s string) (Rule, []int, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("proof: empty justification")
+	}
+	nameEnd := len(fields)
+	for nameEnd > 0 {
+		if _, err := strconv.Atoi(fields[nameEnd-1]); err != nil {
+			break
+		}
+		nameEnd--
+	}
+	if nameEnd == 0 {
+		return "", nil, fmt.Errorf("proof: justification %q names no rule", s)
+	}
+	rule, err := ParseRule(strings.Join(fields[:nameEnd], " "))
+	if err != nil {
+		return "", nil, err
+	}
+	cites := make([]int, 0, len(fields)-nameEnd)
+	for _, f := range fields[nameEnd:] {
+		n, _ := strconv.Atoi(f)
+		cites = append(cites, n)
+	}
+	return rule, cites, nil
+}
+
+// ParseLine builds line number n from a formula and a justification as
+// typed by a student. On error the returned line keeps whatever parsed, so
+// the caller can still check the remaining lines.
+func ParseLine(n int, formula, justification string) (Line, error) {
+	line := Line{Number: n}
+	node, formulaErr := parser.Parse(formula)
+	if formulaErr == nil {
+		line.Formula = node
+	}
+	rule, cites, ruleErr := ParseJustification(justification)
+	if ruleErr == nil {
+		line.Rule, line.Cites = rule, cites
+	}
+	return line, errors.Join(formulaErr, ruleErr)
+}
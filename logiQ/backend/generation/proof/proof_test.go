@@ -0,0 +1,205 @@
+package proof
+
+import (
+	"backend/generation/gentest"
+	"backend/generation/helper"
+	"errors"
+	"testing"
+)
+
+func TestCheckLineRules(t *testing.T) {
+	cases := []struct {
+		rule     Rule
+		cited    string
+		formula  string
+		accepted bool
+	}{
+		{ModusPonens, "p → q, p", "q", true},
+		{ModusPonens, "p, p → q", "q", true},
+		{ModusPonens, "p → q, q", "p", false},
+		{ModusTollens, "p → q, ¬q", "¬p", true},
+		{ModusTollens, "p → q, ¬p", "¬q", false},
+		{HypotheticalSyllogism, "q → r, p → q", "p → r", true},
+		{HypotheticalSyllogism, "p → q, p → r", "q → r", false},
+		{DisjunctiveSyllogism, "p ∨ q, ¬p", "q", true},
+		{DisjunctiveSyllogism, "p ∨ q, ¬q", "p", true},
+		{DisjunctiveSyllogism, "p ∨ q, p", "¬q", false},
+		{Simplification, "p ∧ q", "q", true},
+		{Simplification, "p ∨ q", "q", false},
+		{Conjunction, "p, q", "p ∧ q", true},
+		{Conjunction, "p, q", "p ∨ q", false},
+		{BiconditionalElimination, "p ↔ q", "q → p", true},
+		{BiconditionalElimination, "p ↔ q", "p ∧ q", false},
+		{BiconditionalIntroduction, "q → p, p → q", "p ↔ q", true},
+		{BiconditionalIntroduction, "p → q, q → r", "p ↔ r", false},
+	}
+	for _, c := range cases {
+		cited := gentest.MustParseList(t, c.cited)
+		p := Proof{}
+		cites := make([]int, len(cited))
+		for i, node := range cited {
+			p.Lines = append(p.Lines, Line{Number: i + 1, Formula: node, Rule: Premise})
+			cites[i] = i + 1
+		}
+		p.Lines = append(p.Lines, Line{Number: len(cited) + 1, Formula: gentest.MustParse(t, c.formula), Rule: c.rule, Cites: cites})
+
+		err := p.CheckLine(len(cited))
+		if (err == nil) != c.accepted {
+			t.Errorf("%s: %s ⊢ %s: got err=%v, want accepted=%v", c.rule, c.cited, c.formula, err, c.accepted)
+		}
+		if err := p.Check(cited); (err == nil) != c.accepted {
+			t.Errorf("Check %s: %s ⊢ %s: got err=%v", c.rule, c.cited, c.formula, err)
+		}
+	}
+}
+
+func TestCheckRejectsBadStructure(t *testing.T) {
+	premises := gentest.MustParseList(t, "p → q, p")
+	valid := Proof{Lines: []Line{
+		{Number: 1, Formula: premises[0], Rule: Premise},
+		{Number: 2, Formula: premises[1], Rule: Premise},
+		{Number: 3, Formula: gentest.MustParse(t, "q"), Rule: ModusPonens, Cites: []int{1, 2}},
+	}}
+	if err := valid.Check(premises); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+	if got := valid.Lines[2].String(); got != "3. q (Modus Ponens, 1, 2)" {
+		t.Errorf("line string = %q", got)
+	}
+
+	// 引用了后面的行
+	forward := Proof{Lines: []Line{
+		{Number: 1, Formula: premises[0], Rule: Premise},
+		{Number: 2, Formula: gentest.MustParse(t, "q"), Rule: ModusPonens, Cites: []int{1, 3}},
+		{Number: 3, Formula: premises[1], Rule: Premise},
+	}}
+	// 前提不在给定前提中
+	foreign := Proof{Lines: []Line{
+		{Number: 1, Formula: gentest.MustParse(t, "r"), Rule: Premise},
+	}}
+	// 编号不连续
+	gap := Proof{Lines: []Line{
+		{Number: 1, Formula: premises[0], Rule: Premise},
+		{Number: 3, Formula: premises[1], Rule: Premise},
+	}}
+	for name, p := range map[string]Proof{"forward": forward, "foreign": foreign, "gap": gap} {
+		err := p.Check(premises)
+		var checkErr *CheckError
+		if !errors.As(err, &checkErr) {
+			t.Errorf("%s: expected CheckError, got %v", name, err)
+		}
+	}
+}
+
+func TestProveTemplates(t *testing.T) {
+	cases := []struct {
+		premises string
+		goal     string
+		lines    int
+	}{
+		{"p → q, p", "q", 3},
+		{"p → q, ¬q", "¬p", 3},
+		{"p ↔ q, p", "q", 4},
+		{"p → q, q → r, p", "r", 5},
+		{"p ∨ q, ¬p, q → r", "r", 5},
+		{"p → q, q → r, ¬r", "¬p", 5},
+		{"p → (q → r), p, q", "r", 5},
+		{"p ∧ q, p → r, r → s", "s", 6},
+		{"p ↔ q, q ↔ r", "p ↔ r", 9},
+		{"p ↔ q", "(p → q) ∧ (q → p)", 4},
+		{"p ∨ ¬q, ¬p, ¬q → r", "r", 5},
+	}
+	for _, c := range cases {
+		premises := gentest.MustParseList(t, c.premises)
+		goal := gentest.MustParse(t, c.goal)
+		p, ok := Prove(premises, goal, 16)
+		if !ok {
+			t.Errorf("no proof of %s from %s", c.goal, c.premises)
+			continue
+		}
+		if err := p.Check(premises); err != nil {
+			t.Errorf("proof of %s does not check: %v\n%s", c.goal, err, p)
+		}
+		if !equal(p.Conclusion(), goal) {
+			t.Errorf("proof ends with %s, want %s", helper.Stringify(p.Conclusion()), c.goal)
+		}
+		if len(p.Lines) != c.lines {
+			t.Errorf("proof of %s has %d lines, want %d:\n%s", c.goal, len(p.Lines), c.lines, p)
+		}
+	}
+}
+
+func TestProveFailsForUnderivableGoal(t *testing.T) {
+	premises := gentest.MustParseList(t, "p → q, q")
+	if p, ok := Prove(premises, gentest.MustParse(t, "p"), 16); ok {
+		t.Errorf("affirming the consequent was proved:\n%s", p)
+	}
+	if _, ok := Prove(gentest.MustParseList(t, "p → q, q → r, r → s, p"), gentest.MustParse(t, "s"), 5); ok {
+		t.Error("proof longer than maxLines was returned")
+	}
+}
+
+func TestParseJustification(t *testing.T) {
+	cases := []struct {
+		in    string
+		rule  Rule
+		cites []int
+	}{
+		{"premise", Premise, []int{}},
+		{"MP 1, 2", ModusPonens, []int{1, 2}},
+		{"Modus Ponens, 1, 2", ModusPonens, []int{1, 2}},
+		{"modus_tollens 3 4", ModusTollens, []int{3, 4}},
+		{"∧E 3", Simplification, []int{3}},
+		{"∨I, 2", Addition, []int{2}},
+		{"<->e 1", BiconditionalElimination, []int{1}},
+	}
+	for _, c := range cases {
+		rule, cites, err := ParseJustification(c.in)
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+			continue
+		}
+		if rule != c.rule || len(cites) != len(c.cites) {
+			t.Errorf("%q: got %s %v, want %s %v", c.in, rule, cites, c.rule, c.cites)
+			continue
+		}
+		for i := range cites {
+			if cites[i] != c.cites[i] {
+				t.Errorf("%q: got cites %v, want %v", c.in, cites, c.cites)
+			}
+		}
+	}
+	for _, bad := range []string{"", "1, 2", "XYZ 1"} {
+		if _, _, err := ParseJustification(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestCheckAllReportsEveryLine(t *testing.T) {
+	premises := gentest.MustParseList(t, "p → q, p")
+	input := []struct{ formula, justification string }{
+		{"p → q", "premise"},
+		{"p", "premise"},
+		{"q", "MT 1, 2"},     // 规则用错
+		{"q ∨ r", "∨I 3"},    // 本身正确，引用的行有错也照样检查
+		{"(q", "MP 1, 2"},    // 公式无法解析
+		{"q ∧ p", "∧I 5, 2"}, // 引用了无法解析的行
+	}
+	lines := make([]Line, len(input))
+	for i, in := range input {
+		line, err := ParseLine(i+1, in.formula, in.justification)
+		if (err != nil) != (i == 4) {
+			t.Fatalf("line %d: parse error %v", i+1, err)
+		}
+		lines[i] = line
+	}
+	errs := Proof{Lines: lines}.CheckAll(premises)
+	got := make([]int, len(errs))
+	for i, e := range errs {
+		got[i] = e.Line
+	}
+	if len(got) != 2 || got[0] != 3 || got[1] != 6 {
+		t.Errorf("reported lines %v, want [3 6]", got)
+	}
+}
@@ -0,0 +1,139 @@
+package proof
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+)
+
+// searchLimit 前向搜索时最多保留的行数，防止 HS / ↔E 在长链上组合爆炸
+const searchLimit = 64
+
+// Prove searches forward from premises for goal, applying every rule to the
+// lines found so far, round by round. Conjunction and Biconditional
+// Introduction only build subformulas of goal, so the search stays finite.
+// The returned proof lists every premise first, followed by the lines the
+// goal depends on; ok is false when no proof of at most maxLines lines is
+// found.
+func Prove(premises []*core.Node, goal *core.Node, maxLines int) (Proof, bool) {
+	s := newSearch(goal)
+	for _, prem := range premises {
+		s.add(prem, Premise)
+	}
+	if idx, ok := s.seen[helper.Stringify(goal)]; ok {
+		return s.extract(idx, maxLines)
+	}
+
+	for len(s.lines) < searchLimit {
+		n := len(s.lines)
+		for i := 0; i < n; i++ {
+			for _, rule := range []Rule{Simplification, BiconditionalElimination} {
+				for _, result := range consequences(rule, []*core.Node{s.lines[i].Formula}) {
+					s.add(result, rule, i+1)
+				}
+			}
+			for j := 0; j < n; j++ {
+				if i == j {
+					continue
+				}
+				for _, rule := range Rules {
+					if rule.arity() != 2 {
+						continue
+					}
+					if result := applyBinary(rule, s.lines[i].Formula, s.lines[j].Formula); result != nil {
+						s.add(result, rule, i+1, j+1)
+					}
+				}
+			}
+		}
+		if idx, ok := s.seen[helper.Stringify(goal)]; ok {
+			return s.extract(idx, maxLines)
+		}
+		// 本轮没有新行，已饱和
+		if len(s.lines) == n {
+			break
+		}
+	}
+	return Proof{}, false
+}
+
+type search struct {
+	lines []Line
+	seen  map[string]int
+	// goalSubs 目标的全部子公式，限制 Conjunction / ↔I 的产出
+	goalSubs map[string]bool
+}
+
+func newSearch(goal *core.Node) *search {
+	s := &search{
+		seen:     make(map[string]int),
+		goalSubs: make(map[string]bool),
+	}
+	var walk func(n *core.Node)
+	walk = func(n *core.Node) {
+		if n == nil {
+			return
+		}
+		s.goalSubs[helper.Stringify(n)] = true
+		walk(n.Left)
+		walk(n.Right)
+	}
+	walk(goal)
+	return s
+}
+
+// add 追加一行；已出现的公式、A → A 这类无意义的结果以及超出上限的行都被丢弃
+func (s *search) add(formula *core.Node, rule Rule, cites ...int) {
+	if len(s.lines) >= searchLimit {
+		return
+	}
+	key := helper.Stringify(formula)
+	if _, dup := s.seen[key]; dup {
+		return
+	}
+	switch rule {
+	case HypotheticalSyllogism:
+		if equal(formula.Left, formula.Right) {
+			return
+		}
+	case Conjunction, BiconditionalIntroduction:
+		if !s.goalSubs[key] {
+			return
+		}
+	}
+	s.seen[key] = len(s.lines)
+	s.lines = append(s.lines, Line{Number: len(s.lines) + 1, Formula: formula, Rule: rule, Cites: cites})
+}
+
+// extract 保留全部前提和目标依赖的推导行，按原顺序重新编号
+func (s *search) extract(goalIdx int, maxLines int) (Proof, bool) {
+	needed := make([]bool, len(s.lines))
+	var mark func(idx int)
+	mark = func(idx int) {
+		if needed[idx] {
+			return
+		}
+		needed[idx] = true
+		for _, c := range s.lines[idx].Cites {
+			mark(c - 1)
+		}
+	}
+	mark(goalIdx)
+
+	renumber := make(map[int]int, len(s.lines))
+	out := make([]Line, 0, len(s.lines))
+	for i, l := range s.lines {
+		if l.Rule != Premise && !needed[i] {
+			continue
+		}
+		renumber[l.Number] = len(out) + 1
+		cites := make([]int, len(l.Cites))
+		for j, c := range l.Cites {
+			cites[j] = renumber[c]
+		}
+		out = append(out, Line{Number: len(out) + 1, Formula: l.Formula, Rule: l.Rule, Cites: cites})
+	}
+	if len(out) > maxLines {
+		return Proof{}, false
+	}
+	return Proof{Lines: out}, true
+}
@@ -0,0 +1,212 @@
+// Package render writes core.Node formulas in the notations clients need:
+// the canonical Unicode form, ASCII for plain-text systems, LaTeX for
+// worksheets, Polish prefix notation and spoken English for screen readers.
+// Every infix notation can either parenthesise every binary sub-formula, as
+// helper.Stringify always has, or emit only the parentheses the parser's
+// precedence requires.
+package render
+
+import (
+	"backend/generation/core"
+	"fmt"
+	"strings"
+)
+
+// Notation names an output form; the values are the ones accepted by the API.
+type Notation string
+
+const (
+	Unicode Notation = "unicode"
+	ASCII   Notation = "ascii"
+	LaTeX   Notation = "latex"
+	Prefix  Notation = "prefix"
+	Spoken  Notation = "spoken"
+)
+
+// Notations lists every supported notation.
+var Notations = []Notation{Unicode, ASCII, LaTeX, Prefix, Spoken}
+
+// ParseNotation maps an API value to a Notation; the empty string means Unicode.
+func ParseNotation(s string) (Notation, error) {
+	if s == "" {
+		return Unicode, nil
+	}
+	for _, n := range Notations {
+		if string(n) == s {
+			return n, nil
+		}
+	}
+	return "", fmt.Errorf("render: unknown notation %q", s)
+}
+
+// symbols 各记法下联结词与常量的写法
+type symbols struct {
+	not      string
+	binary   map[core.NodeKind]string
+	top, bot string
+}
+
+var notationSymbols = map[Notation]symbols{
+	Unicode: {
+		not:    "¬",
+		binary: map[core.NodeKind]string{core.And: "∧", core.Or: "∨", core.Impl: "→", core.Iff: "↔", core.Xor: "⊕", core.Nand: "↑", core.Nor: "↓"},
+		top:    "⊤", bot: "⊥",
+	},
+	// 与 parser 接受的 ASCII 别名一致，渲染结果可以直接解析回来
+	ASCII: {
+		not:    "~",
+		binary: map[core.NodeKind]string{core.And: "&", core.Or: "|", core.Impl: "->", core.Iff: "<->", core.Xor: "^", core.Nand: "!&", core.Nor: "!|"},
+		top:    "1", bot: "0",
+	},
+	LaTeX: {
+		not:    `\lnot `,
+		binary: map[core.NodeKind]string{core.And: `\land`, core.Or: `\lor`, core.Impl: `\rightarrow`, core.Iff: `\leftrightarrow`, core.Xor: `\oplus`, core.Nand: `\uparrow`, core.Nor: `\downarrow`},
+		top:    `\top`, bot: `\bot`,
+	},
+	// Łukasiewicz / Bocheński 的字母：N 否定，K 合取，A 析取，C 蕴含，E 等值，J 异或，D 与非，X 或非，V / O 为真 / 假
+	Prefix: {
+		not:    "N",
+		binary: map[core.NodeKind]string{core.And: "K", core.Or: "A", core.Impl: "C", core.Iff: "E", core.Xor: "J", core.Nand: "D", core.Nor: "X"},
+		top:    "V", bot: "O",
+	},
+	Spoken: {
+		not:    "not ",
+		binary: map[core.NodeKind]string{core.And: "and", core.Or: "or", core.Impl: "implies", core.Iff: "if and only if", core.Xor: "xor", core.Nand: "nand", core.Nor: "nor"},
+		top:    "true", bot: "false",
+	},
+}
+
+// symbolKinds 规范 Unicode 写法对应的二元联结词
+var symbolKinds = map[string]core.NodeKind{"∧": core.And, "∨": core.Or, "→": core.Impl, "↔": core.Iff, "⊕": core.Xor, "↑": core.Nand, "↓": core.Nor}
+
+// Renderer writes formulas in one notation.
+type Renderer struct {
+	Notation Notation
+	// MinimalParens drops the parentheses that precedence and associativity
+	// already imply. Prefix notation never needs any.
+	MinimalParens bool
+}
+
+// Canonical is the fully parenthesised Unicode form used throughout generation.
+var Canonical = Renderer{Notation: Unicode}
+
+// IsCanonical reports whether r produces the same text as Canonical.
+func (r Renderer) IsCanonical() bool {
+	return (r.Notation == Unicode || r.Notation == "") && !r.MinimalParens
+}
+
+// Render writes node in r's notation. Unknown notations fall back to Unicode.
+func (r Renderer) Render(node *core.Node) string {
+	if node == nil {
+		return ""
+	}
+	syms, ok := notationSymbols[r.Notation]
+	if !ok {
+		syms = notationSymbols[Unicode]
+	}
+	var b strings.Builder
+	if r.Notation == Prefix {
+		writePrefix(&b, node, syms)
+	} else {
+		r.writeInfix(&b, node, syms)
+	}
+	return b.String()
+}
+
+// Embed renders node for inclusion in running text: LaTeX is wrapped in
+// inline math delimiters, the other notations are returned unchanged.
+func (r Renderer) Embed(node *core.Node) string {
+	s := r.Render(node)
+	if r.Notation == LaTeX {
+		return "$" + s + "$"
+	}
+	return s
+}
+
+func (r Renderer) writeInfix(b *strings.Builder, node *core.Node, syms symbols) {
+	switch node.Kind {
+	case core.Var:
+		b.WriteString(node.Name)
+	case core.True:
+		b.WriteString(syms.top)
+	case core.False:
+		b.WriteString(syms.bot)
+	case core.Not:
+		b.WriteString(syms.not)
+		r.writeChild(b, node.Left, isBinary(node.Left), syms)
+	default:
+		r.writeChild(b, node.Left, r.wrap(node, node.Left, false), syms)
+		b.WriteString(" " + syms.binary[node.Kind] + " ")
+		r.writeChild(b, node.Right, r.wrap(node, node.Right, true), syms)
+	}
+}
+
+func (r Renderer) writeChild(b *strings.Builder, child *core.Node, wrap bool, syms symbols) {
+	if wrap {
+		b.WriteString("(")
+	}
+	r.writeInfix(b, child, syms)
+	if wrap {
+		b.WriteString(")")
+	}
+}
+
+// wrap 判断二元联结词 parent 的子公式是否需要括号；完整模式下二元子公式一律加括号
+func (r Renderer) wrap(parent, child *core.Node, right bool) bool {
+	if !isBinary(child) {
+		return false
+	}
+	if !r.MinimalParens {
+		return true
+	}
+	pp, cp := precedence[parent.Kind], precedence[child.Kind]
+	if cp != pp {
+		return cp < pp
+	}
+	// 同一优先级的不同联结词（如 ∨ 与 ⊕）总是加括号，避免读者依赖结合方向
+	if child.Kind != parent.Kind {
+		return true
+	}
+	// 与 parser 的结合方向一致：→ ↔ 右结合，其余左结合
+	if rightAssoc[parent.Kind] {
+		return !right
+	}
+	return right
+}
+
+// precedence 与 parser 一致，数值越大结合越紧
+var precedence = map[core.NodeKind]int{
+	core.Iff:  1,
+	core.Impl: 2,
+	core.Or:   3, core.Xor: 3, core.Nor: 3,
+	core.And: 4, core.Nand: 4,
+}
+
+var rightAssoc = map[core.NodeKind]bool{core.Impl: true, core.Iff: true}
+
+func isBinary(node *core.Node) bool {
+	_, ok := precedence[node.Kind]
+	return ok
+}
+
+// writePrefix 波兰记法，记号之间用空格分隔，多字母变量名也不会产生歧义
+func writePrefix(b *strings.Builder, node *core.Node, syms symbols) {
+	if b.Len() > 0 {
+		b.WriteString(" ")
+	}
+	switch node.Kind {
+	case core.Var:
+		b.WriteString(node.Name)
+	case core.True:
+		b.WriteString(syms.top)
+	case core.False:
+		b.WriteString(syms.bot)
+	case core.Not:
+		b.WriteString(syms.not)
+		writePrefix(b, node.Left, syms)
+	default:
+		b.WriteString(syms.binary[node.Kind])
+		writePrefix(b, node.Left, syms)
+		writePrefix(b, node.Right, syms)
+	}
+}
@@ -0,0 +1,129 @@
+package render_test
+
+import (
+	"backend/generation/core"
+	"backend/generation/generator/shared"
+	"backend/generation/parser"
+	"backend/generation/render"
+	"backend/generation/sampler"
+	"math/rand/v2"
+	"testing"
+)
+
+func mustParse(t *testing.T, s string) *core.Node {
+	t.Helper()
+	node, err := parser.Parse(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return node
+}
+
+func TestRenderNotations(t *testing.T) {
+	node := mustParse(t, "¬p ∧ q → (r ↔ ⊥)")
+	cases := []struct {
+		r    render.Renderer
+		want string
+	}{
+		{render.Renderer{Notation: render.Unicode}, "(¬p ∧ q) → (r ↔ ⊥)"},
+		{render.Renderer{Notation: render.Unicode, MinimalParens: true}, "¬p ∧ q → (r ↔ ⊥)"},
+		{render.Renderer{Notation: render.ASCII}, "(~p & q) -> (r <-> 0)"},
+		{render.Renderer{Notation: render.LaTeX}, `(\lnot p \land q) \rightarrow (r \leftrightarrow \bot)`},
+		{render.Renderer{Notation: render.Prefix}, "C K N p q E r O"},
+		{render.Renderer{Notation: render.Prefix, MinimalParens: true}, "C K N p q E r O"},
+		{render.Renderer{Notation: render.Spoken, MinimalParens: true}, "not p and q implies (r if and only if false)"},
+	}
+	for _, c := range cases {
+		if got := c.r.Render(node); got != c.want {
+			t.Errorf("%+v: got %q, want %q", c.r, got, c.want)
+		}
+	}
+}
+
+func TestMinimalParens(t *testing.T) {
+	r := render.Renderer{Notation: render.Unicode, MinimalParens: true}
+	cases := map[string]string{
+		"p ∧ q ∧ r":         "p ∧ q ∧ r",
+		"p ∧ (q ∧ r)":       "p ∧ (q ∧ r)",
+		"(p → q) → r":       "(p → q) → r",
+		"p → (q → r)":       "p → q → r",
+		"(p ∨ q) ⊕ r":       "(p ∨ q) ⊕ r",
+		"p ↑ q ∨ r":         "p ↑ q ∨ r",
+		"¬(p ∧ q) ∨ ¬¬r":    "¬(p ∧ q) ∨ ¬¬r",
+		"(p ↔ q) ↔ (r ↓ s)": "(p ↔ q) ↔ r ↓ s",
+	}
+	for input, want := range cases {
+		if got := r.Render(mustParse(t, input)); got != want {
+			t.Errorf("render %q = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// TestRoundTrip 渲染后的 Unicode 与 ASCII 文本解析回来应得到同一棵树
+func TestRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 9))
+	prof := sampler.Profile{
+		Vars:       4,
+		MaxDepth:   4,
+		AllowedOps: []core.NodeKind{core.Not, core.And, core.Or, core.Impl, core.Iff, core.Xor, core.Nand, core.Nor, core.True, core.False},
+	}
+	renderers := []render.Renderer{
+		{Notation: render.Unicode},
+		{Notation: render.Unicode, MinimalParens: true},
+		{Notation: render.ASCII},
+		{Notation: render.ASCII, MinimalParens: true},
+	}
+	for i := 0; i < 200; i++ {
+		node := shared.RandomFormula(rng, prof)
+		want := render.Canonical.Render(node)
+		for _, r := range renderers {
+			text := r.Render(node)
+			parsed, err := parser.Parse(text)
+			if err != nil {
+				t.Fatalf("%+v: parse %q: %v", r, text, err)
+			}
+			if got := render.Canonical.Render(parsed); got != want {
+				t.Fatalf("%+v: %q parsed as %q, want %q", r, text, got, want)
+			}
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	cases := []struct {
+		r    render.Renderer
+		in   string
+		want string
+	}{
+		{render.Renderer{Notation: render.ASCII}, "Which formula is equivalent to p → (q ∧ ¬r)?", "Which formula is equivalent to p -> (q & ~r)?"},
+		{render.Renderer{Notation: render.LaTeX}, "Is ¬p a consequence of p → q and ¬q?", `Is $\lnot p$ a consequence of $p \rightarrow q$ and $\lnot q$?`},
+		{render.Renderer{Notation: render.Spoken, MinimalParens: true}, "3. ¬q → ¬p (Modus Tollens, 1, 2)", "3. not q implies not p (Modus Tollens, 1, 2)"},
+		{render.Renderer{Notation: render.Unicode, MinimalParens: true}, "the clauses (p ∧ q) ∨ ¬r hold", "the clauses p ∧ q ∨ ¬r hold"},
+		{render.Renderer{Notation: render.ASCII}, "{p, ¬q} under p=T, q=F", "{p, ~q} under p=T, q=F"},
+		{render.Renderer{Notation: render.Prefix}, "the branch closes because it contains ⊥", "the branch closes because it contains O"},
+		{render.Renderer{Notation: render.ASCII}, "a ¬p", "a ~p"},
+		{render.Renderer{Notation: render.ASCII}, "Answer: (p ∧ q)", "Answer: (p & q)"},
+		{render.Renderer{Notation: render.ASCII}, "no formula here", "no formula here"},
+		{render.Renderer{Notation: render.Spoken}, "rewriting → / ↔, pushing ¬ inward", "rewriting implies / if and only if, pushing not inward"},
+		{render.Renderer{Notation: render.LaTeX}, "pushing ¬ inward", `pushing $\lnot$ inward`},
+	}
+	for _, c := range cases {
+		if got := c.r.Text(c.in); got != c.want {
+			t.Errorf("%+v: Text(%q) = %q, want %q", c.r, c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseNotation(t *testing.T) {
+	if n, err := render.ParseNotation(""); err != nil || n != render.Unicode {
+		t.Errorf("empty notation: got %q, %v", n, err)
+	}
+	for _, want := range render.Notations {
+		if n, err := render.ParseNotation(string(want)); err != nil || n != want {
+			t.Errorf("%s: got %q, %v", want, n, err)
+		}
+	}
+	if _, err := render.ParseNotation("morse"); err == nil {
+		t.Errorf("expected an error for an unknown notation")
+	}
+}
@@ -0,0 +1,196 @@
+package render
+
+import (
+	"backend/generation/parser"
+	"strings"
+	"unicode"
+)
+
+// formulaSymbols 生成的文本中出现的 Unicode 联结词与常量
+const formulaSymbols = "¬∧∨→↔⊕⊻↑↓⊤⊥"
+
+// Text rewrites the canonical Unicode formulas embedded in generated text
+// (question stems, options, explanations) into r's notation. A formula is a
+// run of single-letter variables, connectives, constants, parentheses and
+// spaces that the parser accepts; everything else, including lone variables,
+// is left untouched. For Canonical the text is returned as is.
+func (r Renderer) Text(s string) string {
+	if r.IsCanonical() || !strings.ContainsAny(s, formulaSymbols) {
+		return s
+	}
+	runes := []rune(s)
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		end := formulaRunEnd(runes, i)
+		if end == i {
+			// 不能出现在公式中的字符，多字母单词整体跳过，避免把单词的首字母当作变量
+			j := i + 1
+			if isWordRune(runes[i]) {
+				for j < len(runes) && isWordRune(runes[j]) {
+					j++
+				}
+			}
+			b.WriteString(string(runes[i:j]))
+			i = j
+			continue
+		}
+		b.WriteString(r.rewriteRun(string(runes[i:end])))
+		i = end
+	}
+	return b.String()
+}
+
+// formulaRunEnd 从 i 开始的公式片段的结束位置；i 处不能开始公式片段时返回 i
+func formulaRunEnd(runes []rune, i int) int {
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '(' || r == ')' || strings.ContainsRune(formulaSymbols, r):
+			i++
+		case unicode.IsLetter(r):
+			// 只有单个字母构成的单词才是变量
+			if i+1 < len(runes) && isWordRune(runes[i+1]) {
+				return i
+			}
+			if i > 0 && isWordRune(runes[i-1]) {
+				return i
+			}
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// rewriteRun 按未配对的括号切分片段，例如 "¬q → ¬p (" 中的 "(" 属于后面的正文
+func (r Renderer) rewriteRun(run string) string {
+	runes := []rune(run)
+	unmatched := make(map[int]bool)
+	open := make([]int, 0)
+	for i, c := range runes {
+		switch c {
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) == 0 {
+				unmatched[i] = true
+			} else {
+				open = open[:len(open)-1]
+			}
+		}
+	}
+	for _, i := range open {
+		unmatched[i] = true
+	}
+
+	var b strings.Builder
+	start := 0
+	for i := range runes {
+		if unmatched[i] {
+			b.WriteString(r.rewriteSegment(string(runes[start:i])))
+			b.WriteRune(runes[i])
+			start = i + 1
+		}
+	}
+	b.WriteString(r.rewriteSegment(string(runes[start:])))
+	return b.String()
+}
+
+// rewriteSegment 改写括号配对的片段，保留首尾空白以及包住整个公式的正文括号
+func (r Renderer) rewriteSegment(seg string) string {
+	body := strings.TrimSpace(seg)
+	if !strings.ContainsAny(body, formulaSymbols) {
+		return seg
+	}
+	lead := seg[:strings.Index(seg, body)]
+	trail := seg[len(lead)+len(body):]
+	if enclosed(body) {
+		return lead + "(" + r.rewriteSegment(body[1:len(body)-1]) + ")" + trail
+	}
+	if out, ok := r.rewriteFormula(body); ok {
+		return lead + out + trail
+	}
+	if out, ok := r.rewriteSymbol(body); ok {
+		return lead + out + trail
+	}
+	// 片段两端可能混入单字母的单词（如 "a ¬p"），逐个去掉后再试
+	for i := strings.LastIndex(body, " "); i > 0; i = strings.LastIndex(body[:i], " ") {
+		if out, ok := r.rewriteFormula(body[:i]); ok {
+			return lead + out + body[i:] + trail
+		}
+	}
+	for i := strings.Index(body, " "); i >= 0 && i < len(body)-1; {
+		if out, ok := r.rewriteFormula(body[i+1:]); ok {
+			return lead + body[:i+1] + out + trail
+		}
+		next := strings.Index(body[i+1:], " ")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return seg
+}
+
+func (r Renderer) rewriteFormula(s string) (string, bool) {
+	if !strings.ContainsAny(s, formulaSymbols) {
+		return "", false
+	}
+	node, err := parser.Parse(s)
+	if err != nil {
+		return "", false
+	}
+	return r.Embed(node), true
+}
+
+// rewriteSymbol 改写正文中单独提到的联结词，例如 "pushing ¬ inward"
+func (r Renderer) rewriteSymbol(s string) (string, bool) {
+	syms, ok := notationSymbols[r.Notation]
+	if !ok {
+		return "", false
+	}
+	var out string
+	switch s {
+	case "¬":
+		out = strings.TrimSpace(syms.not)
+	case "⊤":
+		out = syms.top
+	case "⊥":
+		out = syms.bot
+	default:
+		kind, ok := symbolKinds[s]
+		if !ok {
+			return "", false
+		}
+		out = syms.binary[kind]
+	}
+	if r.Notation == LaTeX {
+		out = "$" + out + "$"
+	}
+	return out, true
+}
+
+// enclosed 判断 s 是否被一对互相匹配的括号完整包住
+func enclosed(s string) bool {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return false
+	}
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i == len(s)-1
+			}
+		}
+	}
+	return false
+}
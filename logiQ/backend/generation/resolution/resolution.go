@@ -0,0 +1,204 @@
+// Package resolution implements propositional resolution. Premises and the
+// negated conclusion are converted to a set of clauses; a bounded saturation
+// search derives the empty clause when that set is unsatisfiable, i.e. when
+// the conclusion follows from the premises.
+package resolution
+
+import (
+	"backend/generation/core"
+	"backend/generation/normalform"
+	"fmt"
+	"strings"
+)
+
+// Empty renders the empty clause.
+const Empty = "□"
+
+// Step records one resolution: Left and Right are resolved on Var, which
+// occurs positively in one of them and negated in the other.
+type Step struct {
+	Left      normalform.Clause
+	Right     normalform.Clause
+	Resolvent normalform.Clause
+	Var       string
+}
+
+// String renders the step, e.g. "{p, q}, {¬p} ⊢ {q}".
+func (s Step) String() string {
+	return fmt.Sprintf("%s, %s ⊢ %s", FormatClause(s.Left), FormatClause(s.Right), FormatClause(s.Resolvent))
+}
+
+// Refutation derives the empty clause from Clauses. Steps are in derivation
+// order; every clause they resolve is either in Clauses or the resolvent of
+// an earlier step, and the last step yields the empty clause.
+type Refutation struct {
+	Clauses normalform.CNF
+	Steps   []Step
+}
+
+// String lists the steps, one per line.
+func (r Refutation) String() string {
+	lines := make([]string, len(r.Steps))
+	for i, s := range r.Steps {
+		lines[i] = fmt.Sprintf("%d. %s", i+1, s)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatClause renders a clause in set notation, e.g. "{p, ¬q}", and the
+// empty clause as "□".
+func FormatClause(c normalform.Clause) string {
+	if len(c) == 0 {
+		return Empty
+	}
+	lits := make([]string, len(c))
+	for i, l := range c {
+		lits[i] = l.String()
+	}
+	return "{" + strings.Join(lits, ", ") + "}"
+}
+
+// FormatSet renders a clause set, e.g. "{p, q}, {¬p}, {¬q}".
+func FormatSet(clauses normalform.CNF) string {
+	parts := make([]string, len(clauses))
+	for i, c := range clauses {
+		parts[i] = FormatClause(c)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ClauseSet converts premises and the negation of conclusion to one clause
+// set. A nil conclusion leaves only the premises. Tautological and subsumed
+// clauses are dropped.
+func ClauseSet(premises []*core.Node, conclusion *core.Node, limits normalform.Limits) (normalform.CNF, error) {
+	formulas := append([]*core.Node(nil), premises...)
+	if conclusion != nil {
+		formulas = append(formulas, &core.Node{Kind: core.Not, Left: conclusion})
+	}
+	set := normalform.CNF{}
+	for _, f := range formulas {
+		cnf, err := normalform.ToCNF(f, limits)
+		if err != nil {
+			return nil, err
+		}
+		set = set.And(cnf)
+	}
+	return set, nil
+}
+
+// Resolvents returns every resolution of a and b, one per variable that
+// occurs positively in one clause and negated in the other. Resolvents that
+// contain a literal together with its negation are useless and omitted.
+func Resolvents(a, b normalform.Clause) []Step {
+	var out []Step
+	for _, l := range a {
+		comp := normalform.Literal{Var: l.Var, Negated: !l.Negated}
+		if !contains(b, comp) {
+			continue
+		}
+		merged := append(a.Without(l), b.Without(comp)...)
+		if resolvent, ok := normalform.NormalizeClause(merged); ok {
+			out = append(out, Step{Left: a, Right: b, Resolvent: resolvent, Var: l.Var})
+		}
+	}
+	return out
+}
+
+// Refute saturates clauses level by level, resolving every pair that involves
+// a clause from the previous level, until the empty clause appears. Resolvents
+// already present or subsumed by a known clause are discarded. ok is false
+// when the set saturates without the empty clause (it is satisfiable) or more
+// than maxClauses clauses would be needed.
+func Refute(clauses normalform.CNF, maxClauses int) (Refutation, bool) {
+	known := make([]normalform.Clause, 0, len(clauses))
+	index := make(map[string]int)
+	// steps 记录每个推导出的子句来自哪一步，输入子句没有记录
+	steps := make(map[int]Step)
+	for _, c := range clauses {
+		if len(c) == 0 {
+			return Refutation{Clauses: clauses}, true
+		}
+		index[FormatClause(c)] = len(known)
+		known = append(known, c)
+	}
+
+	processed := 0
+	for {
+		n := len(known)
+		for j := processed; j < n; j++ {
+			for i := 0; i < j; i++ {
+				for _, step := range Resolvents(known[i], known[j]) {
+					key := FormatClause(step.Resolvent)
+					if _, dup := index[key]; dup || subsumed(known, step.Resolvent) {
+						continue
+					}
+					if len(known) >= maxClauses {
+						return Refutation{}, false
+					}
+					index[key] = len(known)
+					steps[len(known)] = step
+					known = append(known, step.Resolvent)
+					if len(step.Resolvent) == 0 {
+						return extract(clauses, known, index, steps), true
+					}
+				}
+			}
+		}
+		// 本轮没有新子句，已饱和
+		if len(known) == n {
+			return Refutation{}, false
+		}
+		processed = n
+	}
+}
+
+// extract 只保留空子句依赖的步骤，按推导顺序排列
+func extract(clauses normalform.CNF, known []normalform.Clause, index map[string]int, steps map[int]Step) Refutation {
+	needed := make([]bool, len(known))
+	var mark func(idx int)
+	mark = func(idx int) {
+		if needed[idx] {
+			return
+		}
+		needed[idx] = true
+		if step, ok := steps[idx]; ok {
+			mark(index[FormatClause(step.Left)])
+			mark(index[FormatClause(step.Right)])
+		}
+	}
+	mark(len(known) - 1)
+
+	out := Refutation{Clauses: clauses}
+	for idx := range known {
+		if step, ok := steps[idx]; ok && needed[idx] {
+			out.Steps = append(out.Steps, step)
+		}
+	}
+	return out
+}
+
+func contains(c normalform.Clause, l normalform.Literal) bool {
+	for _, x := range c {
+		if x == l {
+			return true
+		}
+	}
+	return false
+}
+
+// subsumed 判断 c 是否包含某个已知子句的全部文字
+func subsumed(known []normalform.Clause, c normalform.Clause) bool {
+	for _, k := range known {
+		all := true
+		for _, l := range k {
+			if !contains(c, l) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package resolution
+
+import (
+	"backend/generation/gentest"
+	"backend/generation/normalform"
+	"backend/generation/validator"
+	"testing"
+)
+
+func clause(lits ...string) normalform.Clause {
+	c := make(normalform.Clause, len(lits))
+	for i, l := range lits {
+		if l[0] == '~' {
+			c[i] = normalform.Literal{Var: l[1:], Negated: true}
+		} else {
+			c[i] = normalform.Literal{Var: l}
+		}
+	}
+	return c
+}
+
+func TestResolvents(t *testing.T) {
+	cases := []struct {
+		left, right normalform.Clause
+		want        []string
+	}{
+		{clause("p", "q"), clause("~p"), []string{"{q}"}},
+		{clause("~p", "q"), clause("p", "r"), []string{"{q, r}"}},
+		{clause("p"), clause("~p"), []string{Empty}},
+		{clause("p", "q"), clause("q", "r"), nil},
+		// 两对互补文字：每个结果都含互补文字，全部丢弃
+		{clause("p", "q"), clause("~p", "~q"), nil},
+	}
+	for _, c := range cases {
+		got := Resolvents(c.left, c.right)
+		if len(got) != len(c.want) {
+			t.Fatalf("Resolvents(%s, %s) = %v, want %v", FormatClause(c.left), FormatClause(c.right), got, c.want)
+		}
+		for i, step := range got {
+			if s := FormatClause(step.Resolvent); s != c.want[i] {
+				t.Errorf("Resolvents(%s, %s)[%d] = %s, want %s", FormatClause(c.left), FormatClause(c.right), i, s, c.want[i])
+			}
+		}
+	}
+}
+
+func TestClauseSet(t *testing.T) {
+	premises := gentest.MustParseList(t, "p → q, q → r")
+	conclusion := gentest.MustParse(t, "p → r")
+	set, err := ClauseSet(premises, conclusion, normalform.DefaultLimits)
+	if err != nil {
+		t.Fatalf("ClauseSet: %v", err)
+	}
+	if got, want := FormatSet(set), "{p}, {¬r}, {¬p, q}, {¬q, r}"; got != want {
+		t.Errorf("ClauseSet = %s, want %s", got, want)
+	}
+}
+
+func TestRefute(t *testing.T) {
+	v := validator.NewDefaultValidator()
+	cases := []struct {
+		premises   string
+		conclusion string
+		valid      bool
+	}{
+		{"p → q, p", "q", true},
+		{"p → q, ¬q", "¬p", true},
+		{"p ∨ q, ¬p", "q", true},
+		{"p → q, q → r, r → s", "p → s", true},
+		{"(p ∨ q) → r, p", "r", true},
+		{"p → q, q", "p", false},
+		{"p ∨ q", "p", false},
+		{"p → q, q → r", "r → p", false},
+	}
+	for _, c := range cases {
+		premises := gentest.MustParseList(t, c.premises)
+		conclusion := gentest.MustParse(t, c.conclusion)
+		set, err := ClauseSet(premises, conclusion, normalform.DefaultLimits)
+		if err != nil {
+			t.Fatalf("ClauseSet(%s ⊢ %s): %v", c.premises, c.conclusion, err)
+		}
+		ref, ok := Refute(set, 64)
+		if ok != c.valid {
+			t.Errorf("Refute(%s) = %v, want %v", FormatSet(set), ok, c.valid)
+			continue
+		}
+		if v.Derivable(premises, conclusion, set.Vars()) != c.valid {
+			t.Fatalf("validator disagrees on %s ⊢ %s", c.premises, c.conclusion)
+		}
+		if !ok {
+			continue
+		}
+
+		// 每一步都是合法的消解，父子句来自输入或更早的步骤，最后一步得到空子句
+		available := make(map[string]bool)
+		for _, cl := range set {
+			available[FormatClause(cl)] = true
+		}
+		for i, step := range ref.Steps {
+			if !available[FormatClause(step.Left)] || !available[FormatClause(step.Right)] {
+				t.Errorf("%s: step %d resolves clauses not yet derived: %s", c.premises, i+1, step)
+			}
+			legal := false
+			for _, r := range Resolvents(step.Left, step.Right) {
+				legal = legal || FormatClause(r.Resolvent) == FormatClause(step.Resolvent)
+			}
+			if !legal {
+				t.Errorf("%s: step %d is not a resolution: %s", c.premises, i+1, step)
+			}
+			available[FormatClause(step.Resolvent)] = true
+		}
+		if last := ref.Steps[len(ref.Steps)-1]; len(last.Resolvent) != 0 {
+			t.Errorf("%s: refutation ends with %s, want %s", c.premises, FormatClause(last.Resolvent), Empty)
+		}
+	}
+}
+
+func TestRefuteRespectsLimit(t *testing.T) {
+	premises := gentest.MustParseList(t, "p → q, q → r, r → s, s → t")
+	conclusion := gentest.MustParse(t, "p → t")
+	set, err := ClauseSet(premises, conclusion, normalform.DefaultLimits)
+	if err != nil {
+		t.Fatalf("ClauseSet: %v", err)
+	}
+	if _, ok := Refute(set, len(set)); ok {
+		t.Errorf("Refute with no room for resolvents succeeded")
+	}
+	if _, ok := Refute(set, 64); !ok {
+		t.Errorf("Refute(%s) failed", FormatSet(set))
+	}
+}
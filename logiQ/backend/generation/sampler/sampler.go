@@ -21,18 +21,24 @@ type Plan struct {
 
 // EqProfile 等价题型的配置文件
 type EqProfile struct {
-	ChainSteps int
+	ChainSteps         int
+	MaxDistractorSteps int // 0 表示不限制
+	MaxExprLength      int // 0 表示使用 shared.MAX_EXPR_LENGTH
 }
 
 // InfProfile 推理题型的配置文件
 type InfProfile struct {
 	ChainSteps int
+	// Letters 非 nil 时模板实例化后用它随机改名变量；它是独立的随机流，不影响生成用的 rng。
+	// nil 时保留按槽位顺序绑定的字母，重放随机字母之前的 blueprint 时如此
+	Letters *rand.Rand
 }
 
 // Profile 配置文件（Profile）定义了题目生成的参数，Generator 负责使用。
 type Profile struct {
 	Vars       int
 	MaxDepth   int
+	MinVars    int // 随机公式至少用到的变量数，0 表示至少两个
 	AllowedOps []core.NodeKind
 	EqProfile  EqProfile
 	InfProfile InfProfile
@@ -85,19 +91,25 @@ func (s Sampler) samplePlan(rng *rand.Rand, difficulty models.QuestionDifficulty
 		catDist := cfg.Planner.CategoryWeights[planDifficulty]
 		planCategory = helper.SampleWeighted(catDist, rng)
 	}
-	// 题型
-	planQType := qType
-	if planQType == "" {
-		typeDist := cfg.Planner.TypeWeights[planDifficulty]
-		planQType = helper.SampleWeighted(typeDist, rng)
-	}
-
 	// Intent
 	intentDistByDifficulty, ok := cfg.Planner.IntentWeights[planCategory]
 	if !ok {
 		return Plan{}, fmt.Errorf("sampler: no intent weights for category %s", planCategory)
 	}
 	intentDist := intentDistByDifficulty[planDifficulty]
+
+	// 题型
+	// 只在该类别有可用意图的题型中抽样，例如证明题没有多选意图
+	planQType := qType
+	if planQType == "" {
+		typeDist := make(map[models.QuestionType]float64)
+		for t, weight := range cfg.Planner.TypeWeights[planDifficulty] {
+			if s.hasIntentFor(intentDist, t) {
+				typeDist[t] = weight
+			}
+		}
+		planQType = helper.SampleWeighted(typeDist, rng)
+	}
 	// 过滤掉权重为0的意图，以及不支持当前题型的意图
 	// 比如说type抽到了True/False，但是抽到了true assignment的intent，它只支持SC和MC，就不能用
 	filtered := make(map[string]float64)
@@ -131,13 +143,54 @@ func (s Sampler) samplePlan(rng *rand.Rand, difficulty models.QuestionDifficulty
 	return plan, nil
 }
 
+// hasIntentFor 判断意图分布中是否有权重为正且支持该题型的意图
+func (s Sampler) hasIntentFor(intentDist map[string]float64, qType models.QuestionType) bool {
+	for name, weight := range intentDist {
+		spec, ok := s.cfg.Intents[name]
+		if ok && weight > 0 && len(spec.Templates.TemplatesFor(qType)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// profileOverride 返回类别配置中针对该难度的 Profile 覆盖项
+func (s Sampler) profileOverride(plan Plan) (config.ProfileOverride, bool) {
+	switch plan.Category {
+	case models.QuestionCategoryEquivalence:
+		diffCfg, ok := s.cfg.Equivalence.Difficulty[plan.Difficulty]
+		return diffCfg.ProfileOverride, ok
+	case models.QuestionCategoryNormalForm:
+		diffCfg, ok := s.cfg.NormalForm.Difficulty[plan.Difficulty]
+		return diffCfg.ProfileOverride, ok
+	default:
+		return config.ProfileOverride{}, false
+	}
+}
+
 // sampleProfile 根据 Plan 抽样 Profile
 func (s Sampler) sampleProfile(rng *rand.Rand, plan Plan) (Profile, error) {
 	cfg := s.cfg
 
-	vars := helper.SampleWeighted(cfg.DifficultyProfiles[plan.Difficulty].VarsDist, rng)
-	maxDepth := helper.SampleWeighted(cfg.DifficultyProfiles[plan.Difficulty].DepthDist, rng)
+	varsDist := cfg.DifficultyProfiles[plan.Difficulty].VarsDist
+	depthDist := cfg.DifficultyProfiles[plan.Difficulty].DepthDist
 	allowedOpsOrig := cfg.DifficultyProfiles[plan.Difficulty].AllowedOps
+	minVars := 0
+	// 部分类别可以单独配置变量数、深度和运算符，例如变量较多的等价难题、规模受限的范式题
+	if override, ok := s.profileOverride(plan); ok {
+		if len(override.VarsDist) > 0 {
+			varsDist = override.VarsDist
+		}
+		if len(override.DepthDist) > 0 {
+			depthDist = override.DepthDist
+		}
+		if len(override.AllowedOps) > 0 {
+			allowedOpsOrig = override.AllowedOps
+		}
+		minVars = override.MinVars
+	}
+	vars := helper.SampleWeighted(varsDist, rng)
+	maxDepth := helper.SampleWeighted(depthDist, rng)
 	allowedOps := make([]core.NodeKind, 0, len(allowedOpsOrig))
 	for _, opStr := range allowedOpsOrig {
 		op, ok := config.OpNameToKind[strings.ToUpper(opStr)]
@@ -149,6 +202,7 @@ func (s Sampler) sampleProfile(rng *rand.Rand, plan Plan) (Profile, error) {
 	profile := Profile{
 		Vars:       vars,
 		MaxDepth:   maxDepth,
+		MinVars:    minVars,
 		AllowedOps: allowedOps,
 	}
 	// Equivalence 题型，额外采样链长
@@ -159,12 +213,15 @@ func (s Sampler) sampleProfile(rng *rand.Rand, plan Plan) (Profile, error) {
 		}
 		chainSteps := helper.SampleWeighted(diffCfg.ChainStepsDist, rng)
 		eqProfile := EqProfile{
-			ChainSteps: chainSteps,
+			ChainSteps:         chainSteps,
+			MaxDistractorSteps: diffCfg.MaxDistractorSteps,
+			MaxExprLength:      diffCfg.MaxExprLength,
 		}
 		profile.EqProfile = eqProfile
 	}
-	// Inference 题型，额外采样链长和冗余前提数量
-	if plan.Category == models.QuestionCategoryInference {
+	// Inference 题型，额外采样链长和冗余前提数量；证明题、消解题、表格题复用推理模板，同样按链长选模板
+	switch plan.Category {
+	case models.QuestionCategoryInference, models.QuestionCategoryProof, models.QuestionCategoryResolution, models.QuestionCategoryTableau:
 		diffCfg, ok := cfg.Inference.Difficulty[plan.Difficulty]
 		if !ok {
 			return Profile{}, fmt.Errorf("sampler: inference difficulty %s not configured", plan.Difficulty)
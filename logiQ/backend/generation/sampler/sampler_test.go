@@ -2,9 +2,11 @@ package sampler
 
 import (
 	"backend/generation/config"
+	"backend/generation/core"
 	"backend/models"
 	"encoding/json"
 	"math/rand/v2"
+	"slices"
 	"testing"
 	"time"
 )
@@ -38,3 +40,44 @@ func TestSample(t *testing.T) {
 	t.Logf("Sampled Profile: %s", jsonProfile)
 
 }
+
+// TestAllowedOpsOverride ⊕ 等联结词不在全局运算符集合中，由类别配置中的 allowed_ops 单独启用
+func TestAllowedOpsOverride(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	extended := func(ops []core.NodeKind) bool {
+		return slices.ContainsFunc(ops, func(op core.NodeKind) bool {
+			return op == core.Xor || op == core.Nand || op == core.Nor || op == core.True || op == core.False
+		})
+	}
+
+	profile, err := NewSampler(cfg).sampleProfile(rng, Plan{Difficulty: models.QuestionDifficultyHard, Category: models.QuestionCategoryTruthTable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extended(profile.AllowedOps) {
+		t.Errorf("hard truth-table profile enables %v", profile.AllowedOps)
+	}
+	plan := Plan{Difficulty: models.QuestionDifficultyHard, Category: models.QuestionCategoryEquivalence}
+	profile, err = NewSampler(cfg).sampleProfile(rng, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !extended(profile.AllowedOps) {
+		t.Errorf("hard equivalence profile does not enable the extended connectives: %v", profile.AllowedOps)
+	}
+
+	hard := cfg.Equivalence.Difficulty[models.QuestionDifficultyHard]
+	hard.AllowedOps = []string{"AND", "XOR", "TRUE"}
+	cfg.Equivalence.Difficulty[models.QuestionDifficultyHard] = hard
+	profile, err = NewSampler(cfg).sampleProfile(rng, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []core.NodeKind{core.And, core.Xor, core.True}; !slices.Equal(profile.AllowedOps, want) {
+		t.Errorf("AllowedOps = %v, want %v", profile.AllowedOps, want)
+	}
+}
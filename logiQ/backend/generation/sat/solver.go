@@ -0,0 +1,368 @@
+// Package sat implements a small CDCL SAT solver and a Tseitin encoder for
+// core.Node formulas. It is sized for generation workloads: a few hundred
+// variables and clauses per query, solved in-process without allocation-heavy
+// truth table enumeration.
+package sat
+
+// Lit is a literal: variable v is encoded as 2v (positive) or 2v+1 (negated).
+type Lit int
+
+// PosLit returns the positive literal of variable v.
+func PosLit(v int) Lit { return Lit(v << 1) }
+
+// NegLit returns the negative literal of variable v.
+func NegLit(v int) Lit { return Lit(v<<1 | 1) }
+
+// Var returns the variable of the literal.
+func (l Lit) Var() int { return int(l >> 1) }
+
+// Neg reports whether the literal is negated.
+func (l Lit) Neg() bool { return l&1 == 1 }
+
+// Not returns the complementary literal.
+func (l Lit) Not() Lit { return l ^ 1 }
+
+// lbool 三值：未赋值 / 真 / 假
+type lbool int8
+
+const (
+	lUndef lbool = 0
+	lTrue  lbool = 1
+	lFalse lbool = -1
+)
+
+type clause struct {
+	lits   []Lit
+	learnt bool
+}
+
+const (
+	activityDecay   = 0.95
+	activityRescale = 1e100
+)
+
+// Solver is a conflict-driven clause-learning solver with two watched
+// literals, first-UIP learning, VSIDS-style branching and phase saving.
+// Clauses may be added between calls to Solve; a Solver is not safe for
+// concurrent use.
+type Solver struct {
+	clauses []*clause
+	learnts []*clause
+	// watches[l] 为监视文字 l 的子句，l 被赋为假时需要检查
+	watches [][]*clause
+
+	assigns  []lbool
+	level    []int
+	reason   []*clause
+	trail    []Lit
+	trailLim []int
+	qhead    int
+
+	activity []float64
+	varInc   float64
+	polarity []bool // 上次赋值的极性，回溯后优先沿用
+	seen     []bool
+
+	model []bool
+	unsat bool
+}
+
+func NewSolver() *Solver {
+	return &Solver{varInc: 1}
+}
+
+// NewVar allocates a fresh variable and returns its index.
+func (s *Solver) NewVar() int {
+	v := len(s.assigns)
+	s.assigns = append(s.assigns, lUndef)
+	s.level = append(s.level, 0)
+	s.reason = append(s.reason, nil)
+	s.activity = append(s.activity, 0)
+	s.polarity = append(s.polarity, false)
+	s.seen = append(s.seen, false)
+	s.watches = append(s.watches, nil, nil)
+	return v
+}
+
+// NumVars returns the number of allocated variables.
+func (s *Solver) NumVars() int { return len(s.assigns) }
+
+// AddClause adds the disjunction of lits. It returns false once the clause
+// set is known to be unsatisfiable at the top level.
+func (s *Solver) AddClause(lits ...Lit) bool {
+	if s.unsat {
+		return false
+	}
+	s.cancelUntil(0)
+
+	// 去重、删除顶层已为假的文字；恒真或已满足的子句直接丢弃
+	// Tseitin 子句只有两三个文字，逐个比较比建 map 快
+	kept := make([]Lit, 0, len(lits))
+	for i, l := range lits {
+		dup := false
+		for _, prev := range lits[:i] {
+			if prev == l.Not() {
+				return true
+			}
+			if prev == l {
+				dup = true
+			}
+		}
+		if dup {
+			continue
+		}
+		switch s.value(l) {
+		case lTrue:
+			return true
+		case lFalse:
+			continue
+		}
+		kept = append(kept, l)
+	}
+
+	switch len(kept) {
+	case 0:
+		s.unsat = true
+		return false
+	case 1:
+		s.enqueue(kept[0], nil)
+		if s.propagate() != nil {
+			s.unsat = true
+			return false
+		}
+		return true
+	}
+	c := &clause{lits: kept}
+	s.clauses = append(s.clauses, c)
+	s.attach(c)
+	return true
+}
+
+// Solve reports whether the clause set is satisfiable. When it is, Value
+// returns the model found.
+func (s *Solver) Solve() bool {
+	if s.unsat {
+		return false
+	}
+	s.cancelUntil(0)
+	for {
+		if confl := s.propagate(); confl != nil {
+			if s.decisionLevel() == 0 {
+				s.unsat = true
+				return false
+			}
+			learnt, backtrack := s.analyze(confl)
+			s.cancelUntil(backtrack)
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], nil)
+			} else {
+				c := &clause{lits: learnt, learnt: true}
+				s.learnts = append(s.learnts, c)
+				s.attach(c)
+				s.enqueue(learnt[0], c)
+			}
+			s.varInc /= activityDecay
+			continue
+		}
+
+		v := s.pickBranchVar()
+		if v < 0 {
+			s.model = make([]bool, len(s.assigns))
+			for i, a := range s.assigns {
+				s.model[i] = a == lTrue
+			}
+			s.cancelUntil(0)
+			return true
+		}
+		s.trailLim = append(s.trailLim, len(s.trail))
+		if s.polarity[v] {
+			s.enqueue(PosLit(v), nil)
+		} else {
+			s.enqueue(NegLit(v), nil)
+		}
+	}
+}
+
+// Value returns the value of v in the model of the last successful Solve.
+func (s *Solver) Value(v int) bool {
+	if v < 0 || v >= len(s.model) {
+		return false
+	}
+	return s.model[v]
+}
+
+func (s *Solver) value(l Lit) lbool {
+	a := s.assigns[l.Var()]
+	if l.Neg() {
+		return -a
+	}
+	return a
+}
+
+func (s *Solver) decisionLevel() int { return len(s.trailLim) }
+
+func (s *Solver) attach(c *clause) {
+	s.watches[c.lits[0]] = append(s.watches[c.lits[0]], c)
+	s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+}
+
+func (s *Solver) enqueue(l Lit, from *clause) {
+	v := l.Var()
+	if l.Neg() {
+		s.assigns[v] = lFalse
+	} else {
+		s.assigns[v] = lTrue
+	}
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = from
+	s.trail = append(s.trail, l)
+}
+
+// propagate 单元传播，返回冲突子句；没有冲突时返回 nil
+func (s *Solver) propagate() *clause {
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead].Not()
+		s.qhead++
+
+		ws := s.watches[falseLit]
+		j := 0
+		for i := 0; i < len(ws); i++ {
+			c := ws[i]
+			// 保证 lits[1] 是刚变为假的文字
+			if c.lits[0] == falseLit {
+				c.lits[0], c.lits[1] = c.lits[1], c.lits[0]
+			}
+			if s.value(c.lits[0]) == lTrue {
+				ws[j] = c
+				j++
+				continue
+			}
+
+			// 寻找新的监视文字
+			moved := false
+			for k := 2; k < len(c.lits); k++ {
+				if s.value(c.lits[k]) != lFalse {
+					c.lits[1], c.lits[k] = c.lits[k], c.lits[1]
+					s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			ws[j] = c
+			j++
+			if s.value(c.lits[0]) == lFalse {
+				// 冲突：保留尚未检查的监视子句
+				j += copy(ws[j:], ws[i+1:])
+				s.watches[falseLit] = ws[:j]
+				s.qhead = len(s.trail)
+				return c
+			}
+			s.enqueue(c.lits[0], c)
+		}
+		s.watches[falseLit] = ws[:j]
+	}
+	return nil
+}
+
+// analyze 按第一唯一蕴含点学习冲突子句，返回学到的子句（lits[0] 为断言文字）和回溯层
+func (s *Solver) analyze(confl *clause) ([]Lit, int) {
+	learnt := []Lit{0}
+	pathCount := 0
+	p := Lit(-1)
+	idx := len(s.trail) - 1
+
+	c := confl
+	for {
+		start := 0
+		if p != -1 {
+			// 原因子句的 lits[0] 就是被蕴含的文字 p 本身
+			start = 1
+		}
+		for _, q := range c.lits[start:] {
+			v := q.Var()
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.bump(v)
+			s.seen[v] = true
+			if s.level[v] >= s.decisionLevel() {
+				pathCount++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+
+		for !s.seen[s.trail[idx].Var()] {
+			idx--
+		}
+		p = s.trail[idx]
+		idx--
+		c = s.reason[p.Var()]
+		s.seen[p.Var()] = false
+		pathCount--
+		if pathCount == 0 {
+			break
+		}
+	}
+	learnt[0] = p.Not()
+
+	backtrack := 0
+	if len(learnt) > 1 {
+		// 把层数最高的文字放到 lits[1]，回溯后它成为第二个监视文字
+		maxIdx := 1
+		for i := 2; i < len(learnt); i++ {
+			if s.level[learnt[i].Var()] > s.level[learnt[maxIdx].Var()] {
+				maxIdx = i
+			}
+		}
+		learnt[1], learnt[maxIdx] = learnt[maxIdx], learnt[1]
+		backtrack = s.level[learnt[1].Var()]
+	}
+	for _, l := range learnt {
+		s.seen[l.Var()] = false
+	}
+	return learnt, backtrack
+}
+
+func (s *Solver) cancelUntil(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		v := s.trail[i].Var()
+		s.polarity[v] = !s.trail[i].Neg()
+		s.assigns[v] = lUndef
+		s.reason[v] = nil
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+// pickBranchVar 选择活跃度最高的未赋值变量；变量数很少，线性扫描即可
+func (s *Solver) pickBranchVar() int {
+	best := -1
+	for v, a := range s.assigns {
+		if a != lUndef {
+			continue
+		}
+		if best < 0 || s.activity[v] > s.activity[best] {
+			best = v
+		}
+	}
+	return best
+}
+
+func (s *Solver) bump(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > activityRescale {
+		for i := range s.activity {
+			s.activity[i] /= activityRescale
+		}
+		s.varInc /= activityRescale
+	}
+}
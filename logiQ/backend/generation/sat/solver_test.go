@@ -0,0 +1,175 @@
+package sat
+
+import (
+	"backend/generation/core"
+	"math/rand/v2"
+	"testing"
+)
+
+// bruteForce 枚举所有赋值判断 CNF 是否可满足
+func bruteForce(nvars int, clauses [][]Lit) bool {
+	for mask := 0; mask < 1<<nvars; mask++ {
+		ok := true
+		for _, c := range clauses {
+			sat := false
+			for _, l := range c {
+				if (mask>>l.Var()&1 == 1) != l.Neg() {
+					sat = true
+					break
+				}
+			}
+			if !sat {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func satisfies(s *Solver, clauses [][]Lit) bool {
+	for _, c := range clauses {
+		sat := false
+		for _, l := range c {
+			if s.Value(l.Var()) != l.Neg() {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSolverRandom3SAT(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 30))
+	for i := 0; i < 300; i++ {
+		nvars := 3 + rng.IntN(10)
+		// 子句数 / 变量数在 4.3 附近时可满足与不可满足各占一半左右
+		nclauses := int(float64(nvars)*(3.5+rng.Float64())) + 1
+		clauses := make([][]Lit, nclauses)
+		for c := range clauses {
+			for k := 0; k < 3; k++ {
+				v := rng.IntN(nvars)
+				if rng.IntN(2) == 0 {
+					clauses[c] = append(clauses[c], PosLit(v))
+				} else {
+					clauses[c] = append(clauses[c], NegLit(v))
+				}
+			}
+		}
+
+		s := NewSolver()
+		for v := 0; v < nvars; v++ {
+			s.NewVar()
+		}
+		for _, c := range clauses {
+			s.AddClause(c...)
+		}
+		got := s.Solve()
+		if want := bruteForce(nvars, clauses); got != want {
+			t.Fatalf("instance %d: Solve() = %v, want %v", i, got, want)
+		}
+		if got && !satisfies(s, clauses) {
+			t.Fatalf("instance %d: model does not satisfy the clauses", i)
+		}
+	}
+}
+
+func TestSolverPigeonhole(t *testing.T) {
+	// n+1 只鸽子放进 n 个笼子，不可满足
+	const holes = 4
+	s := NewSolver()
+	x := make([][]int, holes+1)
+	for p := range x {
+		x[p] = make([]int, holes)
+		for h := range x[p] {
+			x[p][h] = s.NewVar()
+		}
+	}
+	for p := range x {
+		lits := make([]Lit, holes)
+		for h := range lits {
+			lits[h] = PosLit(x[p][h])
+		}
+		s.AddClause(lits...)
+	}
+	for h := 0; h < holes; h++ {
+		for p := 0; p < len(x); p++ {
+			for q := p + 1; q < len(x); q++ {
+				s.AddClause(NegLit(x[p][h]), NegLit(x[q][h]))
+			}
+		}
+	}
+	if s.Solve() {
+		t.Fatal("pigeonhole instance reported satisfiable")
+	}
+}
+
+func TestSolverIncremental(t *testing.T) {
+	s := NewSolver()
+	a, b := s.NewVar(), s.NewVar()
+	s.AddClause(PosLit(a), PosLit(b))
+	if !s.Solve() {
+		t.Fatal("a ∨ b should be satisfiable")
+	}
+	s.AddClause(NegLit(a))
+	if !s.Solve() || !s.Value(b) {
+		t.Fatal("a ∨ b, ¬a should be satisfiable with b true")
+	}
+	s.AddClause(NegLit(b))
+	if s.Solve() {
+		t.Fatal("a ∨ b, ¬a, ¬b should be unsatisfiable")
+	}
+}
+
+func TestEncoderMatchesSemantics(t *testing.T) {
+	p := &core.Node{Kind: core.Var, Name: "p"}
+	q := &core.Node{Kind: core.Var, Name: "q"}
+	cases := []struct {
+		kind  core.NodeKind
+		truth func(a, b bool) bool
+	}{
+		{core.And, func(a, b bool) bool { return a && b }},
+		{core.Or, func(a, b bool) bool { return a || b }},
+		{core.Impl, func(a, b bool) bool { return !a || b }},
+		{core.Iff, func(a, b bool) bool { return a == b }},
+		{core.Xor, func(a, b bool) bool { return a != b }},
+		{core.Nand, func(a, b bool) bool { return !(a && b) }},
+		{core.Nor, func(a, b bool) bool { return !(a || b) }},
+		{core.True, func(a, b bool) bool { return true }},
+		{core.False, func(a, b bool) bool { return false }},
+	}
+	for _, tc := range cases {
+		for _, a := range []bool{false, true} {
+			for _, b := range []bool{false, true} {
+				s := NewSolver()
+				enc := NewEncoder(s)
+				node := &core.Node{Kind: tc.kind, Left: p, Right: q}
+				if tc.kind == core.True || tc.kind == core.False {
+					node = &core.Node{Kind: tc.kind}
+				}
+				root := enc.Encode(node)
+				fix := func(l Lit, value bool) {
+					if value {
+						s.AddClause(l)
+					} else {
+						s.AddClause(l.Not())
+					}
+				}
+				fix(enc.Var("p"), a)
+				fix(enc.Var("q"), b)
+				// 根文字取与语义相反的值时必须不可满足
+				fix(root, !tc.truth(a, b))
+				if s.Solve() {
+					t.Fatalf("kind %d with p=%v q=%v: encoding admits the wrong value", tc.kind, a, b)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package sat
+
+import "backend/generation/core"
+
+// Encoder translates formulas into equisatisfiable clauses on a Solver using
+// the Tseitin transformation: every connective gets a fresh variable that is
+// constrained to equal the connective applied to its operands.
+type Encoder struct {
+	solver *Solver
+	vars   map[string]int
+	names  []string
+	falseL Lit
+	hasF   bool
+}
+
+func NewEncoder(s *Solver) *Encoder {
+	return &Encoder{solver: s, vars: make(map[string]int)}
+}
+
+// Var returns the literal of the propositional variable name, allocating it
+// on first use.
+func (e *Encoder) Var(name string) Lit {
+	if v, ok := e.vars[name]; ok {
+		return PosLit(v)
+	}
+	v := e.solver.NewVar()
+	e.vars[name] = v
+	e.names = append(e.names, name)
+	return PosLit(v)
+}
+
+// Names returns the propositional variables seen so far, in first-use order.
+func (e *Encoder) Names() []string {
+	return append([]string(nil), e.names...)
+}
+
+// False returns a literal that is constrained to be false.
+func (e *Encoder) False() Lit {
+	if !e.hasF {
+		e.falseL = PosLit(e.solver.NewVar())
+		e.solver.AddClause(e.falseL.Not())
+		e.hasF = true
+	}
+	return e.falseL
+}
+
+// Encode adds the defining clauses of node and returns the literal that is
+// true exactly when node is. A nil node encodes as false.
+func (e *Encoder) Encode(node *core.Node) Lit {
+	if node == nil {
+		return e.False()
+	}
+	switch node.Kind {
+	case core.Var:
+		return e.Var(node.Name)
+	case core.True:
+		return e.False().Not()
+	case core.Not:
+		// 取反不需要新变量
+		return e.Encode(node.Left).Not()
+	case core.And, core.Or, core.Impl, core.Iff:
+		a := e.Encode(node.Left)
+		b := e.Encode(node.Right)
+		return e.binary(node.Kind, a, b)
+	case core.Xor, core.Nand, core.Nor:
+		// 取对应联结词（↔ / ∧ / ∨）的定义变量的反，不需要额外子句
+		a := e.Encode(node.Left)
+		b := e.Encode(node.Right)
+		return e.binary(negatedKind[node.Kind], a, b).Not()
+	default:
+		return e.False()
+	}
+}
+
+// negatedKind 给出 ⊕ / ↑ / ↓ 所否定的联结词
+var negatedKind = map[core.NodeKind]core.NodeKind{core.Xor: core.Iff, core.Nand: core.And, core.Nor: core.Or}
+
+func (e *Encoder) binary(kind core.NodeKind, a, b Lit) Lit {
+	s := e.solver
+	x := PosLit(s.NewVar())
+	switch kind {
+	case core.And:
+		// x ↔ a ∧ b
+		s.AddClause(x.Not(), a)
+		s.AddClause(x.Not(), b)
+		s.AddClause(x, a.Not(), b.Not())
+	case core.Or:
+		// x ↔ a ∨ b
+		s.AddClause(x, a.Not())
+		s.AddClause(x, b.Not())
+		s.AddClause(x.Not(), a, b)
+	case core.Impl:
+		// x ↔ ¬a ∨ b
+		s.AddClause(x, a)
+		s.AddClause(x, b.Not())
+		s.AddClause(x.Not(), a.Not(), b)
+	case core.Iff:
+		// x ↔ (a ↔ b)
+		s.AddClause(x.Not(), a.Not(), b)
+		s.AddClause(x.Not(), a, b.Not())
+		s.AddClause(x, a, b)
+		s.AddClause(x, a.Not(), b.Not())
+	}
+	return x
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"backend/generation/config"
+	"backend/generation/core"
+	"backend/generation/sampler"
+	"backend/models"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// 同一个种子下的 PCG 随机流
+const (
+	samplingStream   uint64 = 1
+	generationStream uint64 = 2
+	// letterStream 推理类题目随机改名变量用的随机流，独立于生成流，旧 blueprint 的生成过程不受影响
+	letterStream uint64 = 3
+)
+
+// GeneratorVersion 当前生成代码的版本，记录在 blueprint 中
+// 1：推理、证明、消解、表格法题目的变量字母随机化
+const GeneratorVersion = 1
+
+// withLetters 按生成代码的版本设置推理模板的改名随机流
+func withLetters(profile sampler.Profile, seed int64, version int) sampler.Profile {
+	if version >= 1 {
+		profile.InfProfile.Letters = rand.New(rand.NewPCG(uint64(seed), letterStream))
+	}
+	return profile
+}
+
+// newBlueprint 把采样结果转换为可持久化的 blueprint
+func newBlueprint(seed int64, version string, generatorVersion int, plan sampler.Plan, profile sampler.Profile, templateIndex int) core.Blueprint {
+	ops := make([]string, 0, len(profile.AllowedOps))
+	for _, op := range profile.AllowedOps {
+		ops = append(ops, config.OpName(op))
+	}
+	return core.Blueprint{
+		Seed:           seed,
+		ConfigVersion:  version,
+		Difficulty:     plan.Difficulty,
+		Category:       plan.Category,
+		Type:           plan.QType,
+		Intent:         plan.Intent,
+		MCCorrectCount: plan.MCCorrectCount,
+		Profile: models.BlueprintProfile{
+			Vars:                 profile.Vars,
+			MaxDepth:             profile.MaxDepth,
+			MinVars:              profile.MinVars,
+			AllowedOps:           ops,
+			EqChainSteps:         profile.EqProfile.ChainSteps,
+			EqMaxDistractorSteps: profile.EqProfile.MaxDistractorSteps,
+			EqMaxExprLength:      profile.EqProfile.MaxExprLength,
+			InfChainSteps:        profile.InfProfile.ChainSteps,
+		},
+		TemplateIndex:    templateIndex,
+		GeneratorVersion: generatorVersion,
+	}
+}
+
+// fromBlueprint 从 blueprint 还原 plan 和 profile
+func fromBlueprint(bp core.Blueprint) (sampler.Plan, sampler.Profile, error) {
+	ops := make([]core.NodeKind, 0, len(bp.Profile.AllowedOps))
+	for _, name := range bp.Profile.AllowedOps {
+		op, ok := config.OpNameToKind[strings.ToUpper(name)]
+		if !ok {
+			return sampler.Plan{}, sampler.Profile{}, fmt.Errorf("service: unsupported operator %s in blueprint", name)
+		}
+		ops = append(ops, op)
+	}
+	plan := sampler.Plan{
+		Difficulty:     bp.Difficulty,
+		Category:       bp.Category,
+		QType:          bp.Type,
+		Intent:         bp.Intent,
+		MCCorrectCount: bp.MCCorrectCount,
+	}
+	profile := sampler.Profile{
+		Vars:       bp.Profile.Vars,
+		MaxDepth:   bp.Profile.MaxDepth,
+		MinVars:    bp.Profile.MinVars,
+		AllowedOps: ops,
+		EqProfile: sampler.EqProfile{
+			ChainSteps:         bp.Profile.EqChainSteps,
+			MaxDistractorSteps: bp.Profile.EqMaxDistractorSteps,
+			MaxExprLength:      bp.Profile.EqMaxExprLength,
+		},
+		InfProfile: sampler.InfProfile{ChainSteps: bp.Profile.InfChainSteps},
+	}
+	return plan, profile, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"backend/generation/core"
+	"backend/models"
+	"errors"
+	"math/rand/v2"
+)
+
+// ErrNotProofExercise 只有推理题可以用作证明练习
+var ErrNotProofExercise = errors.New("service: only inference questions can be used as proof exercises")
+
+// ProofExercise 推理题对应的证明练习：模板实例化后、等价变换前的前提和模板的最终结论
+// 结论能用基本推理规则从前提逐行推出
+type ProofExercise struct {
+	TemplateName string
+	Premises     []*core.Node
+	// Target 只取模板的最终结论；中间结论同样有效，一并给出就等于给出了推理题的正确选项
+	Target *core.Node
+	// ConfigChanged blueprint 生成时的配置与当前配置不同，重放得到的实例可能与原题不一致
+	ConfigChanged bool
+}
+
+// ProofExercise 按 blueprint 重放推理题的生成过程，取出未变换的模板实例
+// 只重放生成器这一步，随机流与 build 相同，因此得到的正是原题所用的实例
+func (s Service) ProofExercise(bp models.QuestionBlueprint) (ProofExercise, error) {
+	if bp.Category != models.QuestionCategoryInference {
+		return ProofExercise{}, ErrNotProofExercise
+	}
+	plan, profile, err := fromBlueprint(bp)
+	if err != nil {
+		return ProofExercise{}, err
+	}
+	rng := rand.New(rand.NewPCG(uint64(bp.Seed), generationStream))
+	pools, _, err := s.generators[plan.Category].Generate(rng, withLetters(profile, bp.Seed, bp.GeneratorVersion), plan)
+	if err != nil {
+		return ProofExercise{}, err
+	}
+	inf := pools.Inference
+	return ProofExercise{
+		TemplateName:  inf.TemplateName,
+		Premises:      inf.TemplatePremises,
+		Target:        inf.TemplateValid[len(inf.TemplateValid)-1],
+		ConfigChanged: bp.ConfigVersion != s.cfg.Version,
+	}, nil
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"backend/generation/core"
+	"backend/generation/parser"
+	"backend/generation/proof"
+	"backend/generation/sampler"
+	"backend/models"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// optionFormulaCategories 选项本身是公式的题目类别；真值表、消解、表格法的选项是赋值、子句集和分支，
+// 由 optionSetParsers 解析
+var optionFormulaCategories = map[models.QuestionCategory]bool{
+	models.QuestionCategoryEquivalence:    true,
+	models.QuestionCategoryInference:      true,
+	models.QuestionCategoryNormalForm:     true,
+	models.QuestionCategoryClassification: true,
+	models.QuestionCategoryProof:          true,
+}
+
+// optionSetParsers 选项是集合的题目意图及其解析方式
+var optionSetParsers = map[string]func(string) []*core.Node{
+	"TT_TRUE_ASSIGNMENTS":  parseAssignment,
+	"TT_FALSE_ASSIGNMENTS": parseAssignment,
+	"INF_COUNTEREXAMPLE":   parseAssignment,
+	"TAB_OPEN_BRANCH":      parseBranch,
+	"RES_UNSAT":            parseClauseSet,
+	"RES_RESOLVENT":        parseClauseSet,
+}
+
+// buildFormulas 从候选池和题干数据中取出题目涉及的公式
+// 题干数据是规范 Unicode 文本，在改写记法之前调用
+func buildFormulas(plan sampler.Plan, pools core.CandidatePools, data map[string]string, options []string) *models.QuestionFormulas {
+	var (
+		target     *core.Node
+		premises   []*core.Node
+		candidate  *core.Node
+		clauses    []*core.Node
+		clauseSet  []*core.Node
+		assignment []*core.Node
+		lines      []models.ProofLine
+		extra      []*core.Node // 证明中的公式，只用于收集变量
+	)
+	switch plan.Category {
+	case models.QuestionCategoryTruthTable:
+		target = pools.TruthTable.Formula
+		assignment = parseAssignment(data["alpha"])
+	case models.QuestionCategoryEquivalence:
+		target = pools.Equivalence.Target
+		candidate = parseFormula(data["G"])
+	case models.QuestionCategoryNormalForm:
+		target = pools.NormalForm.Formula
+		candidate = parseFormula(data["G"])
+	case models.QuestionCategoryClassification:
+		target = parseFormula(data["F"])
+	case models.QuestionCategoryInference, models.QuestionCategoryProof,
+		models.QuestionCategoryResolution, models.QuestionCategoryTableau:
+		premises = parseFormulas(data["Premises"])
+		target = parseFormula(data["Conclusion"])
+	}
+	switch plan.Category {
+	case models.QuestionCategoryProof:
+		lines, extra = parseProof(data["Proof"], data["Step"])
+	case models.QuestionCategoryResolution:
+		clauses = []*core.Node{parseClause(data["Left"]), parseClause(data["Right"])}
+		candidate = parseClause(data["C"])
+		clauseSet = parseClauseSet(data["Clauses"])
+	}
+
+	formulas := &models.QuestionFormulas{
+		Target:     core.EncodeFormula(target),
+		Candidate:  core.EncodeFormula(candidate),
+		Premises:   encodeAll(premises),
+		ClauseSet:  encodeAll(clauseSet),
+		Assignment: encodeAll(assignment),
+		// 判断题题干中声称的类别或范式，两者不会同时出现
+		Label: data["Class"] + data["Form"],
+	}
+	nodes := append([]*core.Node{target, candidate}, premises...)
+	nodes = append(nodes, clauseSet...)
+	nodes = append(nodes, assignment...)
+	if lines != nil {
+		formulas.Proof = lines
+		formulas.Line, _ = strconv.Atoi(data["Line"])
+		nodes = append(nodes, extra...)
+	}
+	if clauses != nil && clauses[0] != nil && clauses[1] != nil {
+		formulas.Clauses = []*models.FormulaNode{core.EncodeFormula(clauses[0]), core.EncodeFormula(clauses[1])}
+		nodes = append(nodes, clauses...)
+	}
+
+	if optionFormulaCategories[plan.Category] && plan.QType != models.QuestionTypeTrueFalse {
+		encoded := make([]*models.FormulaNode, len(options))
+		found := false
+		for i, option := range options {
+			if node := parseFormula(option); node != nil {
+				encoded[i] = core.EncodeFormula(node)
+				nodes = append(nodes, node)
+				found = true
+			}
+		}
+		if found {
+			formulas.Options = encoded
+		}
+	}
+	if parse, ok := optionSetParsers[plan.Intent]; ok && plan.QType != models.QuestionTypeTrueFalse {
+		sets := make([][]*models.FormulaNode, len(options))
+		for i, option := range options {
+			set := parse(option)
+			if set == nil {
+				continue
+			}
+			sets[i] = make([]*models.FormulaNode, 0, len(set))
+			for _, n := range set {
+				sets[i] = append(sets[i], core.EncodeFormula(n))
+			}
+			nodes = append(nodes, set...)
+		}
+		formulas.OptionSets = sets
+	}
+
+	formulas.Vars = collectVars(nodes)
+	return formulas
+}
+
+// parseFormula 解析一个公式；变量都是单个字母，因此像 "Simplification" 这样的规则名不算公式
+func parseFormula(s string) *core.Node {
+	if s == "" {
+		return nil
+	}
+	node, err := parser.Parse(s)
+	if err != nil || !singleLetterVars(node) {
+		return nil
+	}
+	return node
+}
+
+func parseFormulas(s string) []*core.Node {
+	if s == "" {
+		return nil
+	}
+	nodes, err := parser.ParseList(s)
+	if err != nil {
+		return nil
+	}
+	return nodes
+}
+
+// parseProof 解析题干中的证明 "1. p → q (Premise)"，每行一条；"?" 表示被隐去的公式或规则
+// step 为判断题待判断的下一行，非空时接在最后。任一行无法解析时返回 nil
+func parseProof(listing, step string) ([]models.ProofLine, []*core.Node) {
+	if listing == "" {
+		return nil, nil
+	}
+	texts := strings.Split(listing, "\n")
+	if step != "" {
+		texts = append(texts, step)
+	}
+	lines := make([]models.ProofLine, len(texts))
+	var nodes []*core.Node
+	for i, text := range texts {
+		_, rest, ok := strings.Cut(text, ". ")
+		open := strings.LastIndex(rest, " (")
+		if !ok || open < 0 || !strings.HasSuffix(rest, ")") {
+			return nil, nil
+		}
+		formula, justification := rest[:open], rest[open+2:len(rest)-1]
+		if formula != "?" {
+			node := parseFormula(formula)
+			if node == nil {
+				return nil, nil
+			}
+			lines[i].Formula = core.EncodeFormula(node)
+			nodes = append(nodes, node)
+		}
+		// 规则名中没有逗号，形如 "Modus Ponens, 1, 2"
+		name, cites, _ := strings.Cut(justification, ", ")
+		if name != "?" {
+			rule, err := proof.ParseRule(name)
+			if err != nil {
+				return nil, nil
+			}
+			lines[i].Rule = string(rule)
+		}
+		if cites != "" {
+			for _, c := range strings.Split(cites, ", ") {
+				n, err := strconv.Atoi(c)
+				if err != nil {
+					return nil, nil
+				}
+				lines[i].Cites = append(lines[i].Cites, n)
+			}
+		}
+	}
+	return lines, nodes
+}
+
+// parseClause 把 "{p, ¬q}" 读成文字的析取，空子句 "□" 为 ⊥
+func parseClause(s string) *core.Node {
+	if s == "□" {
+		return &core.Node{Kind: core.False}
+	}
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil
+	}
+	literals := parseFormulas(s[1 : len(s)-1])
+	if len(literals) == 0 {
+		return nil
+	}
+	clause := literals[0]
+	for _, l := range literals[1:] {
+		clause = &core.Node{Kind: core.Or, Left: clause, Right: l}
+	}
+	return clause
+}
+
+// parseAssignment 把 "p=T, q=F" 读成文字 p、¬q；格式不符时返回 nil
+func parseAssignment(s string) []*core.Node {
+	if s == "" {
+		return nil
+	}
+	var literals []*core.Node
+	for _, part := range strings.Split(s, ", ") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || utf8.RuneCountInString(name) != 1 || (value != "T" && value != "F") {
+			return nil
+		}
+		literal := &core.Node{Kind: core.Var, Name: name}
+		if value == "F" {
+			literal = &core.Node{Kind: core.Not, Left: literal}
+		}
+		literals = append(literals, literal)
+	}
+	return literals
+}
+
+// parseBranch 读出分支上的文字 "p, ¬q"，没有文字的分支写作 "∅"
+func parseBranch(s string) []*core.Node {
+	if s == "∅" {
+		return []*core.Node{}
+	}
+	return parseFormulas(s)
+}
+
+// parseClauseSet 把 "{p, ¬q}, {q}" 读成子句的列表，子句的写法同 parseClause；单个子句也按子句集读取
+func parseClauseSet(s string) []*core.Node {
+	var clauses []*core.Node
+	for s != "" {
+		end := len("□")
+		if strings.HasPrefix(s, "{") {
+			end = strings.Index(s, "}") + 1
+		}
+		if end == 0 || end > len(s) {
+			return nil
+		}
+		clause := parseClause(s[:end])
+		if clause == nil {
+			return nil
+		}
+		clauses = append(clauses, clause)
+		s = s[end:]
+		if s != "" && !strings.HasPrefix(s, ", ") {
+			return nil
+		}
+		s = strings.TrimPrefix(s, ", ")
+	}
+	return clauses
+}
+
+func encodeAll(nodes []*core.Node) []*models.FormulaNode {
+	var out []*models.FormulaNode
+	for _, n := range nodes {
+		out = append(out, core.EncodeFormula(n))
+	}
+	return out
+}
+
+func singleLetterVars(node *core.Node) bool {
+	if node == nil {
+		return true
+	}
+	if node.Kind == core.Var {
+		return utf8.RuneCountInString(node.Name) == 1
+	}
+	return singleLetterVars(node.Left) && singleLetterVars(node.Right)
+}
+
+func collectVars(nodes []*core.Node) []string {
+	seen := make(map[string]bool)
+	var walk func(*core.Node)
+	walk = func(n *core.Node) {
+		if n == nil {
+			return
+		}
+		if n.Kind == core.Var {
+			seen[n.Name] = true
+		}
+		walk(n.Left)
+		walk(n.Right)
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	vars := make([]string, 0, len(seen))
+	for v := range seen {
+		vars = append(vars, v)
+	}
+	sort.Strings(vars)
+	return vars
+}
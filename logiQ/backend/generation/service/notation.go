@@ -0,0 +1,46 @@
+package service
+
+import (
+	"backend/generation/render"
+	"backend/models"
+)
+
+// applyNotation 把题目中的 Unicode 公式改写为指定记法，并记录在题目和 blueprint 上
+// 生成过程始终使用规范的 Unicode 形式，记法只影响最终文本，因此同一种子在不同记法下是同一道题
+func applyNotation(question models.Question, r render.Renderer) models.Question {
+	if r.IsCanonical() {
+		return question
+	}
+	question.QuestionText = r.Text(question.QuestionText)
+	question.Options = renderAll(question.Options, r)
+	question.Explanation = r.Text(question.Explanation)
+	question.OptionExplanations = renderAll(question.OptionExplanations, r)
+	question.Notation = string(r.Notation)
+	if question.Blueprint != nil {
+		bp := *question.Blueprint
+		bp.Notation = string(r.Notation)
+		bp.MinimalParens = r.MinimalParens
+		question.Blueprint = &bp
+	}
+	return question
+}
+
+func renderAll(texts []string, r render.Renderer) []string {
+	if texts == nil {
+		return nil
+	}
+	out := make([]string, len(texts))
+	for i, t := range texts {
+		out[i] = r.Text(t)
+	}
+	return out
+}
+
+// blueprintRenderer 还原 blueprint 中记录的记法，旧题目没有记录时为 Unicode
+func blueprintRenderer(bp models.QuestionBlueprint) (render.Renderer, error) {
+	notation, err := render.ParseNotation(bp.Notation)
+	if err != nil {
+		return render.Renderer{}, err
+	}
+	return render.Renderer{Notation: notation, MinimalParens: bp.MinimalParens}, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"backend/generation/render"
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// maxAttemptsPerQuestion 每道题最多尝试的次数，达到则视为用户的需求无法满足，放弃生成并返回，避免卡死
+const maxAttemptsPerQuestion = 5
+
+// maxDuplicatesPerQuestion 每道题最多跳过的重复题目数，不占用 maxAttemptsPerQuestion；
+// 达到说明题库中这类题目已接近穷尽
+const maxDuplicatesPerQuestion = 20
+
+// ErrDuplicate 生成的题目与题库中已有题目重复
+var ErrDuplicate = errors.New("service: question duplicates one already in the bank")
+
+// BatchRequest 批量生成的参数
+type BatchRequest struct {
+	Num        int
+	Category   models.QuestionCategory
+	Difficulty models.QuestionDifficulty
+	QType      models.QuestionType
+	// Renderer 题目中公式的记法，零值为规范的 Unicode 形式
+	Renderer render.Renderer
+	// MasterSeed 决定整批题目，相同的主种子总是得到相同的结果，与并发数无关；0 表示使用当前时间
+	MasterSeed uint64
+	// Workers 并发生成的 goroutine 数，<= 0 时使用 GOMAXPROCS
+	Workers int
+	// OnProgress 可选的进度回调
+	OnProgress ProgressFunc
+	// Exclude 可选，返回 true 的指纹（通常是题库中已有的题目）会被跳过并换下一个种子重新生成
+	// 它只检查已有的题目，同一批内的重复由入库时的唯一索引拒绝
+	Exclude func(fingerprint string) bool
+}
+
+// Progress 生成过程中的进度事件
+type Progress struct {
+	Slot      int   // 题目在结果中的下标
+	Generated int   // 已成功生成的题目数
+	Total     int   // 需要生成的题目数
+	Attempt   int   // 当前题目的尝试次数，从 1 开始
+	Err       error // 非空表示本次尝试失败；重复题目为 ErrDuplicate
+}
+
+// ProgressFunc 接收进度事件，调用是串行的，但可能来自不同的 goroutine
+type ProgressFunc func(Progress)
+
+// GenerateBatch 用有界的 goroutine 池并发生成题目
+// 第 i 道题的每次尝试都使用由 (MasterSeed, i, attempt) 派生的种子，结果按下标返回，
+// 因此输出只取决于主种子。任意一道题用尽尝试次数或 ctx 被取消时，整批放弃并返回错误
+func (s Service) GenerateBatch(ctx context.Context, req BatchRequest) ([]models.Question, error) {
+	if req.Num <= 0 {
+		return nil, nil
+	}
+	master := req.MasterSeed
+	if master == 0 {
+		master = uint64(time.Now().UnixNano())
+	}
+	workers := req.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, req.Num)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results   = make([]models.Question, req.Num)
+		mu        sync.Mutex // 保护 generated、firstErr 以及进度回调
+		generated int
+		firstErr  error
+	)
+	report := func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if p.Err == nil {
+			generated++
+		}
+		p.Generated = generated
+		p.Total = req.Num
+		if req.OnProgress != nil {
+			req.OnProgress(p)
+		}
+	}
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	slots := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for slot := range slots {
+				question, err := s.generateSlot(ctx, req, master, slot, report)
+				if err != nil {
+					fail(err)
+					return
+				}
+				results[slot] = question
+			}
+		}()
+	}
+
+	// 分发题目下标，ctx 取消后停止分发
+dispatch:
+	for slot := 0; slot < req.Num; slot++ {
+		select {
+		case slots <- slot:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(slots)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("service: generation cancelled: %w", err)
+	}
+	return results, nil
+}
+
+// generateSlot 生成第 slot 道题，失败或与已有题目重复时用下一个派生种子重试
+func (s Service) generateSlot(ctx context.Context, req BatchRequest, master uint64, slot int, report ProgressFunc) (models.Question, error) {
+	var (
+		lastErr            error
+		failed, duplicates int
+	)
+	for attempt := 0; failed < maxAttemptsPerQuestion; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return models.Question{}, fmt.Errorf("service: generation cancelled: %w", err)
+		}
+		seed := deriveSeed(master, slot, attempt)
+		question, err := s.GenerateFromSeed(seed, req.Category, req.Difficulty, req.QType)
+		if err == nil && req.Exclude != nil && req.Exclude(question.Fingerprint) {
+			duplicates++
+			report(Progress{Slot: slot, Attempt: attempt + 1, Err: ErrDuplicate})
+			if duplicates >= maxDuplicatesPerQuestion {
+				return models.Question{}, fmt.Errorf("service: skipped %d duplicate questions: %w", duplicates, ErrDuplicate)
+			}
+			continue
+		}
+		if err != nil {
+			lastErr = err
+			failed++
+			report(Progress{Slot: slot, Attempt: attempt + 1, Err: err})
+			continue
+		}
+		report(Progress{Slot: slot, Attempt: attempt + 1})
+		return applyNotation(question, req.Renderer), nil
+	}
+	return models.Question{}, fmt.Errorf("service: failed to generate question after %d attempts: %w", maxAttemptsPerQuestion, lastErr)
+}
+
+// deriveSeed 用 splitmix64 从主种子派生出某道题某次尝试的种子，结果为非负 int64 以便存入 blueprint
+func deriveSeed(master uint64, slot, attempt int) int64 {
+	x := splitmix64(master ^ splitmix64(uint64(slot)))
+	x = splitmix64(x + uint64(attempt))
+	return int64(x >> 1)
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
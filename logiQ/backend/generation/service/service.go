@@ -3,28 +3,36 @@ package service
 import (
 	"backend/generation/assembler"
 	"backend/generation/builder/choice"
+	"backend/generation/builder/explain"
 	"backend/generation/builder/prepare"
 	"backend/generation/builder/prompt"
 	"backend/generation/config"
+	"backend/generation/fingerprint"
 	"backend/generation/generator"
+	"backend/generation/generator/cls"
 	"backend/generation/generator/eq"
 	"backend/generation/generator/inf"
+	"backend/generation/generator/nf"
+	"backend/generation/generator/prf"
+	"backend/generation/generator/res"
+	"backend/generation/generator/tab"
 	"backend/generation/generator/tt"
 	"backend/generation/sampler"
 	"backend/generation/validator"
 	"backend/models"
+	"context"
 	"fmt"
 	"math/rand/v2"
-	"time"
 )
 
 type Service struct {
-	cfg           config.AppConfig
-	sampler       sampler.Sampler
-	generators    map[models.QuestionCategory]generator.Generator
-	promptBuilder prompt.Builder
-	choiceBuilder choice.Builder
-	assembler     assembler.Assembler
+	cfg            config.AppConfig
+	sampler        sampler.Sampler
+	generators     map[models.QuestionCategory]generator.Generator
+	promptBuilder  prompt.Builder
+	choiceBuilder  choice.Builder
+	explainBuilder explain.Builder
+	assembler      assembler.Assembler
 }
 
 func NewService() Service {
@@ -35,106 +43,148 @@ func NewService() Service {
 	}
 	//
 	generators := make(map[models.QuestionCategory]generator.Generator)
-	defaultValidator := validator.NewDefaultValidator()
-	generators[models.QuestionCategoryTruthTable] = tt.NewTruthTableGenerator(defaultValidator)
-	generators[models.QuestionCategoryEquivalence] = eq.NewEquivalenceGenerator(defaultValidator, cfg.Equivalence)
-	generators[models.QuestionCategoryInference] = inf.NewInferenceGenerator(defaultValidator, cfg.Inference)
+	v, err := validator.New(cfg.Validator)
+	if err != nil {
+		panic(err)
+	}
+	generators[models.QuestionCategoryTruthTable] = tt.NewTruthTableGenerator(v)
+	generators[models.QuestionCategoryEquivalence] = eq.NewEquivalenceGenerator(v, cfg.Equivalence)
+	generators[models.QuestionCategoryInference] = inf.NewInferenceGenerator(v, cfg.Inference)
+	generators[models.QuestionCategoryNormalForm] = nf.NewNormalFormGenerator(v, cfg.NormalForm)
+	generators[models.QuestionCategoryClassification] = cls.NewClassificationGenerator(v)
+	generators[models.QuestionCategoryProof] = prf.NewProofGenerator(v, cfg.Inference)
+	generators[models.QuestionCategoryResolution] = res.NewResolutionGenerator(v, cfg.Inference)
+	generators[models.QuestionCategoryTableau] = tab.NewTableauGenerator(v, cfg.Inference)
 	return Service{
-		cfg:           cfg,
-		sampler:       sampler.NewSampler(cfg),
-		generators:    generators,
-		promptBuilder: prompt.NewBuilder(),
-		choiceBuilder: choice.NewBuilder(),
-		assembler:     assembler.NewAssembler(),
+		cfg:            cfg,
+		sampler:        sampler.NewSampler(cfg),
+		generators:     generators,
+		promptBuilder:  prompt.NewBuilder(),
+		choiceBuilder:  choice.NewBuilder(),
+		explainBuilder: explain.NewBuilder(),
+		assembler:      assembler.NewAssembler(),
 	}
 }
 
-func (s Service) GenerateQuestion(num int, category models.QuestionCategory, difficulty models.QuestionDifficulty, qType models.QuestionType) ([]models.Question, error) {
-
-	if num <= 0 {
-		return nil, nil
-	}
+// ConfigVersion 返回当前加载配置的版本号
+func (s Service) ConfigVersion() string {
+	return s.cfg.Version
+}
 
-	rng := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 0))
-	questionList := make([]models.Question, 0, num)
-	const maxAttemptsPerQuestion = 5
+// GenerateQuestion 生成 num 道题目，使用基于当前时间的主种子和默认并发数
+func (s Service) GenerateQuestion(ctx context.Context, num int, category models.QuestionCategory, difficulty models.QuestionDifficulty, qType models.QuestionType) ([]models.Question, error) {
+	return s.GenerateBatch(ctx, BatchRequest{
+		Num:        num,
+		Category:   category,
+		Difficulty: difficulty,
+		QType:      qType,
+	})
+}
 
-	// 循环生成题目，直到达到所需数量
-	for len(questionList) < num {
-		var lastErr error
-		succeeded := false
-		// 每次生成题目，最多尝试maxAttemptsPerQuestion次，达到则视为用户的需求无法满足，放弃生成并返回，避免卡死
-		for attempt := 0; attempt < maxAttemptsPerQuestion; attempt++ {
-			// 1. sample
-			sampleResult, err := s.sampler.Sample(rng, difficulty, qType, category)
-			if err != nil {
-				lastErr = err
-				continue
-			}
-			plan := sampleResult.Plan
-			profile := sampleResult.Profile
+// GenerateFromSeed 用给定种子生成一道题目
+// 采样使用 PCG(seed, 1)，内容生成使用 PCG(seed, 2)，两条随机流互不影响，
+// 因此只要 blueprint 中记录了采样结果，就可以跳过采样直接重放内容生成
+func (s Service) GenerateFromSeed(seed int64, category models.QuestionCategory, difficulty models.QuestionDifficulty, qType models.QuestionType) (models.Question, error) {
+	sampleRng := rand.New(rand.NewPCG(uint64(seed), samplingStream))
+	sampleResult, err := s.sampler.Sample(sampleRng, difficulty, qType, category)
+	if err != nil {
+		return models.Question{}, err
+	}
+	return s.build(seed, GeneratorVersion, sampleResult.Plan, sampleResult.Profile)
+}
 
-			// 2. generate
-			generatorSpec, ok := s.generators[plan.Category]
-			if !ok {
-				lastErr = fmt.Errorf("service: no generator registered for category %s", plan.Category)
-				continue
-			}
-			candidatePools, _, err := generatorSpec.Generate(rng, profile, plan)
-			if err != nil {
-				lastErr = err
-				continue
-			}
+// Regenerate 按 blueprint 重新生成题目
+// 配置未变化时结果与原题完全一致；配置变化后沿用原来的 plan 和 profile，按新配置生成。
+// 生成代码按 blueprint 记录的版本运行，旧版本的题目同样原样重现。
+// 题目按 blueprint 中记录的记法输出
+func (s Service) Regenerate(bp models.QuestionBlueprint) (models.Question, error) {
+	plan, profile, err := fromBlueprint(bp)
+	if err != nil {
+		return models.Question{}, err
+	}
+	renderer, err := blueprintRenderer(bp)
+	if err != nil {
+		return models.Question{}, err
+	}
+	question, err := s.build(bp.Seed, bp.GeneratorVersion, plan, profile)
+	if err != nil {
+		return models.Question{}, err
+	}
+	return applyNotation(question, renderer), nil
+}
 
-			// 3. build prompt
-			// 获取intentSpec
-			intentSpec, ok := s.cfg.Intents[plan.Intent]
-			if !ok {
-				lastErr = fmt.Errorf("service: intent %s not found", plan.Intent)
-				continue
-			}
-			buildCtx, err := prepare.PreparePromptData(plan, candidatePools, rng)
-			if err != nil {
-				lastErr = err
-				continue
-			}
-			var promptParam = prompt.Params{
-				Intent: intentSpec,
-				QType:  plan.QType,
-				Data:   buildCtx.PromptData,
-			}
-			promptRes, err := s.promptBuilder.BuildPrompt(promptParam, rng)
-			if err != nil {
-				lastErr = err
-				continue
-			}
+// build 按 plan 和 profile 生成题目内容，并附上 blueprint；generatorVersion 决定按哪个版本的生成代码运行
+func (s Service) build(seed int64, generatorVersion int, plan sampler.Plan, profile sampler.Profile) (models.Question, error) {
+	rng := rand.New(rand.NewPCG(uint64(seed), generationStream))
+	profile = withLetters(profile, seed, generatorVersion)
 
-			// 4. build choice
-			var choiceParam = choice.Params{
-				Plan:     plan,
-				Intent:   intentSpec,
-				Pools:    candidatePools,
-				TFAnswer: buildCtx.TFAnswer,
-			}
+	// 1. generate
+	generatorSpec, ok := s.generators[plan.Category]
+	if !ok {
+		return models.Question{}, fmt.Errorf("service: no generator registered for category %s", plan.Category)
+	}
+	candidatePools, _, err := generatorSpec.Generate(rng, profile, plan)
+	if err != nil {
+		return models.Question{}, err
+	}
 
-			choiceRes, err := s.choiceBuilder.BuildChoice(choiceParam, rng)
-			if err != nil {
-				lastErr = err
-				continue
-			}
+	// 2. build prompt
+	// 获取intentSpec
+	intentSpec, ok := s.cfg.Intents[plan.Intent]
+	if !ok {
+		return models.Question{}, fmt.Errorf("service: intent %s not found", plan.Intent)
+	}
+	buildCtx, err := prepare.PreparePromptData(plan, candidatePools, rng)
+	if err != nil {
+		return models.Question{}, err
+	}
+	var promptParam = prompt.Params{
+		Intent: intentSpec,
+		QType:  plan.QType,
+		Data:   buildCtx.PromptData,
+	}
+	promptRes, err := s.promptBuilder.BuildPrompt(promptParam, rng)
+	if err != nil {
+		return models.Question{}, err
+	}
 
-			// 5. assemble
-			question := s.assembler.Assemble(plan, promptRes, choiceRes)
-			questionList = append(questionList, question)
-			succeeded = true
-			break
-		}
+	// 3. build choice
+	var choiceParam = choice.Params{
+		Plan:     plan,
+		Intent:   intentSpec,
+		Pools:    candidatePools,
+		TFAnswer: buildCtx.TFAnswer,
+	}
+	choiceRes, err := s.choiceBuilder.BuildChoice(choiceParam, rng)
+	if err != nil {
+		return models.Question{}, err
+	}
 
-		if !succeeded {
-			return nil, fmt.Errorf("service: failed to generate question after %d attempts: %w", maxAttemptsPerQuestion, lastErr)
-		}
+	// 4. build explanation
+	explanation, err := s.explainBuilder.BuildExplanation(explain.Params{
+		Plan:     plan,
+		Pools:    candidatePools,
+		Data:     buildCtx.PromptData,
+		TFAnswer: buildCtx.TFAnswer,
+		Choice:   choiceRes,
+	})
+	if err != nil {
+		return models.Question{}, err
 	}
 
-	// 6. 返回
-	return questionList, nil
+	// 5. assemble
+	question := s.assembler.Assemble(plan, promptRes, choiceRes, explanation)
+	question.Formulas = buildFormulas(plan, candidatePools, buildCtx.PromptData, question.Options)
+	question.Fingerprint, err = fingerprint.Of(fingerprint.Question{
+		Intent:   plan.Intent,
+		Type:     plan.QType,
+		Formulas: question.Formulas,
+		Options:  question.Options,
+	})
+	if err != nil {
+		return models.Question{}, err
+	}
+	blueprint := newBlueprint(seed, s.cfg.Version, generatorVersion, plan, profile, promptRes.TemplateIndex)
+	question.Blueprint = &blueprint
+	return question, nil
 }
@@ -1,12 +1,26 @@
 package service
 
 import (
+	"backend/generation/audit"
+	"backend/generation/config"
+	"backend/generation/core"
+	"backend/generation/helper"
+	"backend/generation/proof"
+	"backend/generation/render"
+	"backend/generation/validator"
+	"backend/models"
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
 )
 
 func TestService(t *testing.T) {
 	service := NewService()
-	questions, err := service.GenerateQuestion(50, "", "", "")
+	questions, err := service.GenerateQuestion(context.Background(), 50, "", "", "")
 	if err != nil {
 		t.Fatal(" Error generating question: ", err)
 	}
@@ -26,3 +40,405 @@ func TestService(t *testing.T) {
 	}
 
 }
+
+func TestRegenerateFromBlueprint(t *testing.T) {
+	service := NewService()
+	questions, err := service.GenerateQuestion(context.Background(), 60, "", "", "")
+	if err != nil {
+		t.Fatal(" Error generating question: ", err)
+	}
+	for _, question := range questions {
+		if question.Blueprint == nil {
+			t.Fatal("generated question has no blueprint")
+		}
+		if question.Blueprint.ConfigVersion != service.ConfigVersion() {
+			t.Fatalf("blueprint config version %q, want %q", question.Blueprint.ConfigVersion, service.ConfigVersion())
+		}
+
+		regenerated, err := service.Regenerate(*question.Blueprint)
+		if err != nil {
+			t.Fatalf("regenerate seed %d: %v", question.Blueprint.Seed, err)
+		}
+		if !reflect.DeepEqual(question, regenerated) {
+			t.Errorf("regenerated question differs for seed %d:\n%+v\n%+v", question.Blueprint.Seed, question, regenerated)
+		}
+
+		resampled, err := service.GenerateFromSeed(question.Blueprint.Seed, "", "", "")
+		if err != nil {
+			t.Fatalf("generate from seed %d: %v", question.Blueprint.Seed, err)
+		}
+		if !reflect.DeepEqual(question, resampled) {
+			t.Errorf("question generated from seed %d differs", question.Blueprint.Seed)
+		}
+	}
+}
+
+func TestGenerateBatchReproducible(t *testing.T) {
+	service := NewService()
+	req := BatchRequest{Num: 24, MasterSeed: 42, Workers: 1}
+	sequential, err := service.GenerateBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("sequential batch: %v", err)
+	}
+
+	req.Workers = 8
+	progressCalls := 0
+	req.OnProgress = func(p Progress) {
+		progressCalls++
+		if p.Total != req.Num || p.Generated > p.Total {
+			t.Errorf("unexpected progress %+v", p)
+		}
+	}
+	parallel, err := service.GenerateBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("parallel batch: %v", err)
+	}
+	if !reflect.DeepEqual(sequential, parallel) {
+		t.Error("parallel generation differs from sequential generation with the same master seed")
+	}
+	if progressCalls < req.Num {
+		t.Errorf("got %d progress calls, want at least %d", progressCalls, req.Num)
+	}
+}
+
+func TestGenerateBatchNotation(t *testing.T) {
+	service := NewService()
+	req := BatchRequest{Num: 24, MasterSeed: 42, Workers: 4}
+	canonical, err := service.GenerateBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unicode batch: %v", err)
+	}
+	req.Renderer = render.Renderer{Notation: render.ASCII, MinimalParens: true}
+	ascii, err := service.GenerateBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ascii batch: %v", err)
+	}
+	for i, question := range ascii {
+		// 记法只改变文本，题目本身不变
+		if question.Blueprint.Seed != canonical[i].Blueprint.Seed || !reflect.DeepEqual(question.CorrectAnswerIndex, canonical[i].CorrectAnswerIndex) ||
+			question.Fingerprint != canonical[i].Fingerprint {
+			t.Fatalf("question %d differs from the unicode batch", i)
+		}
+		if question.Notation != "ascii" || question.Blueprint.Notation != "ascii" || !question.Blueprint.MinimalParens {
+			t.Fatalf("question %d: notation not recorded: %q %+v", i, question.Notation, question.Blueprint)
+		}
+		if strings.ContainsAny(question.QuestionText+strings.Join(question.Options, ""), "¬∧∨→↔") {
+			t.Errorf("question %d still contains unicode connectives: %s %v", i, question.QuestionText, question.Options)
+		}
+		regenerated, err := service.Regenerate(*question.Blueprint)
+		if err != nil {
+			t.Fatalf("regenerate seed %d: %v", question.Blueprint.Seed, err)
+		}
+		if !reflect.DeepEqual(question, regenerated) {
+			t.Errorf("regenerated question differs for seed %d:\n%+v\n%+v", question.Blueprint.Seed, question, regenerated)
+		}
+	}
+}
+
+// TestHardProfilesUseExtendedConnectives 等价、范式难题按配置使用 ⊕ / ↑ / ↓ / ⊤ / ⊥，
+// 等价题的解析中出现对应的展开规则
+func TestHardProfilesUseExtendedConnectives(t *testing.T) {
+	service := NewService()
+	for _, category := range []models.QuestionCategory{models.QuestionCategoryEquivalence, models.QuestionCategoryNormalForm} {
+		questions, err := service.GenerateBatch(context.Background(), BatchRequest{Num: 40, Category: category, Difficulty: models.QuestionDifficultyHard, MasterSeed: 5})
+		if err != nil {
+			t.Fatalf("%s batch: %v", category, err)
+		}
+		extended, expanded := 0, 0
+		for _, question := range questions {
+			if strings.ContainsAny(question.QuestionText+strings.Join(question.Options, ""), "⊕↑↓⊤⊥") {
+				extended++
+			}
+			if strings.Contains(question.Explanation, "xor expansion") || strings.Contains(question.Explanation, "nand nor expansion") {
+				expanded++
+			}
+		}
+		if extended == 0 {
+			t.Errorf("%s: no hard question uses ⊕, ↑, ↓, ⊤ or ⊥", category)
+		}
+		if category == models.QuestionCategoryEquivalence && expanded == 0 {
+			t.Errorf("no hard equivalence question is explained with the xor or nand/nor expansion")
+		}
+	}
+}
+
+// TestHardEquivalenceUsesManyVariables 等价难题按配置从 10~12 个变量中取用，
+// 变量数达到 sat_min_vars 的题目由 SAT 求解器判定，审核通过且能按 blueprint 重新生成
+func TestHardEquivalenceUsesManyVariables(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewService()
+	questions, err := service.GenerateBatch(context.Background(), BatchRequest{Num: 30, Category: models.QuestionCategoryEquivalence, Difficulty: models.QuestionDifficultyHard, MasterSeed: 3})
+	if err != nil {
+		t.Fatalf("hard equivalence batch: %v", err)
+	}
+	auditor := audit.New(validator.NewSATValidator())
+	large := 0
+	for _, question := range questions {
+		if len(question.Formulas.Vars) < cfg.Validator.SATMinVars {
+			continue
+		}
+		large++
+		if result := auditor.Check(question); !result.OK() {
+			t.Errorf("seed %d: %+v", question.Blueprint.Seed, result.Findings)
+		}
+		if question.Blueprint.Profile.MinVars == 0 || question.Blueprint.Profile.EqMaxExprLength == 0 {
+			t.Errorf("seed %d: profile overrides not recorded: %+v", question.Blueprint.Seed, question.Blueprint.Profile)
+		}
+		regenerated, err := service.Regenerate(*question.Blueprint)
+		if err != nil {
+			t.Fatalf("regenerate seed %d: %v", question.Blueprint.Seed, err)
+		}
+		if !reflect.DeepEqual(question, regenerated) {
+			t.Errorf("regenerated question differs for seed %d", question.Blueprint.Seed)
+		}
+	}
+	if large == 0 {
+		t.Errorf("no hard equivalence question uses %d or more variables", cfg.Validator.SATMinVars)
+	}
+}
+
+// TestGenerateBatchExclude 题库中已有的题目被跳过，换下一个种子重新生成
+func TestGenerateBatchExclude(t *testing.T) {
+	service := NewService()
+	req := BatchRequest{Num: 12, MasterSeed: 9, Workers: 4}
+	first, err := service.GenerateBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("first batch: %v", err)
+	}
+	bank := make(map[string]bool)
+	for _, question := range first {
+		if question.Fingerprint == "" {
+			t.Fatalf("seed %d: no fingerprint", question.Blueprint.Seed)
+		}
+		bank[question.Fingerprint] = true
+	}
+
+	duplicates := 0
+	req.Exclude = func(fingerprint string) bool { return bank[fingerprint] }
+	req.OnProgress = func(p Progress) {
+		if errors.Is(p.Err, ErrDuplicate) {
+			duplicates++
+		}
+	}
+	second, err := service.GenerateBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("second batch: %v", err)
+	}
+	for i, question := range second {
+		if bank[question.Fingerprint] {
+			t.Errorf("question %d duplicates the bank: %s", i, question.QuestionText)
+		}
+	}
+	// 同一个主种子的第一次尝试与第一批完全相同，每道题至少跳过一次
+	if duplicates < req.Num {
+		t.Errorf("skipped %d duplicates, want at least %d", duplicates, req.Num)
+	}
+}
+
+// TestQuestionFormulas 只用结构化公式重新计算等价题与推理题的正确选项
+func TestQuestionFormulas(t *testing.T) {
+	service := NewService()
+	v := validator.NewBitsetValidator()
+	for _, category := range []models.QuestionCategory{models.QuestionCategoryEquivalence, models.QuestionCategoryInference} {
+		for seed := int64(1); seed <= 20; seed++ {
+			question, err := service.GenerateFromSeed(seed, category, "", models.QuestionTypeMultipleChoice)
+			if err != nil {
+				t.Fatalf("%s seed %d: %v", category, seed, err)
+			}
+			f := question.Formulas
+			intent := question.Blueprint.Intent
+			if intent == "INF_COUNTEREXAMPLE" {
+				continue
+			}
+			if f == nil || len(f.Options) != len(question.Options) {
+				t.Fatalf("%s seed %d: missing option formulas: %+v", category, seed, f)
+			}
+			target, err := core.DecodeFormula(f.Target)
+			if err != nil {
+				t.Fatalf("%s seed %d: %v", category, seed, err)
+			}
+			premises := make([]*core.Node, len(f.Premises))
+			for i, p := range f.Premises {
+				if premises[i], err = core.DecodeFormula(p); err != nil {
+					t.Fatalf("%s seed %d: %v", category, seed, err)
+				}
+			}
+
+			want := make([]int, 0)
+			for i, o := range f.Options {
+				option, err := core.DecodeFormula(o)
+				if err != nil || option == nil {
+					t.Fatalf("%s seed %d: option %d: %v", category, seed, i, err)
+				}
+				var holds bool
+				switch intent {
+				case "EQ_EQUIVALENT":
+					holds = v.Equivalent(target, option, f.Vars)
+				case "EQ_NONEQUIVALENT":
+					holds = !v.Equivalent(target, option, f.Vars)
+				case "INF_DERIVABLE":
+					holds = v.Derivable(premises, option, f.Vars)
+				case "INF_UNDERIVABLE":
+					holds = !v.Derivable(premises, option, f.Vars)
+				default:
+					t.Fatalf("unexpected intent %s", intent)
+				}
+				if holds {
+					want = append(want, i)
+				}
+			}
+			got := append([]int(nil), question.CorrectAnswerIndex...)
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s seed %d (%s): correct options %v, recomputed %v", category, seed, intent, got, want)
+			}
+		}
+	}
+}
+
+// TestQuestionOptionSets 赋值、分支和子句集选项有集合形式，真值表题的正确选项可以只用它们重新计算
+func TestQuestionOptionSets(t *testing.T) {
+	service := NewService()
+	v := validator.NewBitsetValidator()
+	questions, err := service.GenerateBatch(context.Background(), BatchRequest{Num: 200, MasterSeed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, question := range questions {
+		f, intent := question.Formulas, question.Blueprint.Intent
+		if _, ok := optionSetParsers[intent]; ok && question.Type != models.QuestionTypeTrueFalse {
+			if len(f.OptionSets) != len(question.Options) || slices.ContainsFunc(f.OptionSets, func(set []*models.FormulaNode) bool { return set == nil }) {
+				t.Fatalf("%s seed %d: missing option sets for %q", intent, question.Blueprint.Seed, question.Options)
+			}
+		}
+		switch {
+		case intent == "TT_EVAL_AT_ASSIGNMENT" && len(f.Assignment) == 0,
+			intent == "RES_RESOLVENT" && len(f.ClauseSet) == 0,
+			(intent == "CLS_CLASS_TF" || intent == "NF_PAIR_TF") && f.Label == "":
+			t.Errorf("%s seed %d: stem not recorded: %+v", intent, question.Blueprint.Seed, f)
+		}
+		if intent != "TT_TRUE_ASSIGNMENTS" && intent != "TT_FALSE_ASSIGNMENTS" {
+			continue
+		}
+		target := decodeAll(t, []*models.FormulaNode{f.Target})[0]
+		want := make([]int, 0)
+		for i, set := range f.OptionSets {
+			assign := make(map[string]bool)
+			for _, literal := range decodeAll(t, set) {
+				if literal.Kind == core.Not {
+					assign[literal.Left.Name] = false
+				} else {
+					assign[literal.Name] = true
+				}
+			}
+			if v.Eval(target, assign) == (intent == "TT_TRUE_ASSIGNMENTS") {
+				want = append(want, i)
+			}
+		}
+		got := append([]int(nil), question.CorrectAnswerIndex...)
+		sort.Ints(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s seed %d: correct options %v, recomputed %v", intent, question.Blueprint.Seed, got, want)
+		}
+	}
+}
+
+func TestGenerateBatchCancelled(t *testing.T) {
+	service := NewService()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := service.GenerateBatch(ctx, BatchRequest{Num: 10, MasterSeed: 7, Workers: 4})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestProofExerciseTargetsAreProvable(t *testing.T) {
+	service := NewService()
+	for seed := int64(0); seed < 20; seed++ {
+		question, err := service.GenerateFromSeed(seed, models.QuestionCategoryInference, "", "")
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		exercise, err := service.ProofExercise(*question.Blueprint)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if len(exercise.Premises) == 0 || exercise.Target == nil || exercise.ConfigChanged {
+			t.Fatalf("seed %d: unexpected exercise %+v", seed, exercise)
+		}
+		if _, ok := proof.Prove(exercise.Premises, exercise.Target, 16); !ok {
+			t.Errorf("seed %d (%s): target %s is not provable", seed, exercise.TemplateName, helper.Stringify(exercise.Target))
+		}
+
+		// 配置变化后重放的实例可能与原题不同，练习要标记出来
+		old := *question.Blueprint
+		old.ConfigVersion = "previous"
+		if exercise, err := service.ProofExercise(old); err != nil || !exercise.ConfigChanged {
+			t.Errorf("seed %d: config change not flagged: %v", seed, err)
+		}
+	}
+
+	question, err := service.GenerateFromSeed(1, models.QuestionCategoryTruthTable, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ProofExercise(*question.Blueprint); !errors.Is(err, ErrNotProofExercise) {
+		t.Errorf("expected ErrNotProofExercise, got %v", err)
+	}
+}
+
+// TestRegenerateOldGeneratorVersion 随机字母之前的 blueprint 仍按原来的字母重现：
+// 改名使用独立的随机流，两个版本的题目只差变量名
+func TestRegenerateOldGeneratorVersion(t *testing.T) {
+	service := NewService()
+	for seed := int64(1); seed <= 20; seed++ {
+		question, err := service.GenerateFromSeed(seed, models.QuestionCategoryInference, "", "")
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if question.Blueprint.GeneratorVersion != GeneratorVersion {
+			t.Fatalf("seed %d: blueprint generator version %d, want %d", seed, question.Blueprint.GeneratorVersion, GeneratorVersion)
+		}
+		old := *question.Blueprint
+		old.GeneratorVersion = 0
+		regenerated, err := service.Regenerate(old)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if regenerated.Blueprint.GeneratorVersion != 0 || !reflect.DeepEqual(regenerated.CorrectAnswerIndex, question.CorrectAnswerIndex) {
+			t.Fatalf("seed %d: old version regenerated a different question", seed)
+		}
+		if !core.AlphaEquivalentAll(decodeAll(t, question.Formulas.Premises), decodeAll(t, regenerated.Formulas.Premises)) {
+			t.Errorf("seed %d: premises differ by more than the letters", seed)
+		}
+
+		current, err := service.ProofExercise(*question.Blueprint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		previous, err := service.ProofExercise(old)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !core.AlphaEquivalentAll(append(current.Premises, current.Target), append(previous.Premises, previous.Target)) {
+			t.Errorf("seed %d: proof exercises differ by more than the letters", seed)
+		}
+	}
+}
+
+func decodeAll(t *testing.T, formulas []*models.FormulaNode) []*core.Node {
+	t.Helper()
+	nodes := make([]*core.Node, len(formulas))
+	for i, f := range formulas {
+		node, err := core.DecodeFormula(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = node
+	}
+	return nodes
+}
@@ -0,0 +1,231 @@
+// Package tableau builds analytic (semantic) tableaux for sets of formulas.
+// Every formula is decomposed by the α rules (one branch) and β rules (two
+// branches) down to literals; a branch closes as soon as it holds a variable
+// together with its negation, or ⊥. The formula set is unsatisfiable exactly
+// when every branch closes, and the literals of an open branch describe a
+// satisfying assignment.
+package tableau
+
+import (
+	"backend/generation/core"
+	"backend/generation/normalform"
+	"errors"
+	"strings"
+)
+
+var ErrTooLarge = errors.New("tableau: more branches than allowed")
+
+// Branch is one root-to-leaf path of a fully expanded tableau.
+type Branch struct {
+	// Formulas lists every formula placed on the branch, the root formulas
+	// first, then the results of the rules in the order they were applied.
+	Formulas []*core.Node
+	// Literals are the literals reached on the branch, sorted by variable.
+	Literals []normalform.Literal
+	Closed   bool
+	// Clash is the variable occurring both plain and negated on a closed
+	// branch, or "⊥" when the branch closed on ⊥ / ¬⊤.
+	Clash string
+}
+
+// String renders the literals of the branch, e.g. "p, ¬q"; a branch without
+// literals renders as "∅".
+func (b Branch) String() string {
+	if len(b.Literals) == 0 {
+		return "∅"
+	}
+	parts := make([]string, len(b.Literals))
+	for i, l := range b.Literals {
+		parts[i] = l.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Assignment makes the literals of the branch true. Variables in vars that do
+// not occur on the branch are set to false; for an open branch any value
+// would do.
+func (b Branch) Assignment(vars []string) map[string]bool {
+	assign := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		assign[v] = false
+	}
+	for _, l := range b.Literals {
+		assign[l.Var] = !l.Negated
+	}
+	return assign
+}
+
+// Tableau is the fully expanded tableau of Formulas; Branches are listed left
+// to right.
+type Tableau struct {
+	Formulas []*core.Node
+	Branches []Branch
+}
+
+// Closed reports whether every branch closes, i.e. Formulas is unsatisfiable.
+func (t Tableau) Closed() bool {
+	for _, b := range t.Branches {
+		if !b.Closed {
+			return false
+		}
+	}
+	return true
+}
+
+// Open returns the open branches from left to right.
+func (t Tableau) Open() []Branch {
+	var out []Branch
+	for _, b := range t.Branches {
+		if !b.Closed {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// Build expands formulas into a full tableau. α rules are applied before β
+// rules so that branching happens as late as possible. It fails with
+// ErrTooLarge when more than maxBranches branches would be needed; zero means
+// unlimited.
+func Build(formulas []*core.Node, maxBranches int) (Tableau, error) {
+	t := Tableau{Formulas: formulas}
+	placed := append([]*core.Node(nil), formulas...)
+	pending := append([]*core.Node(nil), formulas...)
+	if err := t.expand(placed, pending, nil, maxBranches); err != nil {
+		return Tableau{}, err
+	}
+	return t, nil
+}
+
+// expand 展开一条分支：placed 为分支上已有的公式，pending 为尚未分解的公式
+func (t *Tableau) expand(placed, pending []*core.Node, lits []normalform.Literal, maxBranches int) error {
+	for len(pending) > 0 {
+		idx := nextFormula(pending)
+		f := pending[idx]
+		rest := make([]*core.Node, 0, len(pending)-1)
+		rest = append(append(rest, pending[:idx]...), pending[idx+1:]...)
+
+		kind, parts := decompose(f)
+		switch kind {
+		case literal:
+			l := toLiteral(f)
+			if containsLiteral(lits, normalform.Literal{Var: l.Var, Negated: !l.Negated}) {
+				return t.add(Branch{Formulas: placed, Literals: normalform.Clause(append(lits, l)).Sorted(), Closed: true, Clash: l.Var}, maxBranches)
+			}
+			if !containsLiteral(lits, l) {
+				lits = append(append([]normalform.Literal(nil), lits...), l)
+			}
+			pending = rest
+		case closure:
+			return t.add(Branch{Formulas: placed, Literals: normalform.Clause(lits).Sorted(), Closed: true, Clash: "⊥"}, maxBranches)
+		case alpha:
+			placed = append(append([]*core.Node(nil), placed...), parts[0]...)
+			pending = append(rest, parts[0]...)
+		case beta:
+			for _, side := range parts {
+				branchPlaced := append(append([]*core.Node(nil), placed...), side...)
+				branchPending := append(append([]*core.Node(nil), rest...), side...)
+				if err := t.expand(branchPlaced, branchPending, lits, maxBranches); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return t.add(Branch{Formulas: placed, Literals: normalform.Clause(lits).Sorted()}, maxBranches)
+}
+
+func (t *Tableau) add(b Branch, maxBranches int) error {
+	if maxBranches > 0 && len(t.Branches) >= maxBranches {
+		return ErrTooLarge
+	}
+	t.Branches = append(t.Branches, b)
+	return nil
+}
+
+type ruleKind int
+
+const (
+	literal ruleKind = iota
+	alpha
+	beta
+	// closure 为 ⊥ 或 ¬⊤，分支直接关闭
+	closure
+)
+
+// nextFormula 优先选文字和 α 公式，都没有时取第一个 β 公式
+func nextFormula(pending []*core.Node) int {
+	for i, f := range pending {
+		if kind, _ := decompose(f); kind != beta {
+			return i
+		}
+	}
+	return 0
+}
+
+// decompose 返回公式适用的规则及其结果：α 规则只有一组结果，β 规则每个分支一组
+// ⊤ 与 ¬⊥ 按没有结果的 α 规则处理，直接从分支上消去
+func decompose(f *core.Node) (ruleKind, [][]*core.Node) {
+	not := func(n *core.Node) *core.Node { return &core.Node{Kind: core.Not, Left: n} }
+	switch f.Kind {
+	case core.True:
+		return alpha, [][]*core.Node{{}}
+	case core.False:
+		return closure, nil
+	case core.And:
+		return alpha, [][]*core.Node{{f.Left, f.Right}}
+	case core.Or:
+		return beta, [][]*core.Node{{f.Left}, {f.Right}}
+	case core.Impl:
+		return beta, [][]*core.Node{{not(f.Left)}, {f.Right}}
+	case core.Iff:
+		return beta, [][]*core.Node{{f.Left, f.Right}, {not(f.Left), not(f.Right)}}
+	case core.Xor:
+		return beta, [][]*core.Node{{f.Left, not(f.Right)}, {not(f.Left), f.Right}}
+	case core.Nand:
+		return beta, [][]*core.Node{{not(f.Left)}, {not(f.Right)}}
+	case core.Nor:
+		return alpha, [][]*core.Node{{not(f.Left), not(f.Right)}}
+	case core.Not:
+		inner := f.Left
+		switch inner.Kind {
+		case core.True:
+			return closure, nil
+		case core.False:
+			return alpha, [][]*core.Node{{}}
+		case core.Not:
+			return alpha, [][]*core.Node{{inner.Left}}
+		case core.And:
+			return beta, [][]*core.Node{{not(inner.Left)}, {not(inner.Right)}}
+		case core.Or:
+			return alpha, [][]*core.Node{{not(inner.Left), not(inner.Right)}}
+		case core.Impl:
+			return alpha, [][]*core.Node{{inner.Left, not(inner.Right)}}
+		case core.Iff:
+			return beta, [][]*core.Node{{inner.Left, not(inner.Right)}, {not(inner.Left), inner.Right}}
+		case core.Xor:
+			return beta, [][]*core.Node{{inner.Left, inner.Right}, {not(inner.Left), not(inner.Right)}}
+		case core.Nand:
+			return alpha, [][]*core.Node{{inner.Left, inner.Right}}
+		case core.Nor:
+			return beta, [][]*core.Node{{inner.Left}, {inner.Right}}
+		}
+	}
+	return literal, nil
+}
+
+func toLiteral(f *core.Node) normalform.Literal {
+	if f.Kind == core.Not {
+		return normalform.Literal{Var: f.Left.Name, Negated: true}
+	}
+	return normalform.Literal{Var: f.Name}
+}
+
+func containsLiteral(lits []normalform.Literal, l normalform.Literal) bool {
+	for _, x := range lits {
+		if x == l {
+			return true
+		}
+	}
+	return false
+}
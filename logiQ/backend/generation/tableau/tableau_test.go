@@ -0,0 +1,124 @@
+package tableau
+
+import (
+	"backend/generation/core"
+	"backend/generation/generator/shared"
+	"backend/generation/gentest"
+	"backend/generation/sampler"
+	"backend/generation/validator"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func branchStrings(branches []Branch) []string {
+	out := make([]string, len(branches))
+	for i, b := range branches {
+		out[i] = b.String()
+	}
+	return out
+}
+
+func TestBuildBranches(t *testing.T) {
+	cases := []struct {
+		formulas string
+		branches []string
+		open     []string
+	}{
+		{"p ∧ ¬q", []string{"p, ¬q"}, []string{"p, ¬q"}},
+		{"p ∨ q, ¬p", []string{"p, ¬p", "¬p, q"}, []string{"¬p, q"}},
+		{"p → q, p, ¬q", []string{"p, ¬p, ¬q", "p, q, ¬q"}, nil},
+		{"p ↔ q", []string{"p, q", "¬p, ¬q"}, []string{"p, q", "¬p, ¬q"}},
+		{"¬(p → q)", []string{"p, ¬q"}, []string{"p, ¬q"}},
+		{"¬(p ↔ q)", []string{"p, ¬q", "¬p, q"}, []string{"p, ¬q", "¬p, q"}},
+		{"¬¬p, ¬(p ∨ q)", []string{"p, ¬p"}, nil},
+		{"p ⊕ q", []string{"p, ¬q", "¬p, q"}, []string{"p, ¬q", "¬p, q"}},
+		{"p ↑ q, p", []string{"p, ¬p", "p, ¬q"}, []string{"p, ¬q"}},
+		{"¬(p ↓ q), ¬p", []string{"p, ¬p", "¬p, q"}, []string{"¬p, q"}},
+		{"p ∧ ⊤", []string{"p"}, []string{"p"}},
+		{"p ∨ ¬⊤", []string{"p", "∅"}, []string{"p"}},
+	}
+	for _, c := range cases {
+		tab, err := Build(gentest.MustParseList(t, c.formulas), 0)
+		if err != nil {
+			t.Fatalf("Build(%s): %v", c.formulas, err)
+		}
+		if got := branchStrings(tab.Branches); !equalStrings(got, c.branches) {
+			t.Errorf("Build(%s) branches = %q, want %q", c.formulas, got, c.branches)
+		}
+		if got := branchStrings(tab.Open()); !equalStrings(got, c.open) {
+			t.Errorf("Build(%s) open = %q, want %q", c.formulas, got, c.open)
+		}
+		if tab.Closed() != (len(c.open) == 0) {
+			t.Errorf("Build(%s).Closed() = %v", c.formulas, tab.Closed())
+		}
+	}
+}
+
+func TestClosedBranchesRecordClash(t *testing.T) {
+	tab, err := Build(gentest.MustParseList(t, "p → q, p, ¬q"), 0)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	for _, b := range tab.Branches {
+		if !b.Closed || b.Clash == "" {
+			t.Errorf("branch %s: closed=%v clash=%q", b, b.Closed, b.Clash)
+		}
+		// 根公式在每条分支上
+		if len(b.Formulas) < 3 {
+			t.Errorf("branch %s holds %d formulas", b, len(b.Formulas))
+		}
+	}
+}
+
+// 随机公式集：全部分支关闭当且仅当不可满足，开放分支的赋值满足每个公式
+func TestBuildAgreesWithValidator(t *testing.T) {
+	rng := rand.New(rand.NewPCG(19, 190))
+	v := validator.NewBitsetValidator()
+	prof := sampler.Profile{
+		Vars:       3,
+		MaxDepth:   3,
+		AllowedOps: []core.NodeKind{core.Not, core.And, core.Or, core.Impl, core.Iff, core.Xor, core.Nand, core.Nor, core.True, core.False},
+	}
+	vars := shared.CanonicalVars(3)
+	for i := 0; i < 200; i++ {
+		formulas := []*core.Node{shared.RandomFormula(rng, prof), shared.RandomFormula(rng, prof)}
+		tab, err := Build(formulas, 0)
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		conj := shared.Binary(core.And, formulas[0], formulas[1])
+		_, satisfiable := v.EquivalenceCounterexample(conj, shared.Constant(false), vars)
+		if tab.Closed() == satisfiable {
+			t.Fatalf("formulas %v: closed=%v, satisfiable=%v", formulas, tab.Closed(), satisfiable)
+		}
+		for _, b := range tab.Open() {
+			if !v.Eval(conj, b.Assignment(vars)) {
+				t.Fatalf("open branch %s does not satisfy the formulas", b)
+			}
+		}
+	}
+}
+
+func TestBuildRespectsLimit(t *testing.T) {
+	formulas := gentest.MustParseList(t, "p ∨ q, r ∨ s, t ∨ u")
+	if _, err := Build(formulas, 4); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Build with 4 branches: err = %v, want ErrTooLarge", err)
+	}
+	tab, err := Build(formulas, 8)
+	if err != nil || len(tab.Branches) != 8 {
+		t.Errorf("Build with 8 branches: %d branches, err = %v", len(tab.Branches), err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,356 @@
+package validator
+
+import (
+	"backend/generation/core"
+	"math/bits"
+)
+
+// MaxBitsetVars bounds the number of variables a Program may be evaluated
+// over; 2^20 rows already need 16K words per table.
+const MaxBitsetVars = 20
+
+// varPatterns[i] is the column of variable i inside one 64-row word when rows
+// are numbered like helper.EnumerateAssignments: variable i is true in row r
+// iff bit i of r is set.
+var varPatterns = [6]uint64{
+	0xAAAAAAAAAAAAAAAA,
+	0xCCCCCCCCCCCCCCCC,
+	0xF0F0F0F0F0F0F0F0,
+	0xFF00FF00FF00FF00,
+	0xFFFF0000FFFF0000,
+	0xFFFFFFFF00000000,
+}
+
+type opcode uint8
+
+const (
+	opVar opcode = iota
+	opFalse
+	opNot
+	opAnd
+	opOr
+	opImpl
+	opIff
+	opTrue
+	opXor
+	opNand
+	opNor
+)
+
+// instr 的结果保存在与其下标相同的寄存器中，a / b 指向操作数所在的寄存器
+type instr struct {
+	op   opcode
+	a, b int
+	v    int // opVar 的变量下标
+}
+
+// Program is a formula compiled against a fixed variable order. It
+// evaluates 64 truth-table rows per machine word.
+type Program struct {
+	code []instr
+	vars int
+}
+
+// Compile flattens formula into a post-order instruction list. Variables
+// that do not appear in vars evaluate to false, matching DefaultValidator.
+func Compile(formula *core.Node, vars []string) Program {
+	p := Program{code: make([]instr, 0, countNodes(formula)), vars: len(vars)}
+	p.emit(formula, vars)
+	return p
+}
+
+func countNodes(node *core.Node) int {
+	if node == nil {
+		return 1
+	}
+	return 1 + countNodes(node.Left) + countNodes(node.Right)
+}
+
+// varIndex 变量数很少，线性查找比建 map 更快
+func varIndex(vars []string, name string) (int, bool) {
+	for i, v := range vars {
+		if v == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (p *Program) emit(node *core.Node, vars []string) int {
+	if node == nil {
+		p.code = append(p.code, instr{op: opFalse})
+		return len(p.code) - 1
+	}
+
+	var in instr
+	switch node.Kind {
+	case core.Var:
+		v, ok := varIndex(vars, node.Name)
+		if !ok {
+			in = instr{op: opFalse}
+		} else {
+			in = instr{op: opVar, v: v}
+		}
+	case core.True:
+		in = instr{op: opTrue}
+	case core.Not:
+		in = instr{op: opNot, a: p.emit(node.Left, vars)}
+	case core.And, core.Or, core.Impl, core.Iff, core.Xor, core.Nand, core.Nor:
+		left := p.emit(node.Left, vars)
+		right := p.emit(node.Right, vars)
+		in = instr{op: binaryOpcode(node.Kind), a: left, b: right}
+	default:
+		in = instr{op: opFalse}
+	}
+	p.code = append(p.code, in)
+	return len(p.code) - 1
+}
+
+func binaryOpcode(kind core.NodeKind) opcode {
+	switch kind {
+	case core.And:
+		return opAnd
+	case core.Or:
+		return opOr
+	case core.Impl:
+		return opImpl
+	case core.Xor:
+		return opXor
+	case core.Nand:
+		return opNand
+	case core.Nor:
+		return opNor
+	default:
+		return opIff
+	}
+}
+
+// Table evaluates the program on all 2^n rows at once.
+func (p Program) Table() Bits {
+	out := newBits(p.vars)
+	regs := p.registers()
+	for w := range out.words {
+		out.words[w] = p.evalWord(w, regs)
+	}
+	out.trim()
+	return out
+}
+
+// registers 为求值准备寄存器，同一 Program 逐字求值时可重复使用
+func (p Program) registers() []uint64 {
+	return make([]uint64, len(p.code))
+}
+
+// words 返回 n 个变量的真值表占用的字数，以及最后一个字的有效位掩码
+func words(vars int) (int, uint64) {
+	rows := 1 << vars
+	if rows >= 64 {
+		return rows / 64, ^uint64(0)
+	}
+	return 1, (uint64(1) << rows) - 1
+}
+
+// evalWord 计算第 w 个字（第 64w 到 64w+63 行）上的取值
+func (p Program) evalWord(w int, regs []uint64) uint64 {
+	for i, in := range p.code {
+		switch in.op {
+		case opVar:
+			regs[i] = varWord(in.v, w)
+		case opFalse:
+			regs[i] = 0
+		case opNot:
+			regs[i] = ^regs[in.a]
+		case opAnd:
+			regs[i] = regs[in.a] & regs[in.b]
+		case opOr:
+			regs[i] = regs[in.a] | regs[in.b]
+		case opImpl:
+			regs[i] = ^regs[in.a] | regs[in.b]
+		case opIff:
+			regs[i] = ^(regs[in.a] ^ regs[in.b])
+		case opTrue:
+			regs[i] = ^uint64(0)
+		case opXor:
+			regs[i] = regs[in.a] ^ regs[in.b]
+		case opNand:
+			regs[i] = ^(regs[in.a] & regs[in.b])
+		case opNor:
+			regs[i] = ^(regs[in.a] | regs[in.b])
+		}
+	}
+	if len(regs) == 0 {
+		return 0
+	}
+	return regs[len(regs)-1]
+}
+
+func varWord(v, w int) uint64 {
+	if v < len(varPatterns) {
+		return varPatterns[v]
+	}
+	if (w>>(v-len(varPatterns)))&1 == 1 {
+		return ^uint64(0)
+	}
+	return 0
+}
+
+// Bits is a truth table column: bit r is the formula's value in row r.
+type Bits struct {
+	words []uint64
+	rows  int
+}
+
+func newBits(vars int) Bits {
+	rows := 1 << vars
+	return Bits{words: make([]uint64, (rows+63)/64), rows: rows}
+}
+
+// trim 清掉最后一个字中超出行数的位，保证比较和计数不受影响
+func (b Bits) trim() {
+	if rem := b.rows % 64; rem != 0 {
+		b.words[len(b.words)-1] &= (uint64(1) << rem) - 1
+	}
+}
+
+// Rows returns the number of rows in the table.
+func (b Bits) Rows() int { return b.rows }
+
+// Row reports the value in row r.
+func (b Bits) Row(r int) bool {
+	return b.words[r/64]>>(r%64)&1 == 1
+}
+
+// Count returns the number of true rows.
+func (b Bits) Count() int {
+	n := 0
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// BitsetValidator answers Equivalent and Derivable by comparing whole truth
+// tables word by word instead of evaluating one map-based assignment at a
+// time. It falls back to DefaultValidator beyond MaxBitsetVars variables.
+type BitsetValidator struct {
+	fallback DefaultValidator
+}
+
+func NewBitsetValidator() BitsetValidator { return BitsetValidator{} }
+
+// Eval 只计算一行，逐节点求值已经足够快
+func (v BitsetValidator) Eval(formula *core.Node, assign map[string]bool) bool {
+	return v.fallback.Eval(formula, assign)
+}
+
+func (v BitsetValidator) Equivalent(a, b *core.Node, vars []string) bool {
+	if len(vars) > MaxBitsetVars {
+		return v.fallback.Equivalent(a, b, vars)
+	}
+	pa, pb := Compile(a, vars), Compile(b, vars)
+	ra, rb := pa.registers(), pb.registers()
+	n, last := words(len(vars))
+	for w := 0; w < n; w++ {
+		mask := ^uint64(0)
+		if w == n-1 {
+			mask = last
+		}
+		// 逐字比较，发现不同即可提前返回
+		if (pa.evalWord(w, ra)^pb.evalWord(w, rb))&mask != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (v BitsetValidator) EquivalenceCounterexample(a, b *core.Node, vars []string) (map[string]bool, bool) {
+	if len(vars) > MaxBitsetVars {
+		return v.fallback.EquivalenceCounterexample(a, b, vars)
+	}
+	pa, pb := Compile(a, vars), Compile(b, vars)
+	ra, rb := pa.registers(), pb.registers()
+	n, last := words(len(vars))
+	for w := 0; w < n; w++ {
+		mask := ^uint64(0)
+		if w == n-1 {
+			mask = last
+		}
+		if diff := (pa.evalWord(w, ra) ^ pb.evalWord(w, rb)) & mask; diff != 0 {
+			return rowAssignment(vars, w*64+bits.TrailingZeros64(diff)), true
+		}
+	}
+	return nil, false
+}
+
+func (v BitsetValidator) DerivationCounterexample(premises []*core.Node, concl *core.Node, vars []string) (map[string]bool, bool) {
+	if len(vars) > MaxBitsetVars {
+		return v.fallback.DerivationCounterexample(premises, concl, vars)
+	}
+	progs := make([]Program, len(premises))
+	regs := make([][]uint64, len(premises))
+	for i, prem := range premises {
+		progs[i] = Compile(prem, vars)
+		regs[i] = progs[i].registers()
+	}
+	pc := Compile(concl, vars)
+	rc := pc.registers()
+
+	n, last := words(len(vars))
+	for w := 0; w < n; w++ {
+		support := ^uint64(0)
+		if w == n-1 {
+			support = last
+		}
+		for i := range progs {
+			support &= progs[i].evalWord(w, regs[i])
+		}
+		if bad := support &^ pc.evalWord(w, rc); bad != 0 {
+			return rowAssignment(vars, w*64+bits.TrailingZeros64(bad)), true
+		}
+	}
+	return nil, false
+}
+
+// rowAssignment 把真值表的行号还原成赋值，与 helper.EnumerateAssignments 的顺序一致
+func rowAssignment(vars []string, row int) map[string]bool {
+	assign := make(map[string]bool, len(vars))
+	for i, name := range vars {
+		assign[name] = (row>>i)&1 == 1
+	}
+	return assign
+}
+
+func (v BitsetValidator) Derivable(premises []*core.Node, concl *core.Node, vars []string) bool {
+	if len(vars) > MaxBitsetVars {
+		return v.fallback.Derivable(premises, concl, vars)
+	}
+	progs := make([]Program, len(premises))
+	regs := make([][]uint64, len(premises))
+	for i, prem := range premises {
+		progs[i] = Compile(prem, vars)
+		regs[i] = progs[i].registers()
+	}
+	pc := Compile(concl, vars)
+	rc := pc.registers()
+
+	n, last := words(len(vars))
+	hasSupport := false
+	for w := 0; w < n; w++ {
+		// support 为本字中所有前提同时为真的行
+		support := ^uint64(0)
+		if w == n-1 {
+			support = last
+		}
+		for i := range progs {
+			support &= progs[i].evalWord(w, regs[i])
+		}
+		if support == 0 {
+			continue
+		}
+		hasSupport = true
+		if support&^pc.evalWord(w, rc) != 0 {
+			return false
+		}
+	}
+	return hasSupport
+}
@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+var testVars = []string{"p", "q", "r", "s", "t", "u", "v", "w"}
+
+// randomNode 生成深度不超过 depth 的随机公式，只使用 vars 中的变量
+func randomNode(rng *rand.Rand, vars []string, depth int) *core.Node {
+	if depth <= 0 || rng.IntN(4) == 0 {
+		return &core.Node{Kind: core.Var, Name: vars[rng.IntN(len(vars))]}
+	}
+	kinds := []core.NodeKind{core.Not, core.And, core.Or, core.Impl, core.Iff, core.Xor, core.Nand, core.Nor, core.True, core.False}
+	kind := kinds[rng.IntN(len(kinds))]
+	switch kind {
+	case core.Not:
+		return &core.Node{Kind: core.Not, Left: randomNode(rng, vars, depth-1)}
+	case core.True, core.False:
+		return &core.Node{Kind: kind}
+	}
+	return &core.Node{Kind: kind, Left: randomNode(rng, vars, depth-1), Right: randomNode(rng, vars, depth-1)}
+}
+
+func TestProgramTableMatchesEval(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 10))
+	def := NewDefaultValidator()
+	for n := 1; n <= len(testVars); n++ {
+		vars := testVars[:n]
+		for i := 0; i < 50; i++ {
+			formula := randomNode(rng, vars, 5)
+			table := Compile(formula, vars).Table()
+			if table.Rows() != 1<<n {
+				t.Fatalf("table has %d rows, want %d", table.Rows(), 1<<n)
+			}
+			for r, assign := range helper.EnumerateAssignments(vars) {
+				if table.Row(r) != def.Eval(formula, assign) {
+					t.Fatalf("%s row %d (%s): bitset %v, eval %v", helper.Stringify(formula), r, helper.AssignmentStringify(vars, assign), table.Row(r), def.Eval(formula, assign))
+				}
+			}
+		}
+	}
+}
+
+func TestBitsetValidatorAgreesWithDefault(t *testing.T) {
+	rng := rand.New(rand.NewPCG(2, 20))
+	def := NewDefaultValidator()
+	bit := NewBitsetValidator()
+	for i := 0; i < 500; i++ {
+		vars := testVars[:1+rng.IntN(7)]
+		a := randomNode(rng, vars, 4)
+		b := randomNode(rng, vars, 4)
+		if got, want := bit.Equivalent(a, b, vars), def.Equivalent(a, b, vars); got != want {
+			t.Fatalf("Equivalent(%s, %s) = %v, want %v", helper.Stringify(a), helper.Stringify(b), got, want)
+		}
+		if got, want := bit.Equivalent(a, a, vars), true; got != want {
+			t.Fatalf("Equivalent(%s, itself) = %v", helper.Stringify(a), got)
+		}
+
+		premises := []*core.Node{a, randomNode(rng, vars, 2)}
+		if got, want := bit.Derivable(premises, b, vars), def.Derivable(premises, b, vars); got != want {
+			t.Fatalf("Derivable(%s, %s ⊢ %s) = %v, want %v", helper.Stringify(premises[0]), helper.Stringify(premises[1]), helper.Stringify(b), got, want)
+		}
+	}
+}
+
+func TestBitsetDerivableUnsatisfiablePremises(t *testing.T) {
+	p := &core.Node{Kind: core.Var, Name: "p"}
+	notP := &core.Node{Kind: core.Not, Left: p}
+	if NewBitsetValidator().Derivable([]*core.Node{p, notP}, p, []string{"p"}) {
+		t.Error("contradictory premises should not derive anything")
+	}
+}
+
+func benchmarkCases(n int) ([]*core.Node, []*core.Node, []string) {
+	rng := rand.New(rand.NewPCG(3, uint64(n)))
+	vars := testVars[:n]
+	as := make([]*core.Node, 64)
+	bs := make([]*core.Node, 64)
+	for i := range as {
+		as[i] = randomNode(rng, vars, 5)
+		bs[i] = randomNode(rng, vars, 5)
+	}
+	return as, bs, vars
+}
+
+func benchmarkEquivalent(b *testing.B, v Validator) {
+	for _, n := range []int{4, 5, 6} {
+		as, bs, vars := benchmarkCases(n)
+		b.Run(fmt.Sprintf("vars=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				v.Equivalent(as[i%len(as)], bs[i%len(bs)], vars)
+			}
+		})
+	}
+}
+
+func benchmarkDerivable(b *testing.B, v Validator) {
+	for _, n := range []int{4, 5, 6} {
+		as, bs, vars := benchmarkCases(n)
+		b.Run(fmt.Sprintf("vars=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				premises := []*core.Node{as[i%len(as)], bs[(i+1)%len(bs)]}
+				v.Derivable(premises, bs[i%len(bs)], vars)
+			}
+		})
+	}
+}
+
+func BenchmarkEquivalentDefault(b *testing.B) { benchmarkEquivalent(b, NewDefaultValidator()) }
+func BenchmarkEquivalentBitset(b *testing.B)  { benchmarkEquivalent(b, NewBitsetValidator()) }
+func BenchmarkDerivableDefault(b *testing.B)  { benchmarkDerivable(b, NewDefaultValidator()) }
+func BenchmarkDerivableBitset(b *testing.B)   { benchmarkDerivable(b, NewBitsetValidator()) }
@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"backend/generation/config"
+	"backend/generation/core"
+	"fmt"
+)
+
+// 可在 config.yaml 的 validator.engine 中选择的实现
+const (
+	EngineTruthTable = "truth_table"
+	EngineBitset     = "bitset"
+	EngineSAT        = "sat"
+	EngineHybrid     = "hybrid"
+)
+
+// defaultSATMinVars hybrid 模式下未配置 sat_min_vars 时的阈值
+const defaultSATMinVars = 8
+
+// New builds the validator selected by cfg. An empty engine selects bitset.
+func New(cfg config.ValidatorConfig) (Validator, error) {
+	switch cfg.Engine {
+	case EngineTruthTable:
+		return NewDefaultValidator(), nil
+	case "", EngineBitset:
+		return NewBitsetValidator(), nil
+	case EngineSAT:
+		return NewSATValidator(), nil
+	case EngineHybrid:
+		minVars := cfg.SATMinVars
+		if minVars <= 0 {
+			minVars = defaultSATMinVars
+		}
+		return NewHybridValidator(minVars), nil
+	default:
+		return nil, fmt.Errorf("validator: unknown engine %q", cfg.Engine)
+	}
+}
+
+// HybridValidator uses the truth table engine while it is cheap and switches
+// to the SAT solver once a query has satMinVars variables or more.
+type HybridValidator struct {
+	small      BitsetValidator
+	large      SATValidator
+	satMinVars int
+}
+
+func NewHybridValidator(satMinVars int) HybridValidator {
+	return HybridValidator{satMinVars: satMinVars}
+}
+
+func (v HybridValidator) Eval(formula *core.Node, assign map[string]bool) bool {
+	return v.small.Eval(formula, assign)
+}
+
+func (v HybridValidator) Equivalent(a, b *core.Node, vars []string) bool {
+	if len(vars) >= v.satMinVars {
+		return v.large.Equivalent(a, b, vars)
+	}
+	return v.small.Equivalent(a, b, vars)
+}
+
+func (v HybridValidator) Derivable(premises []*core.Node, concl *core.Node, vars []string) bool {
+	if len(vars) >= v.satMinVars {
+		return v.large.Derivable(premises, concl, vars)
+	}
+	return v.small.Derivable(premises, concl, vars)
+}
+
+func (v HybridValidator) EquivalenceCounterexample(a, b *core.Node, vars []string) (map[string]bool, bool) {
+	if len(vars) >= v.satMinVars {
+		return v.large.EquivalenceCounterexample(a, b, vars)
+	}
+	return v.small.EquivalenceCounterexample(a, b, vars)
+}
+
+func (v HybridValidator) DerivationCounterexample(premises []*core.Node, concl *core.Node, vars []string) (map[string]bool, bool) {
+	if len(vars) >= v.satMinVars {
+		return v.large.DerivationCounterexample(premises, concl, vars)
+	}
+	return v.small.DerivationCounterexample(premises, concl, vars)
+}
@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"backend/generation/core"
+	"backend/generation/sat"
+)
+
+// SATValidator decides Equivalent and Derivable with the CDCL solver from the
+// sat package instead of enumerating assignments, so its cost does not grow
+// with 2^n. A and B are equivalent iff ¬(A ↔ B) is unsatisfiable; premises
+// entail a conclusion iff premises ∧ ¬conclusion is unsatisfiable.
+type SATValidator struct {
+	fallback DefaultValidator
+}
+
+func NewSATValidator() SATValidator { return SATValidator{} }
+
+func (v SATValidator) Eval(formula *core.Node, assign map[string]bool) bool {
+	return v.fallback.Eval(formula, assign)
+}
+
+func (v SATValidator) Equivalent(a, b *core.Node, vars []string) bool {
+	solver, enc := newQuery(vars)
+	la := enc.Encode(a)
+	lb := enc.Encode(b)
+	// 存在一行使 a、b 取值不同即不等价：(a ∨ b) ∧ (¬a ∨ ¬b)
+	solver.AddClause(la, lb)
+	solver.AddClause(la.Not(), lb.Not())
+	fixUnknownVars(solver, enc, vars)
+	return !solver.Solve()
+}
+
+func (v SATValidator) Derivable(premises []*core.Node, concl *core.Node, vars []string) bool {
+	solver, enc := newQuery(vars)
+	for _, prem := range premises {
+		solver.AddClause(enc.Encode(prem))
+	}
+	lc := enc.Encode(concl)
+	fixUnknownVars(solver, enc, vars)
+
+	// 与 DefaultValidator 一致：前提不可同时满足时不算可推出
+	if !solver.Solve() {
+		return false
+	}
+	solver.AddClause(lc.Not())
+	return !solver.Solve()
+}
+
+func (v SATValidator) EquivalenceCounterexample(a, b *core.Node, vars []string) (map[string]bool, bool) {
+	solver, enc := newQuery(vars)
+	la := enc.Encode(a)
+	lb := enc.Encode(b)
+	solver.AddClause(la, lb)
+	solver.AddClause(la.Not(), lb.Not())
+	fixUnknownVars(solver, enc, vars)
+	if !solver.Solve() {
+		return nil, false
+	}
+	return model(solver, enc, vars), true
+}
+
+func (v SATValidator) DerivationCounterexample(premises []*core.Node, concl *core.Node, vars []string) (map[string]bool, bool) {
+	solver, enc := newQuery(vars)
+	for _, prem := range premises {
+		solver.AddClause(enc.Encode(prem))
+	}
+	solver.AddClause(enc.Encode(concl).Not())
+	fixUnknownVars(solver, enc, vars)
+	if !solver.Solve() {
+		return nil, false
+	}
+	return model(solver, enc, vars), true
+}
+
+// model 读出 vars 在求解结果中的取值
+func model(solver *sat.Solver, enc *sat.Encoder, vars []string) map[string]bool {
+	assign := make(map[string]bool, len(vars))
+	for _, name := range vars {
+		assign[name] = solver.Value(enc.Var(name).Var())
+	}
+	return assign
+}
+
+// newQuery 先按 vars 的顺序分配变量，使求解过程与公式中变量的出现顺序无关
+func newQuery(vars []string) (*sat.Solver, *sat.Encoder) {
+	solver := sat.NewSolver()
+	enc := sat.NewEncoder(solver)
+	for _, name := range vars {
+		enc.Var(name)
+	}
+	return solver, enc
+}
+
+// fixUnknownVars 不在 vars 中的变量恒为假，与 DefaultValidator 的 lookup 行为一致
+func fixUnknownVars(solver *sat.Solver, enc *sat.Encoder, vars []string) {
+	known := make(map[string]struct{}, len(vars))
+	for _, name := range vars {
+		known[name] = struct{}{}
+	}
+	for _, name := range enc.Names() {
+		if _, ok := known[name]; !ok {
+			solver.AddClause(enc.Var(name).Not())
+		}
+	}
+}
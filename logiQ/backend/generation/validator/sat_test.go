@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"backend/generation/config"
+	"backend/generation/core"
+	"backend/generation/helper"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSATValidatorAgreesWithDefault(t *testing.T) {
+	rng := rand.New(rand.NewPCG(4, 40))
+	def := NewDefaultValidator()
+	sv := NewSATValidator()
+	for i := 0; i < 500; i++ {
+		vars := testVars[:1+rng.IntN(7)]
+		a := randomNode(rng, vars, 4)
+		b := randomNode(rng, vars, 4)
+		if got, want := sv.Equivalent(a, b, vars), def.Equivalent(a, b, vars); got != want {
+			t.Fatalf("Equivalent(%s, %s) = %v, want %v", helper.Stringify(a), helper.Stringify(b), got, want)
+		}
+		notNot := &core.Node{Kind: core.Not, Left: &core.Node{Kind: core.Not, Left: a}}
+		if !sv.Equivalent(a, notNot, vars) {
+			t.Fatalf("Equivalent(%s, ¬¬itself) = false", helper.Stringify(a))
+		}
+
+		premises := []*core.Node{randomNode(rng, vars, 3), randomNode(rng, vars, 3)}
+		concl := randomNode(rng, vars, 2)
+		if got, want := sv.Derivable(premises, concl, vars), def.Derivable(premises, concl, vars); got != want {
+			t.Fatalf("Derivable(%s, %s ⊢ %s) = %v, want %v", helper.Stringify(premises[0]), helper.Stringify(premises[1]), helper.Stringify(concl), got, want)
+		}
+	}
+}
+
+func TestSATValidatorUnknownVarsAreFalse(t *testing.T) {
+	sv := NewSATValidator()
+	p := &core.Node{Kind: core.Var, Name: "p"}
+	z := &core.Node{Kind: core.Var, Name: "z"}
+	// z 不在 vars 中，按假处理：p ∨ z 与 p 等价
+	if !sv.Equivalent(&core.Node{Kind: core.Or, Left: p, Right: z}, p, []string{"p"}) {
+		t.Fatal("variables outside vars should evaluate to false")
+	}
+}
+
+// chain 构造 v0 → v1, v1 → v2, ..., 以及结论 v0 → vn
+func chain(n int) ([]*core.Node, *core.Node, []string) {
+	vars := make([]string, n+1)
+	for i := range vars {
+		vars[i] = fmt.Sprintf("x%d", i)
+	}
+	v := func(i int) *core.Node { return &core.Node{Kind: core.Var, Name: vars[i]} }
+	premises := make([]*core.Node, n)
+	for i := 0; i < n; i++ {
+		premises[i] = &core.Node{Kind: core.Impl, Left: v(i), Right: v(i + 1)}
+	}
+	return premises, &core.Node{Kind: core.Impl, Left: v(0), Right: v(n)}, vars
+}
+
+func TestSATValidatorManyVariables(t *testing.T) {
+	sv := NewSATValidator()
+	premises, concl, vars := chain(30)
+	if !sv.Derivable(premises, concl, vars) {
+		t.Fatal("implication chain over 31 variables should be derivable")
+	}
+	reversed := &core.Node{Kind: core.Impl, Left: concl.Right, Right: concl.Left}
+	if sv.Derivable(premises, reversed, vars) {
+		t.Fatal("converse of the chain should not be derivable")
+	}
+}
+
+func TestNewEngine(t *testing.T) {
+	for _, engine := range []string{"", EngineTruthTable, EngineBitset, EngineSAT, EngineHybrid} {
+		if _, err := New(config.ValidatorConfig{Engine: engine}); err != nil {
+			t.Errorf("New(%q): %v", engine, err)
+		}
+	}
+	if _, err := New(config.ValidatorConfig{Engine: "oracle"}); err == nil {
+		t.Error("unknown engine should be rejected")
+	}
+}
+
+func BenchmarkEquivalentSAT(b *testing.B) {
+	sv := NewSATValidator()
+	for _, n := range []int{6, 10, 12} {
+		premises, _, vars := chain(n - 1)
+		// (x0 → x1) ∧ (x1 → x2) ∧ ... 与逐个取反两次后的自身比较，属于等价的最坏情况
+		var conj *core.Node
+		for _, prem := range premises {
+			if conj == nil {
+				conj = prem
+				continue
+			}
+			conj = &core.Node{Kind: core.And, Left: conj, Right: prem}
+		}
+		other := &core.Node{Kind: core.Not, Left: &core.Node{Kind: core.Not, Left: conj}}
+		b.Run(fmt.Sprintf("vars=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sv.Equivalent(conj, other, vars)
+			}
+		})
+	}
+}
@@ -5,11 +5,16 @@ import (
 	"backend/generation/helper"
 )
 
-// Validator 这个接口目前没有用到，defaultValidator满足了需求
+// Validator 判断公式取值、等价与可推出；具体实现由 config.yaml 的 validator.engine 选择，见 New
 type Validator interface {
 	Eval(formula *core.Node, assign map[string]bool) bool
 	Equivalent(a, b *core.Node, vars []string) bool
 	Derivable(premises []*core.Node, concl *core.Node, vars []string) bool
+	// EquivalenceCounterexample 返回一个使 a、b 取值不同的赋值；等价时 ok 为 false
+	EquivalenceCounterexample(a, b *core.Node, vars []string) (assign map[string]bool, ok bool)
+	// DerivationCounterexample 返回一个使所有前提为真、结论为假的赋值；不存在时 ok 为 false
+	// 前提不可同时满足时 Derivable 也为 false，但此时没有反例
+	DerivationCounterexample(premises []*core.Node, concl *core.Node, vars []string) (assign map[string]bool, ok bool)
 }
 
 type DefaultValidator struct{}
@@ -35,6 +40,14 @@ func (v DefaultValidator) Eval(formula *core.Node, assign map[string]bool) bool
 		left := v.Eval(formula.Left, assign)
 		right := v.Eval(formula.Right, assign)
 		return left == right
+	case core.Xor:
+		return v.Eval(formula.Left, assign) != v.Eval(formula.Right, assign)
+	case core.Nand:
+		return !(v.Eval(formula.Left, assign) && v.Eval(formula.Right, assign))
+	case core.Nor:
+		return !(v.Eval(formula.Left, assign) || v.Eval(formula.Right, assign))
+	case core.True:
+		return true
 	default:
 		return false
 	}
@@ -76,6 +89,33 @@ func (v DefaultValidator) Derivable(premises []*core.Node, concl *core.Node, var
 	return hasSupportingAssignment
 }
 
+func (v DefaultValidator) EquivalenceCounterexample(a, b *core.Node, vars []string) (map[string]bool, bool) {
+	for _, assign := range helper.EnumerateAssignments(vars) {
+		if v.Eval(a, assign) != v.Eval(b, assign) {
+			return assign, true
+		}
+	}
+	return nil, false
+}
+
+func (v DefaultValidator) DerivationCounterexample(premises []*core.Node, concl *core.Node, vars []string) (map[string]bool, bool) {
+	for _, assign := range helper.EnumerateAssignments(vars) {
+		if v.allTrue(premises, assign) && !v.Eval(concl, assign) {
+			return assign, true
+		}
+	}
+	return nil, false
+}
+
+func (v DefaultValidator) allTrue(formulas []*core.Node, assign map[string]bool) bool {
+	for _, f := range formulas {
+		if !v.Eval(f, assign) {
+			return false
+		}
+	}
+	return true
+}
+
 func lookup(assign map[string]bool, name string) bool {
 	if assign == nil {
 		return false
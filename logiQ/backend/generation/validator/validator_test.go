@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestCounterexamplesAreWitnesses(t *testing.T) {
+	def := NewDefaultValidator()
+	engines := map[string]Validator{
+		EngineTruthTable: def,
+		EngineBitset:     NewBitsetValidator(),
+		EngineSAT:        NewSATValidator(),
+	}
+	for name, v := range engines {
+		rng := rand.New(rand.NewPCG(5, 50))
+		for i := 0; i < 300; i++ {
+			vars := testVars[:1+rng.IntN(7)]
+			a := randomNode(rng, vars, 4)
+			b := randomNode(rng, vars, 4)
+			assign, ok := v.EquivalenceCounterexample(a, b, vars)
+			if ok == def.Equivalent(a, b, vars) {
+				t.Fatalf("%s: EquivalenceCounterexample(%s, %s) found = %v, disagrees with Equivalent", name, helper.Stringify(a), helper.Stringify(b), ok)
+			}
+			if ok && def.Eval(a, assign) == def.Eval(b, assign) {
+				t.Fatalf("%s: %s is not a counterexample for %s ≡ %s", name, helper.AssignmentStringify(vars, assign), helper.Stringify(a), helper.Stringify(b))
+			}
+
+			premises := []*core.Node{randomNode(rng, vars, 3), randomNode(rng, vars, 3)}
+			concl := randomNode(rng, vars, 2)
+			assign, ok = v.DerivationCounterexample(premises, concl, vars)
+			if ok {
+				if !def.Eval(premises[0], assign) || !def.Eval(premises[1], assign) || def.Eval(concl, assign) {
+					t.Fatalf("%s: %s does not refute %s, %s ⊢ %s", name, helper.AssignmentStringify(vars, assign), helper.Stringify(premises[0]), helper.Stringify(premises[1]), helper.Stringify(concl))
+				}
+				if def.Derivable(premises, concl, vars) {
+					t.Fatalf("%s: found a counterexample for a derivable conclusion", name)
+				}
+			} else if !def.Derivable(premises, concl, vars) {
+				// 没有反例却不可推出，只可能是前提不可同时满足
+				if _, sat := def.DerivationCounterexample(premises, &core.Node{Kind: core.Not, Left: premises[0]}, vars); sat {
+					t.Fatalf("%s: missed a counterexample for %s, %s ⊢ %s", name, helper.Stringify(premises[0]), helper.Stringify(premises[1]), helper.Stringify(concl))
+				}
+			}
+		}
+	}
+}
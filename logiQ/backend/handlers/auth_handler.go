@@ -134,7 +134,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// 第3步：生成JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email)
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -177,7 +177,7 @@ func (h *AuthHandler) LoginAdmin(c *gin.Context) {
 	}
 
 	// 第3步：生成JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email)
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
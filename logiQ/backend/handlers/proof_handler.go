@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"backend/middleware"
+	"backend/models"
+	"backend/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProofHandler struct {
+	proofService *services.ProofService
+}
+
+func NewProofHandler(proofService *services.ProofService) *ProofHandler {
+	return &ProofHandler{
+		proofService: proofService,
+	}
+}
+
+// GetExercise 获取证明练习，可用 question_id 指定推理题，否则按 difficulty 随机抽取
+func (h *ProofHandler) GetExercise(c *gin.Context) {
+	userID, exist := middleware.GetUserIDFromContext(c)
+	if !exist {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication information not found"})
+		return
+	}
+
+	var questionID primitive.ObjectID
+	if hex := c.Query("question_id"); hex != "" {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+			return
+		}
+		questionID = id
+	}
+	difficulty := models.QuestionDifficulty(c.Query("difficulty"))
+
+	exercise, err := h.proofService.GetExercise(userID, questionID, difficulty)
+	if err != nil {
+		c.JSON(proofErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, exercise)
+}
+
+// CheckProof 逐行检查学生提交的证明
+func (h *ProofHandler) CheckProof(c *gin.Context) {
+	userID, exist := middleware.GetUserIDFromContext(c)
+	if !exist {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication information not found"})
+		return
+	}
+
+	var req models.CheckProofRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.proofService.CheckProof(userID, &req)
+	if err != nil {
+		c.JSON(proofErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func proofErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrQuestionNotFound), errors.Is(err, services.ErrQuestionInactive),
+		errors.Is(err, services.ErrNoProofExercise):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrQuestionInOpenQuiz):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrNotProofExercise), errors.Is(err, services.ErrQuestionNoBlueprint),
+		errors.Is(err, services.ErrInvalidProofTarget):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"backend/middleware"
 	"backend/models"
 	"backend/services"
+	"backend/utils"
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -11,15 +15,17 @@ import (
 
 type QuestionHandler struct {
 	questionService *services.QuestionService
+	jobService      *services.GenerationJobService
 }
 
-func NewQuestionHandler(questionService *services.QuestionService) *QuestionHandler {
+func NewQuestionHandler(questionService *services.QuestionService, jobService *services.GenerationJobService) *QuestionHandler {
 	return &QuestionHandler{
 		questionService: questionService,
+		jobService:      jobService,
 	}
 }
 
-// GenerateQuestion 向题库中增加题目
+// GenerateQuestion 提交题目生成任务，题目在后台生成并入库
 func (h *QuestionHandler) GenerateQuestion(c *gin.Context) {
 	var req models.GenerateQuestionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -27,13 +33,163 @@ func (h *QuestionHandler) GenerateQuestion(c *gin.Context) {
 		return
 	}
 
-	generatedList, err := h.questionService.GenerateQuestion(&req)
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication information not found"})
+		return
+	}
+
+	job, err := h.jobService.Enqueue(userID, req)
 	if err != nil {
+		if errors.Is(err, services.ErrGenerationJobQueueFull) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	// 返回插入的题目数量
-	c.JSON(http.StatusCreated, generatedList)
+	c.JSON(http.StatusAccepted, models.GenerateQuestionJobResponse{JobID: job.ID, Job: job})
+}
+
+// GetGenerationJob 查询生成任务的状态，任务结束后包含入库的题目
+func (h *QuestionHandler) GetGenerationJob(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, err := h.jobService.GetJob(jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelGenerationJob 取消排队中或执行中的生成任务，已入库的题目保留
+func (h *QuestionHandler) CancelGenerationJob(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, err := h.jobService.Cancel(jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrGenerationJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrGenerationJobFinished):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// CreateGenerationJobStreamToken 签发订阅生成任务进度的短期token
+// EventSource 不能设置 Authorization 请求头，前端先用登录token换取该token，再以 ?token= 连接进度推送
+func (h *QuestionHandler) CreateGenerationJobStreamToken(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication information not found"})
+		return
+	}
+	if _, err := h.jobService.GetJob(jobID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := utils.GenerateStreamToken(userID, jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+// StreamGenerationJob 以 server-sent events 推送生成任务的进度，用 CreateGenerationJobStreamToken 签发的 ?token= 认证
+// 先推送一次 snapshot，之后推送 progress / attempt_failed / inserted 等事件，任务结束时以 end 事件收尾
+func (h *QuestionHandler) StreamGenerationJob(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	claims, err := utils.ParseStreamToken(c.Query("token"))
+	if err != nil || claims.JobID != jobID {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	job, events, unsubscribe, err := h.jobService.Subscribe(jobID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("snapshot", job)
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// 通道关闭表示任务已结束，推送最终状态
+				final, err := h.jobService.GetJob(jobID)
+				if err == nil {
+					final.Questions = nil
+					c.SSEvent("end", final)
+				}
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// RegenerateQuestion 按 blueprint 重新生成题目，用于排查学生反馈的问题题目或在配置变更后重建题库
+func (h *QuestionHandler) RegenerateQuestion(c *gin.Context) {
+	var req models.RegenerateQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question, configChanged, err := h.questionService.RegenerateQuestion(&req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrQuestionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrQuestionNoBlueprint):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrQuestionDuplicate):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"question":       question,
+		"config_changed": configChanged,
+		"replaced":       req.Replace,
+	})
 }
 
 // GetQuestionById 根据id获取题目
@@ -4,6 +4,7 @@ import (
 	"backend/middleware"
 	"backend/models"
 	"backend/services"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"net/http"
@@ -58,13 +59,81 @@ func (h *QuizHandler) SubmitQuiz(c *gin.Context) {
 
 	quiz, err := h.quizService.SubmitQuiz(userID, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, quiz)
 }
 
+// StartQuiz 开始作答，服务端开始计时
+func (h *QuizHandler) StartQuiz(c *gin.Context) {
+	sessionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz session ID"})
+		return
+	}
+
+	userID, exist := middleware.GetUserIDFromContext(c)
+	if !exist {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication information not found"})
+		return
+	}
+
+	session, err := h.quizService.StartQuiz(userID, sessionID)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// SaveAnswer 保存单题作答
+func (h *QuizHandler) SaveAnswer(c *gin.Context) {
+	sessionID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz session ID"})
+		return
+	}
+
+	var req models.SaveAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exist := middleware.GetUserIDFromContext(c)
+	if !exist {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication information not found"})
+		return
+	}
+
+	if err := h.quizService.SaveAnswer(userID, sessionID, &req); err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Answer saved"})
+}
+
+// ResumeQuiz 获取用户未完成的测验会话
+func (h *QuizHandler) ResumeQuiz(c *gin.Context) {
+	userID, exist := middleware.GetUserIDFromContext(c)
+	if !exist {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication information not found"})
+		return
+	}
+
+	session, err := h.quizService.ResumeQuiz(userID)
+	if err != nil {
+		c.JSON(quizErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
 func (h *QuizHandler) GetUserQuizHistory(c *gin.Context) {
 
 	// 从JWT token获取用户ID
@@ -98,3 +167,18 @@ func (h *QuizHandler) GetQuiz(c *gin.Context) {
 
 	c.JSON(http.StatusOK, quiz)
 }
+
+// quizErrorStatus 把测验服务的错误映射为HTTP状态码
+func quizErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrQuizSessionNotFound), errors.Is(err, services.ErrNoUnfinishedQuiz):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrQuizSessionNotActive), errors.Is(err, services.ErrQuizSessionExpired):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrQuestionNotFound), errors.Is(err, services.ErrQuestionInactive),
+		errors.Is(err, services.ErrDuplicateAnswer), errors.Is(err, services.ErrQuestionNotInSession):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -38,6 +38,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// 将用户信息存储到上下文中
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
 		c.Next()
 	}
 }
@@ -52,4 +53,3 @@ func GetUserIDFromContext(c *gin.Context) (primitive.ObjectID, bool) {
 	id, ok := userID.(primitive.ObjectID)
 	return id, ok
 }
-
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole 角色校验中间件，必须放在 AuthMiddleware 之后使用
+// 先用token中的角色快速拒绝，再从数据库读取用户当前角色，
+// 这样被降级的管理员无需等待token过期就会失去权限
+func RequireRole(userService *services.UserService, roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		role, _ := GetRoleFromContext(c)
+		if _, ok := allowed[role]; !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		userID, ok := GetUserIDFromContext(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication information not found"})
+			c.Abort()
+			return
+		}
+
+		// 以数据库中的角色为准
+		user, err := userService.GetUserByID(userID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+		if _, ok := allowed[user.Role]; !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Set("role", user.Role)
+		c.Next()
+	}
+}
+
+// GetRoleFromContext 从上下文获取用户角色
+func GetRoleFromContext(c *gin.Context) (string, bool) {
+	role, exists := c.Get("role")
+	if !exists {
+		return "", false
+	}
+
+	value, ok := role.(string)
+	return value, ok
+}
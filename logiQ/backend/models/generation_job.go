@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GenerationJobStatus string
+
+const (
+	GenerationJobStatusQueued    GenerationJobStatus = "queued"
+	GenerationJobStatusRunning   GenerationJobStatus = "running"
+	GenerationJobStatusCompleted GenerationJobStatus = "completed"
+	GenerationJobStatusFailed    GenerationJobStatus = "failed"
+	GenerationJobStatusCancelled GenerationJobStatus = "cancelled"
+)
+
+// GenerationJob 后台批量生成题目的任务
+type GenerationJob struct {
+	ID             primitive.ObjectID      `json:"id"`
+	CreatedBy      primitive.ObjectID      `json:"created_by"`
+	Request        GenerateQuestionRequest `json:"request"`
+	Status         GenerationJobStatus     `json:"status"` // "queued" | "running" | "completed" | "failed" | "cancelled"
+	Total          int                     `json:"total"`
+	Generated      int                     `json:"generated"`
+	Inserted       int                     `json:"inserted"`
+	FailedAttempts int                     `json:"failed_attempts"` // 生成失败后重试的次数
+	Duplicates     int                     `json:"duplicates"`      // 与题库中已有题目重复而跳过的题目数
+	Error          string                  `json:"error,omitempty"`
+	CreatedAt      time.Time               `json:"created_at"`
+	StartedAt      *time.Time              `json:"started_at,omitempty"`
+	FinishedAt     *time.Time              `json:"finished_at,omitempty"`
+	Questions      []Question              `json:"questions,omitempty"` // 已入库的题目，只在查询任务详情时返回
+}
+
+type GenerationJobEventType string
+
+const (
+	GenerationJobEventProgress      GenerationJobEventType = "progress"
+	GenerationJobEventAttemptFailed GenerationJobEventType = "attempt_failed"
+	GenerationJobEventDuplicate     GenerationJobEventType = "duplicate"
+	GenerationJobEventInserted      GenerationJobEventType = "inserted"
+	GenerationJobEventCompleted     GenerationJobEventType = "completed"
+	GenerationJobEventFailed        GenerationJobEventType = "failed"
+	GenerationJobEventCancelled     GenerationJobEventType = "cancelled"
+)
+
+// GenerationJobEvent 推送给订阅者的任务进度事件，Job 为事件发生时的任务快照（不含题目）
+type GenerationJobEvent struct {
+	Type    GenerationJobEventType `json:"type"`
+	Message string                 `json:"message,omitempty"`
+	Job     GenerationJob          `json:"job"`
+}
+
+// GenerateQuestionJobResponse 提交生成任务后的响应
+type GenerateQuestionJobResponse struct {
+	JobID primitive.ObjectID `json:"job_id"`
+	Job   GenerationJob      `json:"job"`
+}
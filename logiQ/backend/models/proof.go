@@ -0,0 +1,41 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// ProofExerciseResponse 证明练习：推理题模板实例化后的前提和要证明的结论
+type ProofExerciseResponse struct {
+	QuestionID primitive.ObjectID `json:"question_id"`
+	Difficulty QuestionDifficulty `json:"difficulty"`
+	Premises   []string           `json:"premises"`
+	Target     string             `json:"target"`
+	Rules      []string           `json:"rules"` // 可用的推理规则
+	// 题目生成时的配置与当前配置不同，练习可能与原题不一致
+	ConfigChanged bool `json:"config_changed"`
+}
+
+// ProofLineInput 学生写的一行证明，justification 形如 "premise"、"MP 1, 2"、"∧E 3"
+type ProofLineInput struct {
+	Formula       string `json:"formula" binding:"required"`
+	Justification string `json:"justification" binding:"required"`
+}
+
+// CheckProofRequest 提交证明，target 必须是该练习的结论
+type CheckProofRequest struct {
+	QuestionID primitive.ObjectID `json:"question_id" binding:"required"`
+	Target     string             `json:"target" binding:"required"`
+	Lines      []ProofLineInput   `json:"lines" binding:"required,min=1,dive"`
+}
+
+// ProofLineResult 单行的检查结果，行号从 1 开始
+type ProofLineResult struct {
+	Line  int    `json:"line"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// CheckProofResponse 每行都有效且最后一行是目标时 complete 为 true
+type CheckProofResponse struct {
+	Complete bool              `json:"complete"`
+	Lines    []ProofLineResult `json:"lines"`
+	Error    string            `json:"error,omitempty"` // 所有行都有效但没有推出目标时的说明
+}
@@ -13,9 +13,14 @@ const (
 type QuestionCategory string
 
 const (
-	QuestionCategoryTruthTable  QuestionCategory = "truthTable"
-	QuestionCategoryEquivalence QuestionCategory = "equivalence"
-	QuestionCategoryInference   QuestionCategory = "inference"
+	QuestionCategoryTruthTable     QuestionCategory = "truthTable"
+	QuestionCategoryEquivalence    QuestionCategory = "equivalence"
+	QuestionCategoryInference      QuestionCategory = "inference"
+	QuestionCategoryNormalForm     QuestionCategory = "normalForm"
+	QuestionCategoryClassification QuestionCategory = "classification"
+	QuestionCategoryProof          QuestionCategory = "proof"
+	QuestionCategoryResolution     QuestionCategory = "resolution"
+	QuestionCategoryTableau        QuestionCategory = "tableau"
 )
 
 type QuestionDifficulty string
@@ -31,22 +36,94 @@ type Question struct {
 	QuestionText       string             `json:"question_text" bson:"question_text" binding:"required"`
 	Options            []string           `json:"options" bson:"options" binding:"required"`
 	CorrectAnswerIndex []int              `json:"correct_answer_index" bson:"correct_answer_index" binding:"required"`
-	Type               QuestionType       `json:"type" bson:"type" binding:"required,oneof=singleChoice multipleChoice trueFalse"`    // "singleChoice" | "multipleChoice" | "trueFalse"
-	Category           QuestionCategory   `json:"category" bson:"category" binding:"required,oneof=truthTable equivalence inference"` // "truthTable" | "equivalence" | "inference"
-	Difficulty         QuestionDifficulty `json:"difficulty" bson:"difficulty" binding:"required,oneof=easy medium hard"`             // "easy" | "medium" | "hard"
+	Type               QuestionType       `json:"type" bson:"type" binding:"required,oneof=singleChoice multipleChoice trueFalse"`                                                       // "singleChoice" | "multipleChoice" | "trueFalse"
+	Category           QuestionCategory   `json:"category" bson:"category" binding:"required,oneof=truthTable equivalence inference normalForm classification proof resolution tableau"` // "truthTable" | "equivalence" | "inference" | "normalForm" | "classification" | "proof" | "resolution" | "tableau"
+	Difficulty         QuestionDifficulty `json:"difficulty" bson:"difficulty" binding:"required,oneof=easy medium hard"`                                                                // "easy" | "medium" | "hard"
 	IsActive           bool               `json:"is_active" bson:"is_active"`
+	Explanation        string             `json:"explanation,omitempty" bson:"explanation,omitempty"`
+	OptionExplanations []string           `json:"option_explanations,omitempty" bson:"option_explanations,omitempty"` // 与 Options 一一对应，说明每个选项对或错的原因
+	Blueprint          *QuestionBlueprint `json:"blueprint,omitempty" bson:"blueprint,omitempty"`                     // 生成参数，手工录入的题目为空
+	Notation           string             `json:"notation,omitempty" bson:"notation,omitempty"`                       // 公式的记法，为空表示 unicode
+	Formulas           *QuestionFormulas  `json:"formulas,omitempty" bson:"formulas,omitempty"`                       // 题目中公式的结构化形式，手工录入的题目为空
+	Fingerprint        string             `json:"fingerprint,omitempty" bson:"fingerprint,omitempty"`                 // 与变量名、交换律和选项顺序无关的题目指纹，有效题目中唯一；没有结构化公式的题目按文本和选项计算
+}
+
+// FormulaNode 序列化的公式语法树，Op 为配置文件中的运算符名字（VAR、NOT、AND、OR、IMP、IFF、XOR、NAND、NOR、TRUE、FALSE）
+type FormulaNode struct {
+	Op    string       `json:"op" bson:"op"`
+	Name  string       `json:"name,omitempty" bson:"name,omitempty"` // 仅 VAR 有
+	Left  *FormulaNode `json:"left,omitempty" bson:"left,omitempty"`
+	Right *FormulaNode `json:"right,omitempty" bson:"right,omitempty"`
+}
+
+// QuestionFormulas 题目涉及的公式，与显示文本及记法无关，可用于重新校验答案、重新渲染和统计分析
+type QuestionFormulas struct {
+	Target    *FormulaNode   `json:"target,omitempty" bson:"target,omitempty"`         // 题干中的公式或结论
+	Premises  []*FormulaNode `json:"premises,omitempty" bson:"premises,omitempty"`     // 推理类题目的前提
+	Candidate *FormulaNode   `json:"candidate,omitempty" bson:"candidate,omitempty"`   // 判断题中需要判断的公式，如与 Target 比较的公式
+	Options   []*FormulaNode `json:"options,omitempty" bson:"options,omitempty"`       // 与 Options 一一对应，不是公式的选项为 null
+	Vars      []string       `json:"vars" bson:"vars"`                                 // 出现的全部变量，按字典序排列
+	Proof     []ProofLine    `json:"proof,omitempty" bson:"proof,omitempty"`           // 证明题题干中的证明；判断题的最后一行是待判断的步骤
+	Line      int            `json:"line,omitempty" bson:"line,omitempty"`             // 证明题被问的行号
+	Clauses   []*FormulaNode `json:"clauses,omitempty" bson:"clauses,omitempty"`       // 消解题中被消解的两个子句，写成文字的析取，空子句为 FALSE
+	ClauseSet []*FormulaNode `json:"clause_set,omitempty" bson:"clause_set,omitempty"` // 消解题题干中的子句集，子句的写法同 Clauses
+	// 真值表判断题题干中的赋值，每个变量写成文字：p=T 为 p，p=F 为 ¬p
+	Assignment []*FormulaNode `json:"assignment,omitempty" bson:"assignment,omitempty"`
+	// 与 Options 一一对应的集合形式：赋值和表格法分支为文字，消解题的子句集为子句（写法同 Clauses）；
+	// 不是集合的选项为 null
+	OptionSets [][]*FormulaNode `json:"option_sets,omitempty" bson:"option_sets,omitempty"`
+	Label      string           `json:"label,omitempty" bson:"label,omitempty"` // 判断题题干声称的类别或范式，如 "a tautology"、"CNF"
+}
+
+// ProofLine 证明中的一行，行号为下标加一；被问的行隐去的公式为 null、规则为空
+type ProofLine struct {
+	Formula *FormulaNode `json:"formula,omitempty" bson:"formula,omitempty"`
+	Rule    string       `json:"rule,omitempty" bson:"rule,omitempty"`
+	Cites   []int        `json:"cites,omitempty" bson:"cites,omitempty"`
+}
+
+// QuestionBlueprint 记录生成一道题目所需的全部参数，用同样的 blueprint 可以重新生成完全相同的题目
+type QuestionBlueprint struct {
+	Seed           int64              `json:"seed" bson:"seed"`
+	ConfigVersion  string             `json:"config_version" bson:"config_version"` // 生成时配置文件的哈希
+	Difficulty     QuestionDifficulty `json:"difficulty" bson:"difficulty"`
+	Category       QuestionCategory   `json:"category" bson:"category"`
+	Type           QuestionType       `json:"type" bson:"type"`
+	Intent         string             `json:"intent" bson:"intent"`
+	MCCorrectCount int                `json:"mc_correct_count,omitempty" bson:"mc_correct_count,omitempty"`
+	Profile        BlueprintProfile   `json:"profile" bson:"profile"`
+	TemplateIndex  int                `json:"template_index" bson:"template_index"`
+	Notation       string             `json:"notation,omitempty" bson:"notation,omitempty"`             // 为空表示 unicode
+	MinimalParens  bool               `json:"minimal_parens,omitempty" bson:"minimal_parens,omitempty"` // 只保留优先级需要的括号
+	// 生成代码的版本，配置之外影响输出的改动会增加它；0 为推理类题目随机字母之前的版本
+	GeneratorVersion int `json:"generator_version,omitempty" bson:"generator_version,omitempty"`
+}
+
+// BlueprintProfile 采样得到的生成参数
+type BlueprintProfile struct {
+	Vars                 int      `json:"vars" bson:"vars"`
+	MaxDepth             int      `json:"max_depth" bson:"max_depth"`
+	MinVars              int      `json:"min_vars,omitempty" bson:"min_vars,omitempty"`
+	AllowedOps           []string `json:"allowed_ops" bson:"allowed_ops"`
+	EqChainSteps         int      `json:"eq_chain_steps,omitempty" bson:"eq_chain_steps,omitempty"`
+	EqMaxDistractorSteps int      `json:"eq_max_distractor_steps,omitempty" bson:"eq_max_distractor_steps,omitempty"`
+	EqMaxExprLength      int      `json:"eq_max_expr_length,omitempty" bson:"eq_max_expr_length,omitempty"`
+	InfChainSteps        int      `json:"inf_chain_steps,omitempty" bson:"inf_chain_steps,omitempty"`
 }
 
 type GenerateQuestionRequest struct {
 	Number int `json:"number" bson:"number" binding:"required"`
 	// 以下三个字段可选，不提供则表示不限制
-	Category   QuestionCategory   `json:"category" bson:"category" binding:"omitempty,oneof=truthTable equivalence inference"`
+	Category   QuestionCategory   `json:"category" bson:"category" binding:"omitempty,oneof=truthTable equivalence inference normalForm classification proof resolution tableau"`
 	Difficulty QuestionDifficulty `json:"difficulty" bson:"difficulty" binding:"omitempty,oneof=easy medium hard"`
 	Type       QuestionType       `json:"type" bson:"type" binding:"omitempty,oneof=singleChoice multipleChoice trueFalse"`
+	// 公式的记法，默认 unicode；MinimalParens 为 true 时只保留优先级需要的括号
+	Notation      string `json:"notation,omitempty" bson:"notation,omitempty" binding:"omitempty,oneof=unicode ascii latex prefix spoken"`
+	MinimalParens bool   `json:"minimal_parens,omitempty" bson:"minimal_parens,omitempty"`
 }
 
 type GetQuestionListRequest struct {
-	Category   QuestionCategory   `json:"category,omitempty" form:"category" bson:"category,omitempty" binding:"omitempty,oneof=truthTable equivalence inference"`
+	Category   QuestionCategory   `json:"category,omitempty" form:"category" bson:"category,omitempty" binding:"omitempty,oneof=truthTable equivalence inference normalForm classification proof resolution tableau"`
 	Difficulty QuestionDifficulty `json:"difficulty,omitempty" form:"difficulty" bson:"difficulty,omitempty" binding:"omitempty,oneof=easy medium hard"`
 	Type       QuestionType       `json:"type,omitempty" form:"type" bson:"type,omitempty" binding:"omitempty,oneof=singleChoice multipleChoice trueFalse"`
 	Page       int                `json:"page,omitempty" form:"page" bson:"page,omitempty" binding:"omitempty,min=1"`
@@ -59,16 +136,29 @@ type QuestionResponseForAdmin struct {
 	QuestionText       string             `json:"question_text" bson:"question_text" binding:"required"`
 	Options            []string           `json:"options" bson:"options" binding:"required"`
 	CorrectAnswerIndex []int              `json:"correct_answer_index" bson:"correct_answer_index" binding:"required"`
-	Type               QuestionType       `json:"type" bson:"type" binding:"required,oneof=singleChoice multipleChoice trueFalse"`    // "singleChoice" | "multipleChoice" | "trueFalse"
-	Category           QuestionCategory   `json:"category" bson:"category" binding:"required,oneof=truthTable equivalence inference"` // "truthTable" | "equivalence" | "inference"
-	Difficulty         QuestionDifficulty `json:"difficulty" bson:"difficulty" binding:"required,oneof=easy medium hard"`             // "easy" | "medium" | "hard"
+	Type               QuestionType       `json:"type" bson:"type" binding:"required,oneof=singleChoice multipleChoice trueFalse"`                                                       // "singleChoice" | "multipleChoice" | "trueFalse"
+	Category           QuestionCategory   `json:"category" bson:"category" binding:"required,oneof=truthTable equivalence inference normalForm classification proof resolution tableau"` // "truthTable" | "equivalence" | "inference" | "normalForm" | "classification" | "proof" | "resolution" | "tableau"
+	Difficulty         QuestionDifficulty `json:"difficulty" bson:"difficulty" binding:"required,oneof=easy medium hard"`                                                                // "easy" | "medium" | "hard"
 	IsActive           bool               `json:"is_active" bson:"is_active"`
+	Explanation        string             `json:"explanation,omitempty" bson:"explanation,omitempty"`
+	OptionExplanations []string           `json:"option_explanations,omitempty" bson:"option_explanations,omitempty"` // 与 Options 一一对应，说明每个选项对或错的原因
+	Blueprint          *QuestionBlueprint `json:"blueprint,omitempty" bson:"blueprint,omitempty"`                     // 生成参数，手工录入的题目为空
+	Notation           string             `json:"notation,omitempty" bson:"notation,omitempty"`                       // 公式的记法，为空表示 unicode
+	Formulas           *QuestionFormulas  `json:"formulas,omitempty" bson:"formulas,omitempty"`                       // 题目中公式的结构化形式，手工录入的题目为空
+	Fingerprint        string             `json:"fingerprint,omitempty" bson:"fingerprint,omitempty"`                 // 与变量名、交换律和选项顺序无关的题目指纹，有效题目中唯一；没有结构化公式的题目按文本和选项计算
 
 	// 新增的统计字段
 	TotalAnswers   int64   `json:"total_answers" bson:"total_answers"`
 	CorrectAnswers int64   `json:"correct_answers" bson:"correct_answers"`
 	AccuracyRate   float64 `json:"accuracy_rate" bson:"accuracy_rate"`
 }
+
+// RegenerateQuestionRequest 按题目保存的 blueprint 重新生成题目
+type RegenerateQuestionRequest struct {
+	QuestionID primitive.ObjectID `json:"question_id" binding:"required"`
+	Replace    bool               `json:"replace"` // 为 true 时用新结果覆盖题库中的题目
+}
+
 type BatchDeleteRequest struct {
 	IDs []string `json:"ids" binding:"required,dive,hexadecimal"`
 }
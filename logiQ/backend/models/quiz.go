@@ -24,11 +24,13 @@ const (
 type Quiz struct {
 	ID                  primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
 	UserID              primitive.ObjectID `json:"user_id" bson:"user_id"`
+	SessionID           primitive.ObjectID `json:"session_id,omitempty" bson:"session_id,omitempty"`
 	Type                QuizType           `json:"type" bson:"type"` // "randomTasks" | "topicPractice" | "byDifficulty" | "customQuiz"
 	Questions           []QuizQuestion     `json:"questions" bson:"questions"`
 	CorrectQuestionsNum int                `json:"correct_questions_num" bson:"correct_questions_num"`
 	CompletionTime      int                `json:"completion_time" bson:"completion_time"` // 秒
 	CompletedAt         time.Time          `json:"completed_at" bson:"completed_at"`
+	Expired             bool               `json:"expired,omitempty" bson:"expired,omitempty"` // 超时未提交，按截止时保存的作答自动判分
 }
 
 type CreateQuizRequest struct {
@@ -37,8 +39,14 @@ type CreateQuizRequest struct {
 	Difficulty QuestionDifficulty `json:"difficulty,omitempty"` // for byDifficulty
 }
 
+// QuizAnswer 客户端提交的单题作答，只包含题目ID和所选选项，正确答案由服务端查询
+type QuizAnswer struct {
+	QuestionID      primitive.ObjectID `json:"question_id" binding:"required"`
+	UserAnswerIndex []int              `json:"user_answer_index"`
+}
+
+// SubmitQuizRequest 提交测验会话，answers 可携带尚未保存的最后作答
 type SubmitQuizRequest struct {
-	Type           QuizType       `json:"type" binding:"required,oneof=randomTasks topicPractice byDifficulty customQuiz"`
-	Questions      []QuizQuestion `json:"questions" binding:"required"`
-	CompletionTime int            `json:"completion_time" binding:"required"`
+	SessionID primitive.ObjectID `json:"session_id" binding:"required"`
+	Answers   []QuizAnswer       `json:"answers" binding:"omitempty,dive"`
 }
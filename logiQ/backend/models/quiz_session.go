@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QuizSessionStatus 测验会话状态：created → in_progress → submitted/expired
+type QuizSessionStatus string
+
+const (
+	QuizSessionStatusCreated    QuizSessionStatus = "created"
+	QuizSessionStatusInProgress QuizSessionStatus = "in_progress"
+	QuizSessionStatusSubmitted  QuizSessionStatus = "submitted"
+	QuizSessionStatusExpired    QuizSessionStatus = "expired"
+)
+
+// QuizSession 持久化的测验会话，开始/结束时间均由服务端记录
+type QuizSession struct {
+	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Type      QuizType           `json:"type" bson:"type"`
+	Status    QuizSessionStatus  `json:"status" bson:"status"`
+	Questions []QuizQuestion     `json:"questions" bson:"questions"`
+	TimeLimit int                `json:"time_limit" bson:"time_limit"` // 秒
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	StartedAt *time.Time         `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	ExpiresAt *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	EndedAt   *time.Time         `json:"ended_at,omitempty" bson:"ended_at,omitempty"`
+	QuizID    primitive.ObjectID `json:"quiz_id,omitempty" bson:"quiz_id,omitempty"` // 提交后生成的Quiz记录
+}
+
+// SaveAnswerRequest 保存单题作答
+type SaveAnswerRequest struct {
+	QuestionID      primitive.ObjectID `json:"question_id" binding:"required"`
+	UserAnswerIndex []int              `json:"user_answer_index"`
+}
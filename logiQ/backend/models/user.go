@@ -6,6 +6,12 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// 用户角色
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
 // User 用户数据模型 - 对应MongoDB中的users集合
 type User struct {
 	ID                primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`             // MongoDB自动生成的唯一ID
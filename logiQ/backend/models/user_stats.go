@@ -30,6 +30,11 @@ func NewUserStats(userID primitive.ObjectID) *UserStats {
 				{Type: string(QuestionCategoryTruthTable), Value: 0, Count: 0},
 				{Type: string(QuestionCategoryEquivalence), Value: 0, Count: 0},
 				{Type: string(QuestionCategoryInference), Value: 0, Count: 0},
+				{Type: string(QuestionCategoryNormalForm), Value: 0, Count: 0},
+				{Type: string(QuestionCategoryClassification), Value: 0, Count: 0},
+				{Type: string(QuestionCategoryProof), Value: 0, Count: 0},
+				{Type: string(QuestionCategoryResolution), Value: 0, Count: 0},
+				{Type: string(QuestionCategoryTableau), Value: 0, Count: 0},
 			},
 			DataByDifficulty: []ErrorDistributionItem{
 				{Type: string(QuestionDifficultyEasy), Value: 0, Count: 0},
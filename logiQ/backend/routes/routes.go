@@ -3,6 +3,7 @@ package routes
 import (
 	"backend/handlers"
 	"backend/middleware"
+	"backend/models"
 	"backend/services"
 
 	"github.com/gin-gonic/gin"
@@ -31,16 +32,19 @@ func SetupRoutes() *gin.Engine {
 	userStatsService := services.NewUserStatsService()
 	userService := services.NewUserService(verificationService, userStatsService)
 	questionService := services.NewQuestionService()
+	generationJobService := services.NewGenerationJobService(questionService)
 	questionStatsService := services.NewQuestionStatsService()
 	quizService := services.NewQuizService(questionService, userStatsService, questionStatsService)
+	proofService := services.NewProofService(questionService, quizService)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userService, verificationService)
 	userHandler := handlers.NewUserHandler(userService)
 	userStatsHandler := handlers.NewUserStatsHandler(userStatsService)
-	questionHandler := handlers.NewQuestionHandler(questionService)
+	questionHandler := handlers.NewQuestionHandler(questionService, generationJobService)
 	questionStatsHandler := handlers.NewQuestionStatsHandler(questionStatsService)
 	quizHandler := handlers.NewQuizHandler(quizService)
+	proofHandler := handlers.NewProofHandler(proofService)
 
 	// Authentication routes
 	authRoutes := r.Group("/auth")
@@ -76,10 +80,19 @@ func SetupRoutes() *gin.Engine {
 
 	// Question routes
 	questionRoutes := r.Group("/question")
-	questionRoutes.Use(middleware.AuthMiddleware()) //需要认证（通常只有管理员可以创建题目）
+	// 题库管理只对管理员开放
+	questionRoutes.Use(middleware.AuthMiddleware(), middleware.RequireRole(userService, models.UserRoleAdmin))
 	{
-		// 生成题目并加入题库
+		// 提交生成任务，题目在后台生成并加入题库
 		questionRoutes.POST("/generate", questionHandler.GenerateQuestion)
+		// 查询生成任务状态
+		questionRoutes.GET("/jobs/:id", questionHandler.GetGenerationJob)
+		// 取消生成任务
+		questionRoutes.POST("/jobs/:id/cancel", questionHandler.CancelGenerationJob)
+		// 签发订阅生成任务进度的短期token
+		questionRoutes.POST("/jobs/:id/stream-token", questionHandler.CreateGenerationJobStreamToken)
+		// 按 blueprint 重新生成题目
+		questionRoutes.POST("/regenerate", questionHandler.RegenerateQuestion)
 		// 获取单个题目
 		questionRoutes.GET("/question/:id", questionHandler.GetQuestionById)
 		// 获取题目列表 支持分页和按类别过滤
@@ -88,9 +101,13 @@ func SetupRoutes() *gin.Engine {
 		questionRoutes.POST("/delete", questionHandler.BatchDeleteQuestions)
 	}
 
+	// 生成任务进度推送（SSE）：EventSource 不能设置 Authorization 请求头，
+	// 用 /question/jobs/:id/stream-token 签发的短期token（?token=）认证，不经过登录token中间件
+	r.GET("/question/jobs/:id/events", questionHandler.StreamGenerationJob)
+
 	// Question stats routes
 	questionStatsRoutes := r.Group("/question-stats")
-	questionStatsRoutes.Use(middleware.AuthMiddleware())
+	questionStatsRoutes.Use(middleware.AuthMiddleware(), middleware.RequireRole(userService, models.UserRoleAdmin))
 	{
 		questionStatsRoutes.GET("/dimension-distribution", questionStatsHandler.GetDimensionDistribution)
 		questionStatsRoutes.GET("/dimension-accuracy", questionStatsHandler.GetDimensionAccuracy)
@@ -102,10 +119,23 @@ func SetupRoutes() *gin.Engine {
 	quizRoutes.Use(middleware.AuthMiddleware())
 	{
 		quizRoutes.POST("/new", quizHandler.CreateQuiz)
+		// 测验会话：开始计时、逐题保存作答、恢复未完成的测验
+		quizRoutes.POST("/session/:id/start", quizHandler.StartQuiz)
+		quizRoutes.POST("/session/:id/answer", quizHandler.SaveAnswer)
+		quizRoutes.GET("/session/resume", quizHandler.ResumeQuiz)
 		quizRoutes.GET("/:id", quizHandler.GetQuiz)
 		quizRoutes.POST("/submit", quizHandler.SubmitQuiz)
 		quizRoutes.GET("/history", quizHandler.GetUserQuizHistory)
 	}
 
+	// Proof routes
+	proofRoutes := r.Group("/proof")
+	proofRoutes.Use(middleware.AuthMiddleware())
+	{
+		// 以推理题为基础的证明练习：获取前提和目标，提交后逐行检查
+		proofRoutes.GET("/exercise", proofHandler.GetExercise)
+		proofRoutes.POST("/check", proofHandler.CheckProof)
+	}
+
 	return r
 }
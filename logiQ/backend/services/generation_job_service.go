@@ -0,0 +1,363 @@
+package services
+
+import (
+	"backend/generation/render"
+	gengerationService "backend/generation/service"
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// generationJobWorkers 同时执行的生成任务数
+	generationJobWorkers = 2
+	// generationJobQueueSize 排队中的任务上限，超过则拒绝新任务
+	generationJobQueueSize = 32
+	// generationBatchSize 每生成多少道题入库一次
+	generationBatchSize = 20
+	// generationJobRetention 任务结束后在内存中保留多久，供查询结果
+	generationJobRetention = time.Hour
+	// subscriberBuffer 每个订阅者的事件缓冲，消费过慢时丢弃中间的进度事件
+	subscriberBuffer = 64
+)
+
+var (
+	ErrGenerationJobNotFound  = errors.New("generation job not found")
+	ErrGenerationJobQueueFull = errors.New("too many generation jobs queued, try again later")
+	ErrGenerationJobFinished  = errors.New("generation job has already finished")
+)
+
+// generationJobEntry 内存中的任务及其订阅者，字段由 GenerationJobService.mu 保护
+// ctx 在任务被取消或结束时取消，生成过程随之停止
+type generationJobEntry struct {
+	job         models.GenerationJob
+	subscribers map[chan models.GenerationJobEvent]struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// GenerationJobService 管理后台题目生成任务
+// 任务只保存在内存中：服务重启后未完成的任务会丢失，但已入库的题目不受影响
+type GenerationJobService struct {
+	questionService *QuestionService
+	generator       gengerationService.Service
+	queue           chan primitive.ObjectID
+
+	mu   sync.RWMutex
+	jobs map[primitive.ObjectID]*generationJobEntry
+}
+
+func NewGenerationJobService(questionService *QuestionService) *GenerationJobService {
+	s := &GenerationJobService{
+		questionService: questionService,
+		generator:       gengerationService.NewService(),
+		queue:           make(chan primitive.ObjectID, generationJobQueueSize),
+		jobs:            make(map[primitive.ObjectID]*generationJobEntry),
+	}
+	for i := 0; i < generationJobWorkers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+// Enqueue 创建生成任务并放入队列，立即返回任务快照
+func (s *GenerationJobService) Enqueue(userID primitive.ObjectID, req models.GenerateQuestionRequest) (models.GenerationJob, error) {
+	if req.Number <= 0 {
+		req.Number = 1
+	}
+	job := models.GenerationJob{
+		ID:        primitive.NewObjectID(),
+		CreatedBy: userID,
+		Request:   req,
+		Status:    models.GenerationJobStatusQueued,
+		Total:     req.Number,
+		CreatedAt: time.Now(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.pruneLocked()
+	s.jobs[job.ID] = &generationJobEntry{
+		job:         job,
+		subscribers: make(map[chan models.GenerationJobEvent]struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.queue <- job.ID:
+		return job, nil
+	default:
+		cancel()
+		s.mu.Lock()
+		delete(s.jobs, job.ID)
+		s.mu.Unlock()
+		return models.GenerationJob{}, ErrGenerationJobQueueFull
+	}
+}
+
+// Cancel 取消排队中或执行中的任务，返回任务快照
+// 排队中的任务立即结束；执行中的任务停止生成，已入库的题目保留，任务在生成中止后结束
+func (s *GenerationJobService) Cancel(jobID primitive.ObjectID) (models.GenerationJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[jobID]
+	if !ok {
+		return models.GenerationJob{}, fmt.Errorf("%w: %s", ErrGenerationJobNotFound, jobID.Hex())
+	}
+	if isJobFinished(entry.job.Status) {
+		return models.GenerationJob{}, fmt.Errorf("%w: %s", ErrGenerationJobFinished, jobID.Hex())
+	}
+	if entry.job.Status == models.GenerationJobStatusQueued {
+		// 排队中的任务在这里结束，worker 取到时跳过（见 run）
+		s.finishLocked(entry, context.Canceled)
+	} else {
+		entry.cancel()
+	}
+	return jobSnapshot(entry.job), nil
+}
+
+// GetJob 获取任务详情，已结束的任务包含入库的题目
+func (s *GenerationJobService) GetJob(jobID primitive.ObjectID) (models.GenerationJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.jobs[jobID]
+	if !ok {
+		return models.GenerationJob{}, fmt.Errorf("%w: %s", ErrGenerationJobNotFound, jobID.Hex())
+	}
+	job := entry.job
+	job.Questions = append([]models.Question(nil), entry.job.Questions...)
+	return job, nil
+}
+
+// Subscribe 订阅任务进度，返回当前快照和事件通道
+// 任务结束后通道会被关闭；调用方不再需要时必须调用 unsubscribe
+func (s *GenerationJobService) Subscribe(jobID primitive.ObjectID) (models.GenerationJob, <-chan models.GenerationJobEvent, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[jobID]
+	if !ok {
+		return models.GenerationJob{}, nil, nil, fmt.Errorf("%w: %s", ErrGenerationJobNotFound, jobID.Hex())
+	}
+
+	ch := make(chan models.GenerationJobEvent, subscriberBuffer)
+	if isJobFinished(entry.job.Status) {
+		// 已结束的任务直接返回关闭的通道
+		close(ch)
+		return jobSnapshot(entry.job), ch, func() {}, nil
+	}
+	entry.subscribers[ch] = struct{}{}
+
+	unsubscribe := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := entry.subscribers[ch]; ok {
+			delete(entry.subscribers, ch)
+			close(ch)
+		}
+	}
+	return jobSnapshot(entry.job), ch, unsubscribe, nil
+}
+
+func (s *GenerationJobService) worker() {
+	for jobID := range s.queue {
+		s.run(jobID)
+	}
+}
+
+// run 分批生成并入库，每批结束后推送入库事件；任务被取消时停止生成
+func (s *GenerationJobService) run(jobID primitive.ObjectID) {
+	s.mu.Lock()
+	entry, ok := s.jobs[jobID]
+	// 排队期间被取消的任务已经结束
+	if !ok || isJobFinished(entry.job.Status) {
+		s.mu.Unlock()
+		return
+	}
+	ctx := entry.ctx
+	req := entry.job.Request
+	s.updateLocked(entry, models.GenerationJobEventProgress, "", func(job *models.GenerationJob) {
+		now := time.Now()
+		job.Status = models.GenerationJobStatusRunning
+		job.StartedAt = &now
+	})
+	s.mu.Unlock()
+
+	// 防止单个任务的异常拖垮 worker
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("generation job %s panicked: %v", jobID.Hex(), r)
+			s.finish(jobID, fmt.Errorf("internal error: %v", r))
+		}
+	}()
+
+	done := 0
+	for done < req.Number {
+		if err := ctx.Err(); err != nil {
+			s.finish(jobID, err)
+			return
+		}
+		batch := min(generationBatchSize, req.Number-done)
+		base := done
+		questions, err := s.generator.GenerateBatch(ctx, gengerationService.BatchRequest{
+			Num:        batch,
+			Category:   req.Category,
+			Difficulty: req.Difficulty,
+			QType:      req.Type,
+			Renderer:   render.Renderer{Notation: render.Notation(req.Notation), MinimalParens: req.MinimalParens},
+			OnProgress: func(p gengerationService.Progress) {
+				if errors.Is(p.Err, gengerationService.ErrDuplicate) {
+					s.update(jobID, models.GenerationJobEventDuplicate, "", func(job *models.GenerationJob) {
+						job.Duplicates++
+					})
+					return
+				}
+				if p.Err != nil {
+					s.update(jobID, models.GenerationJobEventAttemptFailed, p.Err.Error(), func(job *models.GenerationJob) {
+						job.FailedAttempts++
+					})
+					return
+				}
+				s.update(jobID, models.GenerationJobEventProgress, "", func(job *models.GenerationJob) {
+					job.Generated = base + p.Generated
+				})
+			},
+			Exclude: s.inBank,
+		})
+		if err != nil {
+			s.finish(jobID, err)
+			return
+		}
+
+		inserted, err := s.questionService.InsertQuestions(questions)
+		if err != nil {
+			s.finish(jobID, fmt.Errorf("insert questions: %w", err))
+			return
+		}
+		// 同一批内互相重复的题目被唯一索引拒绝，缺的题目由下一批补上
+		if len(inserted) == 0 {
+			s.finish(jobID, errors.New("insert questions: every generated question duplicates one already in the bank"))
+			return
+		}
+		done += len(inserted)
+		s.update(jobID, models.GenerationJobEventInserted, fmt.Sprintf("inserted %d questions", len(inserted)), func(job *models.GenerationJob) {
+			job.Inserted += len(inserted)
+			job.Duplicates += len(questions) - len(inserted)
+			job.Questions = append(job.Questions, inserted...)
+		})
+	}
+
+	s.finish(jobID, nil)
+}
+
+// inBank 判断题库中是否已有指纹相同的题目；查询失败时放行，由唯一索引兜底
+func (s *GenerationJobService) inBank(fingerprint string) bool {
+	exists, err := s.questionService.FingerprintExists(context.Background(), fingerprint)
+	if err != nil {
+		log.Printf("check question fingerprint %s: %v", fingerprint, err)
+		return false
+	}
+	return exists
+}
+
+// finish 标记任务结束并关闭所有订阅通道
+func (s *GenerationJobService) finish(jobID primitive.ObjectID, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.jobs[jobID]; ok {
+		s.finishLocked(entry, err)
+	}
+}
+
+// finishLocked 标记任务结束并关闭所有订阅通道，调用方需持有写锁
+// err 为 context.Canceled 时任务记为已取消；已结束的任务不再修改
+func (s *GenerationJobService) finishLocked(entry *generationJobEntry, err error) {
+	if isJobFinished(entry.job.Status) {
+		return
+	}
+	eventType := models.GenerationJobEventCompleted
+	message := ""
+	switch {
+	case errors.Is(err, context.Canceled):
+		eventType = models.GenerationJobEventCancelled
+		message = "cancelled"
+	case err != nil:
+		eventType = models.GenerationJobEventFailed
+		message = err.Error()
+	}
+	s.updateLocked(entry, eventType, message, func(job *models.GenerationJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		switch {
+		case errors.Is(err, context.Canceled):
+			job.Status = models.GenerationJobStatusCancelled
+		case err != nil:
+			job.Status = models.GenerationJobStatusFailed
+			job.Error = err.Error()
+		default:
+			job.Status = models.GenerationJobStatusCompleted
+		}
+	})
+
+	entry.cancel()
+	for ch := range entry.subscribers {
+		close(ch)
+	}
+	entry.subscribers = make(map[chan models.GenerationJobEvent]struct{})
+}
+
+// update 修改任务并向订阅者广播事件
+func (s *GenerationJobService) update(jobID primitive.ObjectID, eventType models.GenerationJobEventType, message string, mutate func(job *models.GenerationJob)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[jobID]
+	if !ok {
+		return
+	}
+	s.updateLocked(entry, eventType, message, mutate)
+}
+
+// updateLocked 修改任务并向订阅者广播事件，调用方需持有写锁
+func (s *GenerationJobService) updateLocked(entry *generationJobEntry, eventType models.GenerationJobEventType, message string, mutate func(job *models.GenerationJob)) {
+	mutate(&entry.job)
+
+	event := models.GenerationJobEvent{Type: eventType, Message: message, Job: jobSnapshot(entry.job)}
+	for ch := range entry.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 订阅者消费过慢，丢弃本次事件；通道关闭后订阅者可通过 GetJob 获取最终状态
+		}
+	}
+}
+
+// pruneLocked 清理过期的已结束任务，调用方需持有写锁
+func (s *GenerationJobService) pruneLocked() {
+	for id, entry := range s.jobs {
+		if entry.job.FinishedAt != nil && time.Since(*entry.job.FinishedAt) > generationJobRetention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// jobSnapshot 返回不含题目的任务副本，用于进度推送
+func jobSnapshot(job models.GenerationJob) models.GenerationJob {
+	job.Questions = nil
+	return job
+}
+
+func isJobFinished(status models.GenerationJobStatus) bool {
+	return status == models.GenerationJobStatusCompleted || status == models.GenerationJobStatusFailed ||
+		status == models.GenerationJobStatusCancelled
+}
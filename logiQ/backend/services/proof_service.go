@@ -0,0 +1,169 @@
+package services
+
+import (
+	"backend/generation/core"
+	"backend/generation/helper"
+	"backend/generation/parser"
+	"backend/generation/proof"
+	gengerationService "backend/generation/service"
+	"backend/models"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrNotProofExercise   = errors.New("question cannot be used as a proof exercise")
+	ErrNoProofExercise    = errors.New("no inference question available for a proof exercise")
+	ErrInvalidProofTarget = errors.New("target is not a conclusion of this exercise")
+	ErrQuestionInOpenQuiz = errors.New("question is part of an unfinished quiz")
+)
+
+type ProofService struct {
+	questionService *QuestionService
+	quizService     *QuizService
+	generator       gengerationService.Service
+}
+
+func NewProofService(questionService *QuestionService, quizService *QuizService) *ProofService {
+	return &ProofService{
+		questionService: questionService,
+		quizService:     quizService,
+		generator:       gengerationService.NewService(),
+	}
+}
+
+// GetExercise 返回推理题对应的证明练习；questionID 为空时按难度随机抽一道推理题
+// 练习的结论是推理题的正确选项之一，所以用户未完成的测验中的题目和已停用的题目都不能作为练习
+func (s *ProofService) GetExercise(userID, questionID primitive.ObjectID, difficulty models.QuestionDifficulty) (*models.ProofExerciseResponse, error) {
+	var question *models.Question
+	if questionID.IsZero() {
+		open, err := s.quizService.OpenSessionQuestionIDs(userID)
+		if err != nil {
+			return nil, err
+		}
+		exclude := make([]primitive.ObjectID, 0, len(open))
+		for id := range open {
+			exclude = append(exclude, id)
+		}
+		questions, err := s.questionService.GetRandomQuestionsExcept(models.QuestionCategoryInference, difficulty, 1, exclude)
+		if err != nil {
+			return nil, err
+		}
+		if len(questions) == 0 {
+			return nil, ErrNoProofExercise
+		}
+		question = &questions[0]
+	} else {
+		q, err := s.usableQuestion(userID, questionID)
+		if err != nil {
+			return nil, err
+		}
+		question = q
+	}
+
+	exercise, err := s.exercise(question)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]string, 0, len(proof.Rules)+1)
+	rules = append(rules, string(proof.Premise))
+	for _, rule := range proof.Rules {
+		rules = append(rules, string(rule))
+	}
+	return &models.ProofExerciseResponse{
+		QuestionID:    question.ID,
+		Difficulty:    question.Difficulty,
+		Premises:      stringifyAll(exercise.Premises),
+		Target:        helper.Stringify(exercise.Target),
+		Rules:         rules,
+		ConfigChanged: exercise.ConfigChanged,
+	}, nil
+}
+
+// CheckProof 逐行检查学生的证明，返回每一行的错误；格式错误的行同样在对应行报告
+func (s *ProofService) CheckProof(userID primitive.ObjectID, req *models.CheckProofRequest) (*models.CheckProofResponse, error) {
+	question, err := s.usableQuestion(userID, req.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	exercise, err := s.exercise(question)
+	if err != nil {
+		return nil, err
+	}
+
+	// 目标必须是练习的结论，按结构比较
+	target, err := parser.Parse(req.Target)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProofTarget, err)
+	}
+	if helper.Stringify(target) != helper.Stringify(exercise.Target) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidProofTarget, req.Target)
+	}
+
+	results := make([]models.ProofLineResult, len(req.Lines))
+	lines := make([]proof.Line, len(req.Lines))
+	for i, input := range req.Lines {
+		line, err := proof.ParseLine(i+1, input.Formula, input.Justification)
+		lines[i] = line
+		results[i] = models.ProofLineResult{Line: i + 1, Valid: err == nil}
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+	p := proof.Proof{Lines: lines}
+	for _, checkErr := range p.CheckAll(exercise.Premises) {
+		results[checkErr.Line-1].Valid = false
+		results[checkErr.Line-1].Error = checkErr.Reason
+	}
+
+	resp := &models.CheckProofResponse{Complete: true, Lines: results}
+	for _, r := range results {
+		resp.Complete = resp.Complete && r.Valid
+	}
+	if resp.Complete && helper.Stringify(p.Conclusion()) != helper.Stringify(target) {
+		resp.Complete = false
+		resp.Error = fmt.Sprintf("the last line is %s, not the target %s", helper.Stringify(p.Conclusion()), helper.Stringify(target))
+	}
+	return resp, nil
+}
+
+// usableQuestion 取出指定的题目，拒绝已停用的题目和用户未完成的测验中的题目
+func (s *ProofService) usableQuestion(userID, questionID primitive.ObjectID) (*models.Question, error) {
+	question, err := s.questionService.GetQuestionByID(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if !question.IsActive {
+		return nil, fmt.Errorf("%w: %s", ErrQuestionInactive, questionID.Hex())
+	}
+	open, err := s.quizService.OpenSessionQuestionIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if open[questionID] {
+		return nil, fmt.Errorf("%w: %s", ErrQuestionInOpenQuiz, questionID.Hex())
+	}
+	return question, nil
+}
+
+// exercise 按题目的 blueprint 取出证明练习
+func (s *ProofService) exercise(question *models.Question) (gengerationService.ProofExercise, error) {
+	if question.Blueprint == nil {
+		return gengerationService.ProofExercise{}, fmt.Errorf("%w: %s", ErrQuestionNoBlueprint, question.ID.Hex())
+	}
+	exercise, err := s.generator.ProofExercise(*question.Blueprint)
+	if errors.Is(err, gengerationService.ErrNotProofExercise) {
+		return gengerationService.ProofExercise{}, fmt.Errorf("%w: %s is a %s question", ErrNotProofExercise, question.ID.Hex(), question.Category)
+	}
+	return exercise, err
+}
+
+func stringifyAll(nodes []*core.Node) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = helper.Stringify(n)
+	}
+	return out
+}
@@ -5,11 +5,21 @@ import (
 	gengerationService "backend/generation/service"
 	"backend/models"
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrQuestionNotFound    = errors.New("question not found")
+	ErrQuestionInactive    = errors.New("question is no longer active")
+	ErrQuestionNoBlueprint = errors.New("question has no generation blueprint")
+	ErrQuestionDuplicate   = errors.New("an equivalent question is already in the bank")
 )
 
 type QuestionService struct {
@@ -22,34 +32,139 @@ func NewQuestionService() *QuestionService {
 	}
 }
 
-// GenerateQuestion 从外部API生成题目并存入数据库，返回插入的题目数量
-func (s *QuestionService) GenerateQuestion(req *models.GenerateQuestionRequest) ([]models.Question, error) {
-	if req.Number == 0 {
-		req.Number = 1
+// InsertQuestions 批量插入题目，返回带有ID的题目列表
+// 指纹与题库中已有题目（或同一批中靠前的题目）相同的题目被唯一索引拒绝，不会出现在返回结果中
+func (s *QuestionService) InsertQuestions(questionList []models.Question) ([]models.Question, error) {
+	if len(questionList) == 0 {
+		return nil, nil
 	}
-	genService := gengerationService.NewService()
-	questionList, err := genService.GenerateQuestion(req.Number, req.Category, req.Difficulty, req.Type)
-	if err != nil {
-		return nil, err
-	}
-	// 批量插入题目
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	docs := make([]interface{}, len(questionList))
+	for i := range questionList {
+		if questionList[i].ID.IsZero() {
+			questionList[i].ID = primitive.NewObjectID()
+		}
+		docs[i] = questionList[i]
+	}
+	// 无序插入：重复的题目失败后继续插入其余题目
+	_, err := s.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	rejected := make(map[int]bool)
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+			return nil, err
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr) {
+				return nil, err
+			}
+			rejected[writeErr.Index] = true
+		}
+	}
+	inserted := make([]models.Question, 0, len(questionList))
 	for i, q := range questionList {
-		docs[i] = q
+		if !rejected[i] {
+			inserted = append(inserted, q)
+		}
+	}
+	return inserted, nil
+}
+
+// FingerprintExists 判断题库中是否已有指纹相同的有效题目；已停用的题目不算重复
+func (s *QuestionService) FingerprintExists(ctx context.Context, fingerprint string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err := s.collection.FindOne(ctx, bson.M{"fingerprint": fingerprint, "is_active": true}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
 	}
-	_, err = s.collection.InsertMany(ctx, docs)
+	return err == nil, err
+}
+
+// RegenerateQuestion 按题目保存的 blueprint 重新生成题目
+// 返回重新生成的题目，以及生成时的配置是否与当前配置不同；replace 为 true 时覆盖题库中的原题
+func (s *QuestionService) RegenerateQuestion(req *models.RegenerateQuestionRequest) (*models.Question, bool, error) {
+	question, err := s.GetQuestionByID(req.QuestionID)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return questionList, nil
+	if question.Blueprint == nil {
+		return nil, false, fmt.Errorf("%w: %s", ErrQuestionNoBlueprint, req.QuestionID.Hex())
+	}
+
+	genService := gengerationService.NewService()
+	regenerated, err := genService.Regenerate(*question.Blueprint)
+	if err != nil {
+		return nil, false, err
+	}
+	regenerated.ID = question.ID
+	regenerated.IsActive = question.IsActive
+	configChanged := question.Blueprint.ConfigVersion != genService.ConfigVersion()
+
+	if req.Replace {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		update := bson.M{"$set": bson.M{
+			"question_text":        regenerated.QuestionText,
+			"options":              regenerated.Options,
+			"correct_answer_index": regenerated.CorrectAnswerIndex,
+			"explanation":          regenerated.Explanation,
+			"option_explanations":  regenerated.OptionExplanations,
+			"blueprint":            regenerated.Blueprint,
+			"formulas":             regenerated.Formulas,
+			"fingerprint":          regenerated.Fingerprint,
+		}}
+		if _, err := s.collection.UpdateByID(ctx, question.ID, update); err != nil {
+			// 配置变化后重新生成的题目可能与题库中的另一道题相同
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, false, fmt.Errorf("%w: %s", ErrQuestionDuplicate, req.QuestionID.Hex())
+			}
+			return nil, false, err
+		}
+	}
+
+	return &regenerated, configChanged, nil
 }
 
 // GetQuestionByID 根据ID获取题目
 func (s *QuestionService) GetQuestionByID(questionID primitive.ObjectID) (*models.Question, error) {
-	// TODO: 根据ID获取题目
-	return nil, nil
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var question models.Question
+	err := s.collection.FindOne(ctx, bson.M{"_id": questionID}).Decode(&question)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, errors.New("Database query error")
+	}
+
+	return &question, nil
+}
+
+// GetQuestionsByIDs 根据ID批量获取题目，返回以ID为键的map，不存在的ID不会出现在结果中
+func (s *QuestionService) GetQuestionsByIDs(questionIDs []primitive.ObjectID) (map[primitive.ObjectID]models.Question, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{"_id": bson.M{"$in": questionIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var questions []models.Question
+	if err = cursor.All(ctx, &questions); err != nil {
+		return nil, err
+	}
+
+	result := make(map[primitive.ObjectID]models.Question, len(questions))
+	for _, question := range questions {
+		result[question.ID] = question
+	}
+	return result, nil
 }
 
 // GetQuestionList 获取题目列表，支持分页和筛选
@@ -136,6 +251,11 @@ func (s *QuestionService) GetQuestionList(category models.QuestionCategory, diff
 
 // GetRandomQuestions 根据category difficulty获取10个题目，用来创建quiz
 func (s *QuestionService) GetRandomQuestions(category models.QuestionCategory, difficulty models.QuestionDifficulty, count int) ([]models.Question, error) {
+	return s.GetRandomQuestionsExcept(category, difficulty, count, nil)
+}
+
+// GetRandomQuestionsExcept 与 GetRandomQuestions 相同，但不会抽到 exclude 中的题目
+func (s *QuestionService) GetRandomQuestionsExcept(category models.QuestionCategory, difficulty models.QuestionDifficulty, count int, exclude []primitive.ObjectID) ([]models.Question, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -155,6 +275,10 @@ func (s *QuestionService) GetRandomQuestions(category models.QuestionCategory, d
 		filter["difficulty"] = string(difficulty)
 	}
 
+	if len(exclude) > 0 {
+		filter["_id"] = bson.M{"$nin": exclude}
+	}
+
 	// 使用MongoDB的$sample进行随机抽样
 	pipeline := []bson.M{
 		{"$match": filter},
@@ -175,6 +299,48 @@ func (s *QuestionService) GetRandomQuestions(category models.QuestionCategory, d
 	return questions, nil
 }
 
+// ForEachActiveQuestion 按 _id 顺序遍历所有有效题目，fn 返回错误时停止遍历
+func (s *QuestionService) ForEachActiveQuestion(ctx context.Context, fn func(models.Question) error) error {
+	return s.forEachQuestion(ctx, bson.M{"is_active": true}, fn)
+}
+
+// ForEachUnfingerprintedQuestion 按 _id 顺序遍历没有指纹的题目（包括已停用的题目），
+// 即加入指纹之前生成的题目和手工录入的题目
+func (s *QuestionService) ForEachUnfingerprintedQuestion(ctx context.Context, fn func(models.Question) error) error {
+	return s.forEachQuestion(ctx, bson.M{"fingerprint": bson.M{"$exists": false}}, fn)
+}
+
+// SetFingerprint 补写题目的指纹；题库中已有指纹相同的有效题目时返回 ErrQuestionDuplicate
+func (s *QuestionService) SetFingerprint(ctx context.Context, questionID primitive.ObjectID, fingerprint string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	_, err := s.collection.UpdateByID(ctx, questionID, bson.M{"$set": bson.M{"fingerprint": fingerprint}})
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %s", ErrQuestionDuplicate, questionID.Hex())
+	}
+	return err
+}
+
+func (s *QuestionService) forEachQuestion(ctx context.Context, filter bson.M, fn func(models.Question) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var question models.Question
+		if err := cursor.Decode(&question); err != nil {
+			return err
+		}
+		if err := fn(question); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
 // SoftDeleteQuestionsByIDs 软删除题目
 func (s *QuestionService) SoftDeleteQuestionsByIDs(questionIDs []primitive.ObjectID) (int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -56,7 +56,7 @@ func (s *QuestionStatsService) CalcQuestionDistributions() (*models.QuestionDist
 	// 使用 $facet 聚合管道，同时统计难度、类型、分类和总数
 	pipeline := mongo.Pipeline{
 		{
-			{"$facet", bson.M{
+			{Key: "$facet", Value: bson.M{
 				"difficulty": []bson.M{
 					// 按难度分组并统计数量
 					{"$group": bson.M{"_id": "$difficulty", "count": bson.M{"$sum": 1}}},
@@ -6,11 +6,25 @@ import (
 	"backend/utils"
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// quizTimeLimit 每场测验的作答时限，从开始作答算起，由服务端计时
+const quizTimeLimit = 30 * time.Minute
+
+var (
+	ErrDuplicateAnswer      = errors.New("question answered more than once")
+	ErrQuizSessionNotFound  = errors.New("quiz session not found")
+	ErrQuizSessionNotActive = errors.New("quiz session is not in progress")
+	ErrQuizSessionExpired   = errors.New("quiz session has expired")
+	ErrNoUnfinishedQuiz     = errors.New("no unfinished quiz session")
+	ErrQuestionNotInSession = errors.New("question does not belong to this quiz session")
 )
 
 type QuizService struct {
@@ -18,7 +32,7 @@ type QuizService struct {
 	userStatsService     *UserStatsService
 	questionStatsService *QuestionStatsService
 	collection           *mongo.Collection
-	//pendingCollection *mongo.Collection
+	sessionCollection    *mongo.Collection
 }
 
 func NewQuizService(questionService *QuestionService, userStatsService *UserStatsService, questionStatsService *QuestionStatsService) *QuizService {
@@ -27,18 +41,23 @@ func NewQuizService(questionService *QuestionService, userStatsService *UserStat
 		userStatsService:     userStatsService,
 		questionStatsService: questionStatsService,
 		collection:           database.GetCollection(database.QuizzesCollection),
+		sessionCollection:    database.GetCollection(database.QuizSessionsCollection),
 	}
 }
 
-func (s *QuizService) CreateQuiz(userID primitive.ObjectID, req *models.CreateQuizRequest) (*models.Quiz, error) {
-	//ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	//defer cancel()
+// CreateQuiz 抽取题目并创建一个 created 状态的测验会话
+func (s *QuizService) CreateQuiz(userID primitive.ObjectID, req *models.CreateQuizRequest) (*models.QuizSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
 	// 获取随机题目列表
 	questionList, err := s.questionService.GetRandomQuestions(req.Category, req.Difficulty, 10)
 	if err != nil {
 		return nil, err
 	}
+	if len(questionList) == 0 {
+		return nil, errors.New("no questions available for this quiz")
+	}
 	// 将题目列表转换为QuizQuestion类型
 	quizQuestions := make([]models.QuizQuestion, len(questionList))
 	for i, question := range questionList {
@@ -47,74 +66,427 @@ func (s *QuizService) CreateQuiz(userID primitive.ObjectID, req *models.CreateQu
 			UserAnswerIndex: []int{}, // 初始化用户答案为空
 		}
 	}
-	// 创建Quiz对象
-	quiz := models.Quiz{
+	// 创建会话对象
+	session := models.QuizSession{
 		UserID:    userID,
 		Type:      req.Type,
+		Status:    models.QuizSessionStatusCreated,
 		Questions: quizQuestions,
+		TimeLimit: int(quizTimeLimit.Seconds()),
+		CreatedAt: time.Now(),
+	}
+	// 插入会话到数据库
+	result, err := s.sessionCollection.InsertOne(ctx, session)
+	if err != nil {
+		return nil, err
 	}
-	//// 插入Quiz到数据库
-	//result, err := s.pendingCollection.InsertOne(ctx, quiz)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//
-	//// 设置生成的ID
-	//quiz.ID = result.InsertedID.(primitive.ObjectID)
+	// 设置生成的ID
+	session.ID = result.InsertedID.(primitive.ObjectID)
 
-	return &quiz, nil
+	return redactSession(&session), nil
+}
+
+// StartQuiz 开始作答：created → in_progress，记录开始时间和截止时间
+// 对已开始且未过期的会话重复调用会直接返回该会话
+func (s *QuizService) StartQuiz(userID, sessionID primitive.ObjectID) (*models.QuizSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	expiresAt := now.Add(quizTimeLimit)
+	filter := bson.M{"_id": sessionID, "user_id": userID, "status": models.QuizSessionStatusCreated}
+	update := bson.M{"$set": bson.M{
+		"status":     models.QuizSessionStatusInProgress,
+		"started_at": now,
+		"expires_at": expiresAt,
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var session models.QuizSession
+	err := s.sessionCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&session)
+	if err == nil {
+		return redactSession(&session), nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	// 会话不是 created 状态：若已在进行中则视为幂等调用
+	active, err := s.getActiveSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return redactSession(active), nil
+}
+
+// SaveAnswer 保存进行中会话的单题作答
+func (s *QuizService) SaveAnswer(userID, sessionID primitive.ObjectID, req *models.SaveAnswerRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.getActiveSession(userID, sessionID); err != nil {
+		return err
+	}
+
+	answer := req.UserAnswerIndex
+	if answer == nil {
+		answer = []int{}
+	}
+	filter := bson.M{
+		"_id":                    sessionID,
+		"user_id":                userID,
+		"status":                 models.QuizSessionStatusInProgress,
+		"questions.question._id": req.QuestionID,
+	}
+	update := bson.M{"$set": bson.M{"questions.$.user_answer_index": answer}}
+	result, err := s.sessionCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrQuestionNotInSession, req.QuestionID.Hex())
+	}
+	return nil
+}
+
+// ResumeQuiz 返回用户最近一个未完成（created 或 in_progress 且未过期）的会话
+func (s *QuizService) ResumeQuiz(userID primitive.ObjectID) (*models.QuizSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 先结束已超时的会话（按保存的作答判分），避免恢复到过期会话
+	if err := s.expireOverdueSessions(ctx, bson.M{"user_id": userID}); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{
+		"user_id": userID,
+		"status": bson.M{"$in": []models.QuizSessionStatus{
+			models.QuizSessionStatusCreated,
+			models.QuizSessionStatusInProgress,
+		}},
+	}
+	opts := options.FindOne().SetSort(bson.M{"created_at": -1})
+
+	var session models.QuizSession
+	err := s.sessionCollection.FindOne(ctx, filter, opts).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNoUnfinishedQuiz
+		}
+		return nil, err
+	}
+	return redactSession(&session), nil
 }
 
+// SubmitQuiz 提交会话：合并最后的作答、服务端判分、计算用时并写入Quiz记录
 func (s *QuizService) SubmitQuiz(userID primitive.ObjectID, req *models.SubmitQuizRequest) (*models.Quiz, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// 1. 设置quiz完成时间
-	completedAt := time.Now()
-	// 2. 计算正确答案数量和更新题目统计
+	// 1. 获取进行中的会话
+	session, err := s.getActiveSession(userID, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	// 2. 合并请求中携带的作答
+	answers, err := mergeSessionAnswers(session, req.Answers)
+	if err != nil {
+		return nil, err
+	}
+	// 3. 从题库查询标准题目，拒绝不存在或已停用的题目
+	questionMap, err := s.loadGradableQuestions(answers)
+	if err != nil {
+		return nil, err
+	}
+	// 4. 结束会话、判分并写入Quiz记录；完成时间和用时均以服务端为准
+	return s.finishSession(ctx, session, answers, questionMap, models.QuizSessionStatusSubmitted, time.Now())
+}
+
+// finishSession 结束进行中的会话：抢占会话状态防止重复结束，按 questionMap 中的标准答案判分，写入Quiz记录并关联到会话，
+// 最后更新统计信息。写入或关联失败时删除已写入的Quiz记录，把会话恢复为 in_progress 并返回错误
+func (s *QuizService) finishSession(ctx context.Context, session *models.QuizSession, answers []models.QuizAnswer,
+	questionMap map[primitive.ObjectID]models.Question, status models.QuizSessionStatus, endedAt time.Time) (*models.Quiz, error) {
+	// 1. 抢占会话状态，防止重复提交或重复判分
+	claim, err := s.sessionCollection.UpdateOne(ctx,
+		bson.M{"_id": session.ID, "status": models.QuizSessionStatusInProgress},
+		bson.M{"$set": bson.M{"status": status, "ended_at": endedAt}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claim.ModifiedCount == 0 {
+		return nil, ErrQuizSessionNotActive
+	}
+	completionTime := int(endedAt.Sub(*session.StartedAt).Seconds())
+	if completionTime > session.TimeLimit {
+		completionTime = session.TimeLimit
+	}
+
+	// 2. 服务端判分
 	correctCount := 0
-	for index, question := range req.Questions {
-		isCorrect := utils.AreSlicesEqual(question.UserAnswerIndex, question.Question.CorrectAnswerIndex)
+	quizQuestions := make([]models.QuizQuestion, 0, len(answers))
+	for _, answer := range answers {
+		question := questionMap[answer.QuestionID]
+		isCorrect := utils.AreSlicesEqual(answer.UserAnswerIndex, question.CorrectAnswerIndex)
 		if isCorrect {
 			correctCount++
-			question.IsCorrect = true
-		} else {
-			question.IsCorrect = false
 		}
-		req.Questions[index] = question // 更新问题状态
-
-		// 更新单题统计信息
-		go s.questionStatsService.UpdateStats(question.Question.ID, isCorrect)
+		quizQuestions = append(quizQuestions, models.QuizQuestion{
+			Question:        &question,
+			UserAnswerIndex: answer.UserAnswerIndex,
+			IsCorrect:       isCorrect,
+		})
 	}
 	// 3. 创建Quiz记录
 	quiz := models.Quiz{
-		UserID:              userID,
-		Type:                req.Type,
-		Questions:           req.Questions,
+		UserID:              session.UserID,
+		SessionID:           session.ID,
+		Type:                session.Type,
+		Questions:           quizQuestions,
 		CorrectQuestionsNum: correctCount,
-		CompletionTime:      req.CompletionTime,
-		CompletedAt:         completedAt,
+		CompletionTime:      completionTime,
+		CompletedAt:         endedAt,
+		Expired:             status == models.QuizSessionStatusExpired,
 	}
 	// 4. 保存到数据库
 	result, err := s.collection.InsertOne(ctx, quiz)
 	if err != nil {
-		return nil, err
+		return nil, s.rollbackFinish(ctx, session.ID, status, nil, err)
 	}
-	// 5. 设置生成的ID
+	// 5. 设置生成的ID，并关联到会话
 	quiz.ID = result.InsertedID.(primitive.ObjectID)
+	_, err = s.sessionCollection.UpdateOne(ctx, bson.M{"_id": session.ID}, bson.M{"$set": bson.M{"quiz_id": quiz.ID}})
+	if err != nil {
+		return nil, s.rollbackFinish(ctx, session.ID, status, &quiz.ID, err)
+	}
 
-	// 6. 更新用户统计信息
-	err = s.userStatsService.UpdateUserStats(userID, &quiz, s)
+	// 6. 会话已结束，更新单题统计和用户统计信息；放在最后，撤销时不会留下多计的统计
+	for _, quizQuestion := range quizQuestions {
+		go s.questionStatsService.UpdateStats(quizQuestion.Question.ID, quizQuestion.IsCorrect)
+	}
+	err = s.userStatsService.UpdateUserStats(session.UserID, &quiz, s)
 	if err != nil {
 		// 统计更新失败，记录错误但不影响quiz提交成功
 		// 可以考虑添加日志记录
 		// log.Printf("Failed to update user stats: %v", err)
 	}
-
-	// 7. 返回结果
 	return &quiz, nil
 }
 
+// rollbackFinish 撤销 finishSession 的部分写入：删除已写入的Quiz记录（quizID 不为空时），把会话恢复为 in_progress，
+// 返回导致撤销的错误和撤销中出现的错误。ctx 可能已经超时，撤销使用新的时限
+func (s *QuizService) rollbackFinish(ctx context.Context, sessionID primitive.ObjectID, status models.QuizSessionStatus, quizID *primitive.ObjectID, cause error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	errs := []error{cause}
+	if quizID != nil {
+		if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": *quizID}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	_, err := s.sessionCollection.UpdateOne(ctx,
+		bson.M{"_id": sessionID, "status": status},
+		bson.M{
+			"$set":   bson.M{"status": models.QuizSessionStatusInProgress},
+			"$unset": bson.M{"ended_at": "", "quiz_id": ""},
+		},
+	)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// OpenSessionQuestionIDs 返回用户未完成（created 或 in_progress 且未过期）的会话中的全部题目ID
+// 作答期间这些题目的答案不能通过其他接口泄露
+func (s *QuizService) OpenSessionQuestionIDs(userID primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.expireOverdueSessions(ctx, bson.M{"user_id": userID}); err != nil {
+		return nil, err
+	}
+	filter := bson.M{
+		"user_id": userID,
+		"status": bson.M{"$in": []models.QuizSessionStatus{
+			models.QuizSessionStatusCreated,
+			models.QuizSessionStatusInProgress,
+		}},
+	}
+	opts := options.Find().SetProjection(bson.M{"questions.question._id": 1})
+	cursor, err := s.sessionCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []models.QuizSession
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	ids := make(map[primitive.ObjectID]bool)
+	for _, session := range sessions {
+		for _, q := range session.Questions {
+			if q.Question != nil {
+				ids[q.Question.ID] = true
+			}
+		}
+	}
+	return ids, nil
+}
+
+// getActiveSession 获取属于该用户且处于 in_progress 的会话，超时的会话会按保存的作答判分并标记为 expired
+func (s *QuizService) getActiveSession(userID, sessionID primitive.ObjectID) (*models.QuizSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var session models.QuizSession
+	err := s.sessionCollection.FindOne(ctx, bson.M{"_id": sessionID, "user_id": userID}).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrQuizSessionNotFound
+		}
+		return nil, errors.New("Database query error")
+	}
+
+	if session.Status == models.QuizSessionStatusExpired {
+		return nil, ErrQuizSessionExpired
+	}
+	if session.Status != models.QuizSessionStatusInProgress {
+		return nil, ErrQuizSessionNotActive
+	}
+	if session.ExpiresAt != nil && time.Now().After(*session.ExpiresAt) {
+		if err := s.expireOverdueSessions(ctx, bson.M{"_id": session.ID}); err != nil {
+			return nil, err
+		}
+		return nil, ErrQuizSessionExpired
+	}
+	return &session, nil
+}
+
+// expireOverdueSessions 结束满足 filter 且已超过截止时间的进行中会话：按会话中保存的作答判分并写入Quiz记录，
+// 状态记为 expired，结束时间记为截止时间。已被其他请求结束的会话跳过
+func (s *QuizService) expireOverdueSessions(ctx context.Context, filter bson.M) error {
+	filter["status"] = models.QuizSessionStatusInProgress
+	filter["expires_at"] = bson.M{"$lt": time.Now()}
+	cursor, err := s.sessionCollection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var sessions []models.QuizSession
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return err
+	}
+
+	for i := range sessions {
+		session := &sessions[i]
+		answers, err := mergeSessionAnswers(session, nil)
+		if err != nil {
+			return err
+		}
+		questionMap, err := s.loadExpiredSessionQuestions(session)
+		if err != nil {
+			return err
+		}
+		_, err = s.finishSession(ctx, session, answers, questionMap, models.QuizSessionStatusExpired, *session.ExpiresAt)
+		if err != nil && !errors.Is(err, ErrQuizSessionNotActive) {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadExpiredSessionQuestions 查询过期会话中题目的标准答案：作答期间停用的题目照常判分，
+// 题库中查不到的题目按会话中保存的副本判分，使过期会话总能结束
+func (s *QuizService) loadExpiredSessionQuestions(session *models.QuizSession) (map[primitive.ObjectID]models.Question, error) {
+	questionIDs := make([]primitive.ObjectID, len(session.Questions))
+	for i, question := range session.Questions {
+		questionIDs[i] = question.Question.ID
+	}
+	questionMap, err := s.questionService.GetQuestionsByIDs(questionIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, question := range session.Questions {
+		if _, ok := questionMap[question.Question.ID]; !ok {
+			questionMap[question.Question.ID] = *question.Question
+		}
+	}
+	return questionMap, nil
+}
+
+// mergeSessionAnswers 以会话中保存的作答为基础，用请求中的作答覆盖，返回会话全部题目的作答
+func mergeSessionAnswers(session *models.QuizSession, overrides []models.QuizAnswer) ([]models.QuizAnswer, error) {
+	answers := make([]models.QuizAnswer, len(session.Questions))
+	positions := make(map[primitive.ObjectID]int, len(session.Questions))
+	for i, question := range session.Questions {
+		answers[i] = models.QuizAnswer{QuestionID: question.Question.ID, UserAnswerIndex: question.UserAnswerIndex}
+		positions[question.Question.ID] = i
+	}
+
+	seen := make(map[primitive.ObjectID]struct{}, len(overrides))
+	for _, answer := range overrides {
+		idx, ok := positions[answer.QuestionID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrQuestionNotInSession, answer.QuestionID.Hex())
+		}
+		if _, dup := seen[answer.QuestionID]; dup {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateAnswer, answer.QuestionID.Hex())
+		}
+		seen[answer.QuestionID] = struct{}{}
+		answers[idx].UserAnswerIndex = answer.UserAnswerIndex
+	}
+	return answers, nil
+}
+
+// redactSession 返回隐藏了正确答案和解析的会话副本，避免作答过程中泄露答案
+func redactSession(session *models.QuizSession) *models.QuizSession {
+	redacted := *session
+	redacted.Questions = make([]models.QuizQuestion, len(session.Questions))
+	for i, quizQuestion := range session.Questions {
+		if quizQuestion.Question != nil {
+			question := *quizQuestion.Question
+			question.CorrectAnswerIndex = nil
+			question.Explanation = ""
+			question.OptionExplanations = nil
+			question.Blueprint = nil
+			quizQuestion.Question = &question
+		}
+		redacted.Questions[i] = quizQuestion
+	}
+	return &redacted
+}
+
+// loadGradableQuestions 查询作答对应的题目，拒绝重复、不存在或已停用的题目
+func (s *QuizService) loadGradableQuestions(answers []models.QuizAnswer) (map[primitive.ObjectID]models.Question, error) {
+	questionIDs := make([]primitive.ObjectID, 0, len(answers))
+	seen := make(map[primitive.ObjectID]struct{}, len(answers))
+	for _, answer := range answers {
+		if _, ok := seen[answer.QuestionID]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateAnswer, answer.QuestionID.Hex())
+		}
+		seen[answer.QuestionID] = struct{}{}
+		questionIDs = append(questionIDs, answer.QuestionID)
+	}
+
+	questionMap, err := s.questionService.GetQuestionsByIDs(questionIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range questionIDs {
+		question, ok := questionMap[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrQuestionNotFound, id.Hex())
+		}
+		if !question.IsActive {
+			return nil, fmt.Errorf("%w: %s", ErrQuestionInactive, id.Hex())
+		}
+	}
+	return questionMap, nil
+}
+
 func (s *QuizService) GetUserQuizHistory(userID primitive.ObjectID) ([]models.Quiz, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
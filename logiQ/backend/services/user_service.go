@@ -176,7 +176,7 @@ func (s *UserService) createUserDirectly(pendingReg *models.PendingRegistration)
 		Email:             pendingReg.Email,
 		Password:          pendingReg.Password,                                            // 已加密的密码
 		ProfilePictureUrl: "https://logiq.blob.core.windows.net/bucket-logiq/default.JPG", // 默认头像
-		Role:              "user",
+		Role:              models.UserRoleUser,
 		CreatedAt:         now,
 		UpdatedAt:         now,
 	}
@@ -274,7 +274,7 @@ func (s *UserService) LoginAdmin(req *models.LoginRequest) (*models.User, error)
 	}
 
 	// 第3步：检查用户角色是否为admin
-	if user.Role != "admin" {
+	if user.Role != models.UserRoleAdmin {
 		return nil, errors.New("Access denied: not an admin user")
 	}
 
@@ -224,6 +224,7 @@ func (s *UserStatsService) updateErrorDistribution(userStats *models.UserStats,
 }
 
 // updateErrorByCategory 更新按分类的错误统计
+// 新增类别之前创建的统计中没有该类别，此时追加一项
 func (s *UserStatsService) updateErrorByCategory(userStats *models.UserStats, category models.QuestionCategory) {
 	for i := range userStats.ErrorDistribution.DataByCategory {
 		if userStats.ErrorDistribution.DataByCategory[i].Type == string(category) {
@@ -231,6 +232,10 @@ func (s *UserStatsService) updateErrorByCategory(userStats *models.UserStats, ca
 			return
 		}
 	}
+	userStats.ErrorDistribution.DataByCategory = append(userStats.ErrorDistribution.DataByCategory, models.ErrorDistributionItem{
+		Type:  string(category),
+		Count: 1,
+	})
 }
 
 // updateErrorByDifficulty 更新按难度的错误统计
@@ -47,16 +47,18 @@ func CheckPassword(password, hashedPassword string) bool {
 type Claims struct {
 	UserID primitive.ObjectID `json:"user_id"`
 	Email  string             `json:"email"`
+	Role   string             `json:"role"`
 	jwt.RegisteredClaims
 }
 
 // GenerateJWT 生成JWT token
-func GenerateJWT(userID primitive.ObjectID, email string) (string, error) {
+func GenerateJWT(userID primitive.ObjectID, email, role string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour) // 24小时有效期
 
 	claims := &Claims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -82,6 +84,10 @@ func ParseJWT(tokenString string) (*Claims, error) {
 	if !token.Valid {
 		return nil, errors.New("invalid token")
 	}
+	// 登录token不带audience，带audience的是订阅进度等用途受限的token，不能当作登录token
+	if len(claims.Audience) > 0 {
+		return nil, errors.New("invalid token")
+	}
 
 	return claims, nil
 }
@@ -129,3 +135,51 @@ func ParseTemporaryToken(tokenString string) (*TemporaryClaims, error) {
 
 	return claims, nil
 }
+
+// streamTokenAudience 订阅生成任务进度的token的audience
+const streamTokenAudience = "generation_job_events"
+
+// StreamClaims 订阅生成任务进度的token的Claims结构
+type StreamClaims struct {
+	UserID primitive.ObjectID `json:"user_id"`
+	JobID  primitive.ObjectID `json:"job_id"`
+	jwt.RegisteredClaims
+}
+
+// GenerateStreamToken 生成订阅生成任务进度的token（1分钟有效期），只对一个任务有效
+// EventSource 不能设置 Authorization 请求头，token 只能放在URL的查询参数中，因此有效期很短
+func GenerateStreamToken(userID, jobID primitive.ObjectID) (string, error) {
+	expirationTime := time.Now().Add(time.Minute) // 1分钟有效期
+
+	claims := &StreamClaims{
+		UserID: userID,
+		JobID:  jobID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{streamTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(getJWTSecret())
+}
+
+// ParseStreamToken 解析订阅生成任务进度的token
+func ParseStreamToken(tokenString string) (*StreamClaims, error) {
+	claims := &StreamClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return getJWTSecret(), nil
+	}, jwt.WithAudience(streamTokenAudience))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("invalid stream token")
+	}
+
+	return claims, nil
+}
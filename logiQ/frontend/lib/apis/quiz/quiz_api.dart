@@ -29,7 +29,9 @@ class QuizApi {
     if (category != null) body['category'] = category;
     if (difficulty != null) body['difficulty'] = difficulty;
 
-    final response = await _apiService.post('/quiz/new', body: body);
+    // 先创建测验会话，再开始作答（服务端开始计时）
+    final session = await _apiService.post('/quiz/new', body: body);
+    final response = await _apiService.post('/quiz/session/${session['_id']}/start');
     return Quiz.fromJson(response);
   }
 
@@ -68,20 +70,23 @@ class QuizApi {
 // 请求和响应模型
 class SubmitQuizRequest {
 
-  final String type;
+  final String sessionId;
   final List<QuizQuestion> questions;
-  final int completionTime;
 
   SubmitQuizRequest({
-    required this.type,
+    required this.sessionId,
     required this.questions,
-    required this.completionTime,
   });
 
   Map<String, dynamic> toJson() => {
-    'type': type,
-    'questions': questions.map((q) => q.toJson()).toList(),
-    'completion_time': completionTime,
+    'session_id': sessionId,
+    // 只提交题目ID和作答，判分由服务端完成
+    'answers': questions
+        .map((q) => {
+              'question_id': q.questionId,
+              'user_answer_index': q.userAnswerIndex,
+            })
+        .toList(),
   };
 }
 
@@ -10,6 +10,10 @@ class Question {
   final QuestionCategory category;
   final QuestionDifficulty difficulty;
 
+  // 解析，作答结束后才会由服务端返回
+  final String explanation;
+  final List<String> optionExplanations;
+
   Question({
     required this.id,
     required this.questionText,
@@ -18,6 +22,8 @@ class Question {
     required this.type,
     required this.category,
     required this.difficulty,
+    this.explanation = '',
+    this.optionExplanations = const [],
   });
 
   Question copyWith({
@@ -28,6 +34,8 @@ class Question {
     QuestionType? type,
     QuestionCategory? category,
     QuestionDifficulty? difficulty,
+    String? explanation,
+    List<String>? optionExplanations,
   }) {
     return Question(
       id: id ?? this.id,
@@ -37,6 +45,8 @@ class Question {
       type: type ?? this.type,
       category: category ?? this.category,
       difficulty: difficulty ?? this.difficulty,
+      explanation: explanation ?? this.explanation,
+      optionExplanations: optionExplanations ?? this.optionExplanations,
     );
   }
 
@@ -58,6 +68,8 @@ class Question {
       (e) => e.name == json['difficulty'],
       orElse: () => QuestionDifficulty.easy,
     ),
+    explanation: json['explanation'] ?? '',
+    optionExplanations: List<String>.from(json['option_explanations'] ?? []),
   );
 
   Map<String, dynamic> toJson() => {
@@ -68,6 +80,8 @@ class Question {
     'type': type.name,
     'category': category.name,
     'difficulty': difficulty.name,
+    'explanation': explanation,
+    'option_explanations': optionExplanations,
   };
 
 }
@@ -113,6 +127,11 @@ enum QuestionCategory {
   truthTable,
   equivalence,
   inference,
+  normalForm,
+  classification,
+  proof,
+  resolution,
+  tableau,
 }
 
 extension QuestionCategoryExtension on QuestionCategory {
@@ -124,6 +143,16 @@ extension QuestionCategoryExtension on QuestionCategory {
         return 'Equivalence';
       case QuestionCategory.inference:
         return 'Inference';
+      case QuestionCategory.normalForm:
+        return 'Normal Form';
+      case QuestionCategory.classification:
+        return 'Classification';
+      case QuestionCategory.proof:
+        return 'Proof';
+      case QuestionCategory.resolution:
+        return 'Resolution';
+      case QuestionCategory.tableau:
+        return 'Tableau';
     }
   }
 
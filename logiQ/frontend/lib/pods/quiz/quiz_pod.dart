@@ -99,7 +99,7 @@ class QuizNotifier extends _$QuizNotifier {
         if (quiz == null) {
           return SubmitQuizResult.error('Quiz data is not available');
         } else {
-          quizRequest = SubmitQuizRequest(type: quiz.type.name, questions: quiz.questions, completionTime: quiz.completionTime);
+          quizRequest = SubmitQuizRequest(sessionId: quiz.id, questions: quiz.questions);
         }
         final quizRepository = ref.read(quizRepositoryProvider);
         final quizRes = await quizRepository.submitQuiz(quizRequest);
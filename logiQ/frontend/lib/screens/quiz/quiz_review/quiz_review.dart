@@ -56,6 +56,7 @@ class _QuizReviewState extends ConsumerState<QuizReview> {
           final userAnswerIndex = currentQuizQuestion.userAnswerIndex;
           final correctAnswerIndex = currentQuizQuestion.question.correctAnswerIndex;
           final isCorrect = currentQuizQuestion.isCorrect;
+          final explanation = currentQuizQuestion.question.explanation;
 
           return BaseContainer(
             isScrollable: false,
@@ -68,7 +69,7 @@ class _QuizReviewState extends ConsumerState<QuizReview> {
                 Expanded(flex: 4, child: QuestionArea()),
                 Divider(height: 0),
                 const Gap(10),
-                AnswerReview(correctAnswerIndex: correctAnswerIndex, userAnswerIndex: userAnswerIndex, isCorrect: isCorrect, questionType: questionType),
+                AnswerReview(correctAnswerIndex: correctAnswerIndex, userAnswerIndex: userAnswerIndex, isCorrect: isCorrect, questionType: questionType, explanation: explanation),
                 const Gap(10),
                 Expanded(
                   flex: 5,
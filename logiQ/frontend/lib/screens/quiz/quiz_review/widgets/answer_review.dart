@@ -9,12 +9,14 @@ class AnswerReview extends StatelessWidget {
     required this.userAnswerIndex,
     required this.isCorrect,
     required this.questionType,
+    this.explanation = '',
   });
 
   final List<int> correctAnswerIndex;
   final List<int> userAnswerIndex;
   final bool isCorrect;
   final QuestionType questionType;
+  final String explanation;
 
   String _convertIndexesToLabels(List<int> indexes) {
     if (indexes.isEmpty) {
@@ -35,7 +37,7 @@ class AnswerReview extends StatelessWidget {
   Widget build(BuildContext context) {
     final theme = Theme.of(context);
 
-    return Row(
+    final answers = Row(
       mainAxisAlignment: MainAxisAlignment.spaceBetween,
       children: [
         Text(
@@ -52,5 +54,18 @@ class AnswerReview extends StatelessWidget {
         ),
       ],
     );
+
+    if (explanation.isEmpty) {
+      return answers;
+    }
+
+    return Column(
+      crossAxisAlignment: CrossAxisAlignment.start,
+      children: [
+        answers,
+        const SizedBox(height: 6),
+        Text(explanation, style: theme.textTheme.bodySmall),
+      ],
+    );
   }
 }
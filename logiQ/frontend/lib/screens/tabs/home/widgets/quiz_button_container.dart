@@ -75,6 +75,11 @@ class QuizButtonContainer extends ConsumerWidget {
       QuestionCategory.truthTable.displayName.toString(),
       QuestionCategory.equivalence.displayName.toString(),
       QuestionCategory.inference.displayName.toString(),
+      QuestionCategory.normalForm.displayName.toString(),
+      QuestionCategory.classification.displayName.toString(),
+      QuestionCategory.proof.displayName.toString(),
+      QuestionCategory.resolution.displayName.toString(),
+      QuestionCategory.tableau.displayName.toString(),
     ];
     final difficultyList = [
       QuestionDifficulty.easy.displayName.toString(),
@@ -1,8 +1,13 @@
 import 'package:flutter/material.dart';
+import 'package:google_fonts/google_fonts.dart';
 import 'colors.dart';
 
+// 题目和选项中的联结词 ¬ ∧ ∨ → ↔ ⊕ ↑ ↓ 和常量 ⊤ ⊥ 不全在默认字体中，统一回退到 Noto Sans Math
+final List<String> formulaFontFallback = [GoogleFonts.notoSansMath().fontFamily!];
+
 final ThemeData lightTheme = ThemeData(
     useMaterial3: true,
+    fontFamilyFallback: formulaFontFallback,
     colorScheme: ColorScheme.fromSeed(
       seedColor: AppColors.lightPrimaryColor,
       brightness: Brightness.light,
@@ -13,6 +18,7 @@ final ThemeData lightTheme = ThemeData(
 
 final ThemeData darkTheme = ThemeData(
   useMaterial3: true,
+  fontFamilyFallback: formulaFontFallback,
   colorScheme: ColorScheme.fromSeed(
     seedColor: AppColors.darkPrimaryColor,
     brightness: Brightness.dark,